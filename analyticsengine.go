@@ -0,0 +1,379 @@
+package Cx1ClientGo
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/exp/slices"
+)
+
+// Name of the group containing all findings in LocalAnalytics.Calculate
+const LocalAnalyticsAllGroup = "*"
+
+var localAnalyticsDefaultStates = []string{"TO_VERIFY", "NOT_EXPLOITABLE", "PROPOSED_NOT_EXPLOITABLE", "CONFIRMED", "URGENT"}
+
+func NewLocalAnalytics() *LocalAnalytics {
+	return &LocalAnalytics{
+		history: make(map[string][]ResultsChangelog),
+		groups:  make(map[string][]string),
+	}
+}
+
+// Convenience function: retrieves the completed scans on the branch since a date, their results,
+// and the change history for each project. Project groups are set from the project's applications, groups and tags, see LocalAnalyticsGroups.
+// If branch is empty, the project's main branch is used.
+func (c *Cx1Client) GetLocalAnalytics(projects []Project, branch string, since time.Time) (*LocalAnalytics, error) {
+	analytics := NewLocalAnalytics()
+
+	for _, p := range projects {
+		projectBranch := branch
+		if projectBranch == "" {
+			projectBranch = p.MainBranch
+		}
+		c.config.Logger.Debugf("Collecting local analytics data for project %v branch %v", p.String(), projectBranch)
+
+		filter := ScanFilter{
+			BaseFilter: BaseFilter{Limit: c.config.Pagination.Scans},
+			ProjectID:  p.ProjectID,
+			Statuses:   []string{ScanStatus.Completed},
+			FromDate:   since,
+		}
+		if projectBranch != "" {
+			filter.Branches = []string{projectBranch}
+		}
+		_, scans, err := c.GetAllScansFiltered(filter)
+		if err != nil {
+			return analytics, err
+		}
+
+		for _, scan := range scans {
+			results, err := c.GetAllScanResultsByID(scan.ScanID)
+			if err != nil {
+				return analytics, err
+			}
+			analytics.AddScan(scan, results)
+		}
+
+		_, history, err := c.GetAllResultsChangeHistoryFiltered(ResultsChangeFilter{
+			BaseFilter: BaseFilter{Limit: c.config.Pagination.Results},
+			History:    true,
+			EntityID:   p.ProjectID,
+			EntityType: "projectID",
+		})
+		if err != nil {
+			return analytics, fmt.Errorf("failed to get results change history for project %v: %s", p.String(), err)
+		}
+		if len(history) > 0 && !slices.ContainsFunc(history, func(h ResultsChangeHistory) bool {
+			return slices.ContainsFunc(h.Predicates, func(change ResultsChangelog) bool { return change.State != "" })
+		}) {
+			c.config.Logger.Warnf("The change history of project %v has no structured states, triage dates are taken from the change text which may not recognize custom states, see LocalAnalyticsOptions.CustomStates", p.String())
+		}
+		analytics.AddChangeHistory(p.ProjectID, history)
+		analytics.SetProjectGroups(p.ProjectID, LocalAnalyticsGroups(p)...)
+	}
+
+	return analytics, nil
+}
+
+// Returns the default groups for a project: "application:<id>", "group:<id>", and "tag:<key>=<value>" (or "tag:<key>" for tags without a value)
+func LocalAnalyticsGroups(project Project) []string {
+	groups := []string{}
+	if project.Applications != nil {
+		for _, a := range *project.Applications {
+			groups = append(groups, "application:"+a)
+		}
+	}
+	for _, g := range project.Groups {
+		groups = append(groups, "group:"+g)
+	}
+	for k, v := range project.Tags {
+		if v == "" {
+			groups = append(groups, "tag:"+k)
+		} else {
+			groups = append(groups, fmt.Sprintf("tag:%v=%v", k, v))
+		}
+	}
+	sort.Strings(groups)
+	return groups
+}
+
+// Adds a scan and its results. Scans can be added in any order, but should be from a single branch per project
+// since a finding missing from a later scan of the same branch is counted as fixed
+func (a *LocalAnalytics) AddScan(scan Scan, results ScanResultSet) {
+	a.scans = append(a.scans, localAnalyticsScan{scan: scan, results: results})
+}
+
+// Adds the change history for a project's results, used for the time to triage
+func (a *LocalAnalytics) AddChangeHistory(projectID string, history []ResultsChangeHistory) {
+	for _, h := range history {
+		key := projectID + "|" + h.SimilarityID
+		a.history[key] = append(a.history[key], h.Predicates...)
+	}
+}
+
+// Sets the arbitrary groups (eg: business unit, team) a project belongs to, replacing any previous groups
+func (a *LocalAnalytics) SetProjectGroups(projectID string, groups ...string) {
+	a.groups[projectID] = groups
+}
+
+// Returns the lifecycle of each finding, calculated from the scans and change history
+func (a *LocalAnalytics) GetFindings(options LocalAnalyticsOptions) []LocalAnalyticsFinding {
+	a.calculateFindings(options)
+	findings := make([]LocalAnalyticsFinding, 0, len(a.findings))
+	for _, f := range a.findings {
+		findings = append(findings, *f)
+	}
+	sort.Slice(findings, func(i, j int) bool {
+		if findings[i].ProjectID != findings[j].ProjectID {
+			return findings[i].ProjectID < findings[j].ProjectID
+		}
+		return findings[i].SimilarityID < findings[j].SimilarityID
+	})
+	return findings
+}
+
+// Calculates the statistics for each project group, and for all findings in the LocalAnalyticsAllGroup group
+func (a *LocalAnalytics) Calculate(options LocalAnalyticsOptions) map[string]LocalAnalyticsStats {
+	if options.Now.IsZero() {
+		options.Now = time.Now()
+	}
+	if len(options.AgingBuckets) == 0 {
+		options.AgingBuckets = []int{30, 60, 90, 180}
+	}
+	sort.Ints(options.AgingBuckets)
+
+	a.calculateFindings(options)
+
+	groups := map[string]*localAnalyticsAccumulator{}
+
+	for _, f := range a.findings {
+		if len(options.States) > 0 && !slices.ContainsFunc(options.States, func(s string) bool { return strings.EqualFold(s, f.State) }) {
+			continue
+		}
+		if len(options.Severities) > 0 && !slices.ContainsFunc(options.Severities, func(s string) bool { return strings.EqualFold(s, f.Severity) }) {
+			continue
+		}
+
+		for _, group := range append([]string{LocalAnalyticsAllGroup}, a.groups[f.ProjectID]...) {
+			acc, ok := groups[group]
+			if !ok {
+				acc = &localAnalyticsAccumulator{stats: LocalAnalyticsStats{Group: group, OpenBySeverity: map[string]uint64{}}}
+				for _, max := range options.AgingBuckets {
+					acc.stats.Aging = append(acc.stats.Aging, LocalAnalyticsAgingBucket{MaxDays: max})
+				}
+				acc.stats.Aging = append(acc.stats.Aging, LocalAnalyticsAgingBucket{MaxDays: 0})
+				groups[group] = acc
+			}
+			acc.add(f, options)
+		}
+	}
+
+	stats := make(map[string]LocalAnalyticsStats, len(groups))
+	for group, acc := range groups {
+		if acc.stats.Fixed > 0 {
+			acc.stats.MeanTimeToResolution = acc.resolution / time.Duration(acc.stats.Fixed)
+		}
+		if acc.triaged > 0 {
+			acc.stats.MeanTimeToTriage = acc.triage / time.Duration(acc.triaged)
+		}
+		if acc.stats.Findings > 0 {
+			acc.stats.FixRate = float64(acc.stats.Fixed) / float64(acc.stats.Findings)
+		}
+		if acc.everFixed > 0 {
+			acc.stats.ReopenRate = float64(acc.stats.Reopened) / float64(acc.everFixed)
+		}
+		stats[group] = acc.stats
+	}
+	return stats
+}
+
+type localAnalyticsAccumulator struct {
+	stats              LocalAnalyticsStats
+	resolution, triage time.Duration
+	triaged, everFixed uint64
+}
+
+func (acc *localAnalyticsAccumulator) add(f *LocalAnalyticsFinding, options LocalAnalyticsOptions) {
+	acc.stats.Findings++
+	if f.Reopened > 0 {
+		acc.stats.Reopened++
+	}
+	if f.FixedAt != nil || f.Reopened > 0 {
+		acc.everFixed++
+	}
+	if f.TriagedAt != nil && f.TriagedAt.After(f.FirstFoundAt) {
+		acc.triaged++
+		acc.triage += f.TriagedAt.Sub(f.FirstFoundAt)
+	}
+
+	sla, hasSLA := options.SLA[strings.ToUpper(f.Severity)]
+	if !hasSLA {
+		for severity, duration := range options.SLA {
+			if strings.EqualFold(severity, f.Severity) {
+				sla, hasSLA = duration, true
+			}
+		}
+	}
+
+	if f.FixedAt != nil {
+		acc.stats.Fixed++
+		duration := f.FixedAt.Sub(f.FirstFoundAt)
+		acc.resolution += duration
+		if hasSLA && duration > sla {
+			acc.stats.SLABreaches++
+		}
+		return
+	}
+
+	acc.stats.Open++
+	acc.stats.OpenBySeverity[f.Severity]++
+	age := options.Now.Sub(f.FirstFoundAt)
+	if hasSLA && age > sla {
+		acc.stats.SLABreaches++
+	}
+
+	days := int(age.Hours() / 24)
+	for i := range acc.stats.Aging {
+		if acc.stats.Aging[i].MaxDays == 0 || days <= acc.stats.Aging[i].MaxDays {
+			acc.stats.Aging[i].Count++
+			break
+		}
+	}
+}
+
+// replays the scans of each project branch in order to find when each finding was first detected, fixed and reopened
+func (a *LocalAnalytics) calculateFindings(options LocalAnalyticsOptions) {
+	a.findings = make(map[string]*LocalAnalyticsFinding)
+
+	sort.SliceStable(a.scans, func(i, j int) bool {
+		return a.scans[i].scan.CreatedAt.Before(a.scans[j].scan.CreatedAt)
+	})
+
+	states := append(slices.Clone(localAnalyticsDefaultStates), options.CustomStates...)
+
+	for _, s := range a.scans {
+		branchKey := s.scan.ProjectID + "|" + s.scan.Branch
+		seen := map[string]bool{}
+		engines := map[string]bool{}
+
+		observe := func(engine string, r ScanResultBase) {
+			engines[engine] = true
+			if strings.EqualFold(r.Status, "FIXED") {
+				return
+			}
+			key := strings.Join([]string{branchKey, engine, r.SimilarityID}, "|")
+			seen[key] = true
+
+			f, ok := a.findings[key]
+			if !ok {
+				f = &LocalAnalyticsFinding{
+					ProjectID:    s.scan.ProjectID,
+					Branch:       s.scan.Branch,
+					SimilarityID: r.SimilarityID,
+					Engine:       engine,
+					FirstFoundAt: r.FirstFoundAt,
+				}
+				if f.FirstFoundAt.IsZero() || f.FirstFoundAt.After(s.scan.CreatedAt) {
+					f.FirstFoundAt = s.scan.CreatedAt
+				}
+				f.TriagedAt = a.triagedAt(s.scan.ProjectID, r.SimilarityID, states)
+				a.findings[key] = f
+			} else if f.FixedAt != nil {
+				f.Reopened++
+				f.FixedAt = nil
+			}
+			f.Severity = r.Severity
+			f.State = r.State
+		}
+
+		for _, r := range s.results.SAST {
+			observe("sast", r.ScanResultBase)
+		}
+		for _, r := range s.results.IAC {
+			observe("iac", r.ScanResultBase)
+		}
+		for _, r := range s.results.SCA {
+			observe("sca", r.ScanResultBase)
+		}
+		for _, r := range s.results.SCAContainer {
+			observe("sca-container", r.ScanResultBase)
+		}
+		for _, r := range s.results.Containers {
+			observe("containers", r.ScanResultBase)
+		}
+		for _, e := range s.scan.Engines {
+			engines[strings.ToLower(e)] = true
+		}
+
+		// findings from engines which ran in this scan but were not detected are fixed
+		for key, f := range a.findings {
+			if f.FixedAt != nil || seen[key] || f.ProjectID != s.scan.ProjectID || f.Branch != s.scan.Branch {
+				continue
+			}
+			if engines[f.Engine] || (f.Engine == "iac" && engines["kics"]) || (f.Engine == "sca-container" && engines["sca"]) {
+				fixed := s.scan.CreatedAt
+				f.FixedAt = &fixed
+			}
+		}
+	}
+}
+
+// returns the date of the first change history entry moving the finding out of To Verify.
+// The state is taken from the entry's State, or from its Change text if State is empty, recognizing the states listed
+func (a *LocalAnalytics) triagedAt(projectID, similarityID string, states []string) *time.Time {
+	var triaged *time.Time
+	for _, change := range a.history[projectID+"|"+similarityID] {
+		state := change.State
+		if state == "" {
+			state = changelogState(change.Change, states)
+		}
+		if state == "" || strings.EqualFold(state, "TO_VERIFY") {
+			continue
+		}
+		if triaged == nil || change.Date.Before(*triaged) {
+			date := change.Date
+			triaged = &date
+		}
+	}
+	return triaged
+}
+
+// finds the state a change history entry moved the finding to, which is the state named last in the text
+// eg: "Changed state from To Verify to Proposed Not Exploitable" returns PROPOSED_NOT_EXPLOITABLE
+func changelogState(change string, states []string) string {
+	text := strings.ReplaceAll(strings.ToUpper(change), " ", "_")
+	var match string
+	end := -1
+	for _, state := range states {
+		state = strings.ReplaceAll(strings.ToUpper(state), " ", "_")
+		if idx := strings.LastIndex(text, state); idx >= 0 {
+			if idx+len(state) > end || (idx+len(state) == end && len(state) > len(match)) {
+				match = state
+				end = idx + len(state)
+			}
+		}
+	}
+	return match
+}
+
+// Returns the statistics as one line per group, sorted by group
+func LocalAnalyticsStatsString(stats map[string]LocalAnalyticsStats) string {
+	groups := make([]string, 0, len(stats))
+	for group := range stats {
+		groups = append(groups, group)
+	}
+	sort.Strings(groups)
+
+	var b strings.Builder
+	for _, group := range groups {
+		fmt.Fprintf(&b, "%v\n", stats[group].String())
+	}
+	return b.String()
+}
+
+func (s LocalAnalyticsStats) String() string {
+	return fmt.Sprintf("%v: %d findings, %d open, %d fixed (%.0f%%), %d reopened (%.0f%%), MTTR %v, MTTT %v, %d SLA breaches", s.Group, s.Findings, s.Open, s.Fixed, s.FixRate*100, s.Reopened, s.ReopenRate*100,
+		s.MeanTimeToResolution.Round(time.Hour), s.MeanTimeToTriage.Round(time.Hour), s.SLABreaches)
+}
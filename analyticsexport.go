@@ -0,0 +1,325 @@
+package Cx1ClientGo
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var openMetricsInvalidChars = regexp.MustCompile(`[^a-zA-Z0-9_:]`)
+
+func NewAnalyticsMetricSet() *AnalyticsMetricSet {
+	return &AnalyticsMetricSet{index: make(map[string]int)}
+}
+
+// Retrieves the analytics KPIs for each target and returns them as a metric set
+// Each target's labels are added to its metrics, eg: {"project": "my-project"} for a target filtering on that project
+func (c *Cx1Client) GetAnalyticsMetrics(targets []AnalyticsMetricsTarget) (*AnalyticsMetricSet, error) {
+	metrics := NewAnalyticsMetricSet()
+
+	for _, t := range targets {
+		c.config.Logger.Debugf("Collecting analytics metrics for %v", t.Labels)
+
+		severity, err := c.GetAnalyticsVulnerabilitiesBySeverityTotal(t.Filter)
+		if err != nil {
+			return metrics, err
+		}
+		metrics.AddDistribution("cx1_vulnerabilities", "Current vulnerabilities by scanner and severity", severity, "scanner", "severity", t.Labels)
+
+		state, err := c.GetAnalyticsVulnerabilitiesByStateTotal(t.Filter)
+		if err != nil {
+			return metrics, err
+		}
+		metrics.AddDistribution("cx1_vulnerabilities_by_state", "Current vulnerabilities by scanner and state", state, "scanner", "state", t.Labels)
+
+		status, err := c.GetAnalyticsVulnerabilitiesByStatusTotal(t.Filter)
+		if err != nil {
+			return metrics, err
+		}
+		metrics.AddDistribution("cx1_vulnerabilities_by_status", "Current vulnerabilities by scanner and status", status, "scanner", "status", t.Labels)
+
+		severityState, err := c.GetAnalyticsVulnerabilitiesBySeverityAndStateTotal(t.Filter)
+		if err != nil {
+			return metrics, err
+		}
+		metrics.AddSeverityAndState("cx1_vulnerabilities_by_severity_and_state", "Current vulnerabilities by state and severity", severityState, "state", t.Labels)
+
+		aging, err := c.GetAnalyticsVulnerabilitiesByAgingTotal(t.Filter)
+		if err != nil {
+			return metrics, err
+		}
+		agingStats := make([]AnalyticsSeverityAndStateStats, len(aging))
+		for i := range aging {
+			agingStats[i] = AnalyticsSeverityAndStateStats(aging[i])
+		}
+		metrics.AddSeverityAndState("cx1_vulnerabilities_by_age", "Current vulnerabilities by age and severity", agingStats, "age", t.Labels)
+
+		overtime, err := c.GetAnalyticsVulnerabilitiesBySeverityOvertime(t.Filter)
+		if err != nil {
+			return metrics, err
+		}
+		metrics.AddOverTime("cx1_vulnerabilities_overtime", "Vulnerabilities by severity, latest value of the analytics time series", overtime, "severity", false, t.Labels)
+
+		fixed, err := c.GetAnalyticsFixedVulnerabilitiesBySeverityOvertime(t.Filter)
+		if err != nil {
+			return metrics, err
+		}
+		metrics.AddOverTime("cx1_fixed_vulnerabilities_overtime", "Fixed vulnerabilities by severity, latest value of the analytics time series", fixed, "severity", false, t.Labels)
+
+		mttr, err := c.GetAnalyticsMeanTimeToResolution(t.Filter)
+		if err != nil {
+			return metrics, err
+		}
+		metrics.AddMeanTime("cx1_mean_time_to_resolution", "Mean time to resolution as reported by the analytics API", mttr, t.Labels)
+	}
+
+	return metrics, nil
+}
+
+// Adds a sample with the labels, and an optional timestamp. The metric family is created with the help text on first use
+func (m *AnalyticsMetricSet) Add(name, help string, value float64, labels map[string]string, timestamp *time.Time) {
+	if m.index == nil {
+		m.index = make(map[string]int)
+	}
+	name = openMetricsName(name)
+	id, ok := m.index[name]
+	if !ok {
+		m.families = append(m.families, &analyticsMetricFamily{name: name, help: help})
+		id = len(m.families) - 1
+		m.index[name] = id
+	}
+
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	sample := analyticsMetricSample{value: value, timestamp: timestamp}
+	for _, k := range keys {
+		sample.labels = append(sample.labels, [2]string{openMetricsName(k), labels[k]})
+	}
+	m.families[id].samples = append(m.families[id].samples, sample)
+}
+
+// Adds one sample per distribution entry, with the block label (eg: scanner) and entry label (eg: severity) added to the labels
+// A <name>_overall sample is added with the overall total (the _total and _count suffixes are reserved for counters and summaries), and <name>_density with the density (per LOC) of each entry
+func (m *AnalyticsMetricSet) AddDistribution(name, help string, stats AnalyticsDistributionStats, blockLabel, entryLabel string, labels map[string]string) {
+	for _, block := range stats.Distribution {
+		for _, entry := range block.Values {
+			l := withLabels(labels, blockLabel, block.Label, entryLabel, entry.Label)
+			m.Add(name, help, float64(entry.Results), l, nil)
+			m.Add(name+"_density", help+" per line of code", float32Value(entry.Density), l, nil)
+		}
+	}
+	m.Add(name+"_overall", help+", total", float64(stats.Total), labels, nil)
+}
+
+// Adds one sample per severity within each labelled group, eg: state or age
+func (m *AnalyticsMetricSet) AddSeverityAndState(name, help string, stats []AnalyticsSeverityAndStateStats, groupLabel string, labels map[string]string) {
+	for _, group := range stats {
+		for _, severity := range group.Severities {
+			m.Add(name, help, float64(severity.Results), withLabels(labels, groupLabel, group.Label, "severity", severity.Label), nil)
+		}
+	}
+}
+
+// Adds the time series with the series label (eg: severity). By default only the latest value of each series is added
+// with its timestamp, since a scraper will build the history itself. If history is true, every value is added with a "date" label, eg: to backfill.
+func (m *AnalyticsMetricSet) AddOverTime(name, help string, stats []AnalyticsOverTimeStats, seriesLabel string, history bool, labels map[string]string) {
+	for _, series := range stats {
+		if len(series.Values) == 0 {
+			continue
+		}
+		l := withLabels(labels, seriesLabel, series.Label)
+
+		if history {
+			for _, v := range series.Values {
+				m.Add(name, help, float32Value(v.Value), withLabels(l, "date", v.Date.Format("2006-01-02")), nil)
+			}
+			continue
+		}
+
+		latest := series.Values[0]
+		for _, v := range series.Values {
+			if v.Date.After(latest.Date.Time) {
+				latest = v
+			}
+		}
+		timestamp := latest.Date.Time
+		if timestamp.IsZero() {
+			m.Add(name, help, float32Value(latest.Value), l, nil)
+		} else {
+			m.Add(name, help, float32Value(latest.Value), l, &timestamp)
+		}
+	}
+}
+
+// Adds the mean time by severity and by state, and <name>_results with the number of results included in each mean
+func (m *AnalyticsMetricSet) AddMeanTime(name, help string, stats AnalyticsMeanTimeStats, labels map[string]string) {
+	for _, entry := range stats.MeanTimeData {
+		l := withLabels(labels, "severity", entry.Label)
+		m.Add(name, help+" by severity", float64(entry.MeanTime), l, nil)
+		m.Add(name+"_results", "Number of results included in the mean time to resolution", float64(entry.Results), l, nil)
+	}
+	for _, entry := range stats.MeanTimeStateData {
+		l := withLabels(labels, "state", entry.Label)
+		m.Add(name+"_by_state", help+" by state", float64(entry.MeanTime), l, nil)
+	}
+}
+
+// Adds per-project and per-application counts from the project overviews:
+// cx1_project_info (1 per project and application), cx1_project_last_scan_timestamp_seconds and cx1_application_projects
+func (m *AnalyticsMetricSet) AddProjectOverviews(overviews []ProjectOverview, labels map[string]string) {
+	applications := map[string]uint64{}
+	for _, p := range overviews {
+		l := withLabels(labels, "project", p.Name, "project_id", p.ProjectID, "risk_level", p.RiskLevel)
+		if len(p.ApplicationIDs) == 0 {
+			m.Add("cx1_project_info", "Project information", 1, l, nil)
+		}
+		for _, a := range p.ApplicationIDs {
+			m.Add("cx1_project_info", "Project information", 1, withLabels(l, "application", a.Name), nil)
+			applications[a.Name]++
+		}
+
+		if lastScan, err := time.Parse(time.RFC3339, p.LastScanDate); err == nil {
+			m.Add("cx1_project_last_scan_timestamp_seconds", "Time of the last scan of the project", float64(lastScan.Unix()), withLabels(labels, "project", p.Name), nil)
+		}
+	}
+
+	names := make([]string, 0, len(applications))
+	for name := range applications {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		m.Add("cx1_application_projects", "Number of projects in the application", float64(applications[name]), withLabels(labels, "application", name), nil)
+	}
+}
+
+// Writes the metrics in the OpenMetrics text format
+func (m AnalyticsMetricSet) WriteTo(w io.Writer) (int64, error) {
+	var b bytes.Buffer
+	for _, f := range m.families {
+		fmt.Fprintf(&b, "# TYPE %v gauge\n", f.name)
+		if f.help != "" {
+			fmt.Fprintf(&b, "# HELP %v %v\n", f.name, openMetricsEscape(f.help, false))
+		}
+		for _, s := range f.samples {
+			b.WriteString(f.name)
+			if len(s.labels) > 0 {
+				b.WriteString("{")
+				for i, l := range s.labels {
+					if i > 0 {
+						b.WriteString(",")
+					}
+					fmt.Fprintf(&b, "%v=\"%v\"", l[0], openMetricsEscape(l[1], true))
+				}
+				b.WriteString("}")
+			}
+			b.WriteString(" " + strconv.FormatFloat(s.value, 'g', -1, 64))
+			if s.timestamp != nil {
+				b.WriteString(" " + strconv.FormatFloat(float64(s.timestamp.UnixMilli())/1000, 'f', -1, 64))
+			}
+			b.WriteString("\n")
+		}
+	}
+	b.WriteString("# EOF\n")
+	return b.WriteTo(w)
+}
+
+func (m AnalyticsMetricSet) String() string {
+	var b strings.Builder
+	_, _ = m.WriteTo(&b)
+	return b.String()
+}
+
+// Creates an http.Handler serving the analytics metrics for the targets, which are retrieved on the first request
+// and then at most once per interval. If a refresh fails, the previous metrics are served until the next interval.
+func NewAnalyticsMetricsHandler(client *Cx1Client, targets []AnalyticsMetricsTarget, interval time.Duration) *AnalyticsMetricsHandler {
+	return &AnalyticsMetricsHandler{
+		client:   client,
+		targets:  targets,
+		interval: interval,
+	}
+}
+
+func (h *AnalyticsMetricsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.lock.Lock()
+	if h.cache == nil || time.Since(h.updated) > h.interval {
+		metrics, err := h.refresh()
+		if err != nil {
+			h.client.config.Logger.Errorf("Failed to refresh analytics metrics: %s", err)
+			if h.cache == nil {
+				h.lock.Unlock()
+				http.Error(w, "failed to retrieve analytics metrics", http.StatusInternalServerError)
+				return
+			}
+		} else {
+			h.cache = []byte(metrics.String())
+		}
+		h.updated = time.Now()
+	}
+	data := h.cache
+	h.lock.Unlock()
+
+	w.Header().Set("Content-Type", "application/openmetrics-text; version=1.0.0; charset=utf-8")
+	_, _ = w.Write(data)
+}
+
+func (h *AnalyticsMetricsHandler) refresh() (*AnalyticsMetricSet, error) {
+	metrics, err := h.client.GetAnalyticsMetrics(h.targets)
+	if err != nil || !h.IncludeProjectOverviews {
+		return metrics, err
+	}
+
+	overviews, err := h.client.GetAllProjectOverviews()
+	if err != nil {
+		return metrics, err
+	}
+	metrics.AddProjectOverviews(overviews, nil)
+	return metrics, nil
+}
+
+// copies the labels and adds name/value pairs, skipping empty names
+func withLabels(labels map[string]string, pairs ...string) map[string]string {
+	l := make(map[string]string, len(labels)+len(pairs)/2)
+	for k, v := range labels {
+		l[k] = v
+	}
+	for i := 0; i+1 < len(pairs); i += 2 {
+		if pairs[i] != "" {
+			l[pairs[i]] = pairs[i+1]
+		}
+	}
+	return l
+}
+
+// converts without the float32 precision noise, eg: 0.1 rather than 0.10000000149011612
+func float32Value(f float32) float64 {
+	v, _ := strconv.ParseFloat(strconv.FormatFloat(float64(f), 'g', -1, 32), 64)
+	return v
+}
+
+func openMetricsName(name string) string {
+	name = openMetricsInvalidChars.ReplaceAllString(name, "_")
+	if name != "" && name[0] >= '0' && name[0] <= '9' {
+		name = "_" + name
+	}
+	return name
+}
+
+func openMetricsEscape(text string, quote bool) string {
+	text = strings.ReplaceAll(text, "\\", "\\\\")
+	text = strings.ReplaceAll(text, "\n", "\\n")
+	if quote {
+		text = strings.ReplaceAll(text, "\"", "\\\"")
+	}
+	return text
+}
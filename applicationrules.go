@@ -0,0 +1,267 @@
+package Cx1ClientGo
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"golang.org/x/exp/slices"
+)
+
+// The application rule types supported by Cx1. Rule values may contain multiple items separated by ';', and a rule matches if any item matches,
+// except for regex rules where the value is a single regular expression which may itself contain ';'. Tag key-value items are in the form "key:value".
+var ApplicationRuleTypes = struct {
+	NameIn            string
+	NameStartsWith    string
+	NameContains      string
+	NameRegex         string
+	TagKeyExists      string
+	TagValueExists    string
+	TagKeyValueExists string
+}{
+	NameIn:            "project.name.in",
+	NameStartsWith:    "project.name.starts-with",
+	NameContains:      "project.name.contains",
+	NameRegex:         "project.name.regex",
+	TagKeyExists:      "project.tag.key.exists",
+	TagValueExists:    "project.tag.value.exists",
+	TagKeyValueExists: "project.tag.key-value.exists",
+}
+
+// a rule prepared for matching many projects, with its value split into items and any regex compiled once
+type applicationRuleMatcher struct {
+	rule  ApplicationRule
+	items []string
+	regex *regexp.Regexp
+}
+
+func newApplicationRuleMatcher(ar ApplicationRule) (applicationRuleMatcher, error) {
+	m := applicationRuleMatcher{rule: ar}
+	if ar.Type == ApplicationRuleTypes.NameRegex {
+		re, err := regexp.Compile(ar.Value)
+		if err != nil {
+			return m, fmt.Errorf("invalid regex in rule %v: %s", ar.String(), err)
+		}
+		m.regex = re
+		return m, nil
+	}
+
+	for _, item := range strings.Split(ar.Value, ";") {
+		if item != "" {
+			m.items = append(m.items, item)
+		}
+	}
+	return m, nil
+}
+
+func newApplicationRuleMatchers(rules []ApplicationRule) ([]applicationRuleMatcher, error) {
+	matchers := make([]applicationRuleMatcher, 0, len(rules))
+	for _, rule := range rules {
+		m, err := newApplicationRuleMatcher(rule)
+		if err != nil {
+			return matchers, err
+		}
+		matchers = append(matchers, m)
+	}
+	return matchers, nil
+}
+
+// Returns true if the project matches this rule, evaluated locally in the same way as Cx1 assigns projects to applications
+func (ar ApplicationRule) Matches(project Project) (bool, error) {
+	m, err := newApplicationRuleMatcher(ar)
+	if err != nil {
+		return false, err
+	}
+	return m.matches(project)
+}
+
+func (m applicationRuleMatcher) matches(project Project) (bool, error) {
+	if m.regex != nil {
+		return m.regex.MatchString(project.Name), nil
+	}
+	for _, item := range m.items {
+		matched, err := m.matchesItem(project, item)
+		if err != nil || matched {
+			return matched, err
+		}
+	}
+	return false, nil
+}
+
+func (m applicationRuleMatcher) matchesItem(project Project, item string) (bool, error) {
+	switch m.rule.Type {
+	case ApplicationRuleTypes.NameIn:
+		return project.Name == item, nil
+	case ApplicationRuleTypes.NameStartsWith:
+		return strings.HasPrefix(project.Name, item), nil
+	case ApplicationRuleTypes.NameContains:
+		return strings.Contains(project.Name, item), nil
+	case ApplicationRuleTypes.TagKeyExists:
+		_, ok := project.Tags[item]
+		return ok, nil
+	case ApplicationRuleTypes.TagValueExists:
+		for _, v := range project.Tags {
+			if v == item {
+				return true, nil
+			}
+		}
+		return false, nil
+	case ApplicationRuleTypes.TagKeyValueExists:
+		key, value, _ := strings.Cut(item, ":")
+		v, ok := project.Tags[key]
+		return ok && v == value, nil
+	}
+	return false, fmt.Errorf("unknown application rule type %v", m.rule.Type)
+}
+
+// Returns true if the project matches any of the application's rules
+func (a Application) MatchesProject(project Project) (bool, error) {
+	matchers, err := newApplicationRuleMatchers(a.Rules)
+	if err != nil {
+		return false, err
+	}
+	return matchesRules(matchers, project)
+}
+
+// Returns the projects which match any of the application's rules
+func (a Application) GetMatchingProjects(projects []Project) ([]Project, error) {
+	return filterProjectsByRules(a.Rules, projects)
+}
+
+func matchesRules(matchers []applicationRuleMatcher, project Project) (bool, error) {
+	for _, m := range matchers {
+		matched, err := m.matches(project)
+		if err != nil || matched {
+			return matched, err
+		}
+	}
+	return false, nil
+}
+
+func filterProjectsByRules(rules []ApplicationRule, projects []Project) ([]Project, error) {
+	matching := []Project{}
+	matchers, err := newApplicationRuleMatchers(rules)
+	if err != nil {
+		return matching, err
+	}
+	for _, p := range projects {
+		matched, err := matchesRules(matchers, p)
+		if err != nil {
+			return matching, err
+		}
+		if matched {
+			matching = append(matching, p)
+		}
+	}
+	return matching, nil
+}
+
+// Shows which of the projects the application would gain or lose if its rules were replaced with the proposed rules.
+// The current membership is the application's ProjectIds, or its current rules evaluated locally if ProjectIds is not set.
+func PreviewApplicationRules(application Application, rules []ApplicationRule, projects []Project) (ApplicationRulePreview, error) {
+	preview := ApplicationRulePreview{
+		ApplicationID:   application.ApplicationID,
+		ApplicationName: application.Name,
+		Gained:          []Project{},
+		Lost:            []Project{},
+		Unchanged:       []Project{},
+	}
+
+	current := []string{}
+	if application.ProjectIds != nil {
+		current = *application.ProjectIds
+	} else {
+		matching, err := application.GetMatchingProjects(projects)
+		if err != nil {
+			return preview, err
+		}
+		for _, p := range matching {
+			current = append(current, p.ProjectID)
+		}
+	}
+
+	matchers, err := newApplicationRuleMatchers(rules)
+	if err != nil {
+		return preview, err
+	}
+	for _, p := range projects {
+		matched, err := matchesRules(matchers, p)
+		if err != nil {
+			return preview, err
+		}
+		member := slices.Contains(current, p.ProjectID)
+		switch {
+		case matched && !member:
+			preview.Gained = append(preview.Gained, p)
+		case !matched && member:
+			preview.Lost = append(preview.Lost, p)
+		case matched:
+			preview.Unchanged = append(preview.Unchanged, p)
+		}
+	}
+
+	return preview, nil
+}
+
+// Shows which projects in the tenant the application would gain or lose if its rules were replaced with the proposed rules
+func (c *Cx1Client) PreviewApplicationRuleChange(application Application, rules []ApplicationRule) (ApplicationRulePreview, error) {
+	projects, err := c.GetAllProjects()
+	if err != nil {
+		return ApplicationRulePreview{}, fmt.Errorf("failed to get projects: %s", err)
+	}
+	return PreviewApplicationRules(application, rules, projects)
+}
+
+// Returns the projects which are not matched by the rules of any of the applications
+func GetProjectsMatchedByNoApplication(applications []Application, projects []Project) ([]Project, error) {
+	unmatched := []Project{}
+	matchers := make([][]applicationRuleMatcher, len(applications))
+	for i, a := range applications {
+		var err error
+		if matchers[i], err = newApplicationRuleMatchers(a.Rules); err != nil {
+			return unmatched, fmt.Errorf("failed to evaluate rules of application %v: %s", a.String(), err)
+		}
+	}
+
+	for _, p := range projects {
+		matched := false
+		for i, a := range applications {
+			m, err := matchesRules(matchers[i], p)
+			if err != nil {
+				return unmatched, fmt.Errorf("failed to evaluate rules of application %v: %s", a.String(), err)
+			}
+			if m {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			unmatched = append(unmatched, p)
+		}
+	}
+	return unmatched, nil
+}
+
+// Returns the projects in the tenant which are not matched by the rules of any application
+func (c *Cx1Client) GetProjectsWithoutApplication() ([]Project, error) {
+	applications, err := c.GetAllApplications()
+	if err != nil {
+		return []Project{}, fmt.Errorf("failed to get applications: %s", err)
+	}
+	projects, err := c.GetAllProjects()
+	if err != nil {
+		return []Project{}, fmt.Errorf("failed to get projects: %s", err)
+	}
+	return GetProjectsMatchedByNoApplication(applications, projects)
+}
+
+func (p ApplicationRulePreview) String() string {
+	lines := []string{fmt.Sprintf("Application %v: %d gained, %d lost, %d unchanged", p.ApplicationName, len(p.Gained), len(p.Lost), len(p.Unchanged))}
+	for _, project := range p.Gained {
+		lines = append(lines, fmt.Sprintf("  + %v", project.String()))
+	}
+	for _, project := range p.Lost {
+		lines = append(lines, fmt.Sprintf("  - %v", project.String()))
+	}
+	return strings.Join(lines, "\n")
+}
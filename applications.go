@@ -410,7 +410,7 @@ func (a *Application) RemoveRule(ruleID string) {
 
 // AssignProject will create or update a "project.name.in" type rule to assign the project to the app
 func (a *Application) AssignProject(project *Project) {
-	a.AddRule("project.name.in", project.Name)
+	a.AddRule(ApplicationRuleTypes.NameIn, project.Name)
 
 	if *a.ProjectIds == nil || !slices.Contains(*a.ProjectIds, project.ProjectID) {
 		if *a.ProjectIds == nil {
@@ -432,7 +432,7 @@ func (a *Application) AssignProject(project *Project) {
 
 // UnassignProject will remove the project from the "project.name.in" rule if it's there, and if the rule ends up empty it will remove the rule
 func (a *Application) UnassignProject(project *Project) {
-	rules := a.GetRulesByType("project.name.in")
+	rules := a.GetRulesByType(ApplicationRuleTypes.NameIn)
 	if len(rules) > 0 {
 		for _, rule := range rules {
 			if strings.Contains(fmt.Sprintf(";%v;", rule.Value), fmt.Sprintf(";%v;", project.Name)) {
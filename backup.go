@@ -0,0 +1,1128 @@
+package Cx1ClientGo
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/exp/slices"
+)
+
+// Version of the backup archive format, archives from newer versions can not be restored
+const TenantBackupVersion = 1
+
+// the files in the backup archive, in the order they are written
+func (b *TenantBackup) archiveFiles() []struct {
+	name string
+	data interface{}
+} {
+	return []struct {
+		name string
+		data interface{}
+	}{
+		{"tenant-configuration.json", &b.TenantConfiguration},
+		{"result-states.json", &b.ResultStates},
+		{"roles.json", &b.Roles},
+		{"groups.json", &b.Groups},
+		{"users.json", &b.Users},
+		{"clients.json", &b.Clients},
+		{"applications.json", &b.Applications},
+		{"projects.json", &b.Projects},
+		{"scan-schedules.json", &b.ScanSchedules},
+		{"queries.json", &b.Queries},
+		{"presets.json", &b.Presets},
+	}
+}
+
+// Writes a backup of the tenant configuration to w as a zip archive, see BackupTenantWithOptions
+func (c *Cx1Client) BackupTenant(w io.Writer) error {
+	return c.BackupTenantWithOptions(w, TenantBackupOptions{})
+}
+
+// Writes a backup of the tenant configuration to w as a zip archive, containing a manifest.json and one JSON file per object type
+func (c *Cx1Client) BackupTenantWithOptions(w io.Writer, options TenantBackupOptions) error {
+	backup, err := c.GetTenantBackup(options)
+	if err != nil {
+		return err
+	}
+	return backup.Write(w)
+}
+
+// Retrieves the tenant configuration: projects with their project-level configuration, applications with rules, groups with roles and members,
+// users with their directly-assigned roles, OIDC clients (without secrets), custom roles, custom presets with their query families,
+// custom SAST queries with source at the tenant, application and project levels, scan schedules, custom result states and the tenant-level configuration
+func (c *Cx1Client) GetTenantBackup(options TenantBackupOptions) (TenantBackup, error) {
+	backup := TenantBackup{
+		Version:             TenantBackupVersion,
+		Created:             time.Now(),
+		Tenant:              c.GetTenantName(),
+		Projects:            []TenantBackupProject{},
+		Applications:        []Application{},
+		Groups:              []TenantBackupGroup{},
+		Users:               []TenantBackupUser{},
+		Clients:             []TenantBackupClient{},
+		Roles:               []TenantBackupRole{},
+		Presets:             []TenantBackupPreset{},
+		Queries:             []TenantBackupQuery{},
+		ScanSchedules:       []ProjectScanSchedule{},
+		ResultStates:        []ResultState{},
+		TenantConfiguration: []ConfigurationSetting{},
+	}
+
+	settings, err := c.GetTenantConfiguration()
+	if err != nil {
+		return backup, fmt.Errorf("failed to get tenant configuration: %s", err)
+	}
+	for _, s := range settings {
+		if s.OriginLevel == "Tenant" {
+			backup.TenantConfiguration = append(backup.TenantConfiguration, s)
+		}
+	}
+
+	if backup.ResultStates, err = c.GetCustomResultStates(); err != nil {
+		return backup, fmt.Errorf("failed to get custom result states: %s", err)
+	}
+
+	if backup.Roles, err = c.getCustomRoles(); err != nil {
+		return backup, fmt.Errorf("failed to get roles: %s", err)
+	}
+
+	groups, err := c.GetAllGroups()
+	if err != nil {
+		return backup, fmt.Errorf("failed to get groups: %s", err)
+	}
+	for _, g := range flattenGroups(groups, "") {
+		members, err := c.GetGroupMembersByID(g.GroupID)
+		if err != nil {
+			return backup, fmt.Errorf("failed to get members of group %v: %s", g.String(), err)
+		}
+		group := TenantBackupGroup{
+			GroupID:     g.GroupID,
+			ParentID:    g.ParentID,
+			Name:        g.Name,
+			Path:        g.Path,
+			ClientRoles: g.ClientRoles,
+			RealmRoles:  g.RealmRoles,
+			Members:     []string{},
+		}
+		for _, u := range members {
+			group.Members = append(group.Members, u.UserID)
+		}
+		backup.Groups = append(backup.Groups, group)
+	}
+
+	if !options.SkipUsers {
+		users, err := c.GetAllUsers()
+		if err != nil {
+			return backup, fmt.Errorf("failed to get users: %s", err)
+		}
+		for _, u := range users {
+			roles, err := c.GetUserAssignedRoles(&u)
+			if err != nil {
+				return backup, fmt.Errorf("failed to get roles of user %v: %s", u.String(), err)
+			}
+			user := TenantBackupUser{User: u, AppRoles: []string{}, IAMRoles: []string{}}
+			for _, r := range roles {
+				if r.ClientID == c.GetASTAppID() {
+					user.AppRoles = append(user.AppRoles, r.Name)
+				} else {
+					user.IAMRoles = append(user.IAMRoles, r.Name)
+				}
+			}
+			backup.Users = append(backup.Users, user)
+		}
+	}
+
+	clients, err := c.GetClients()
+	if err != nil {
+		return backup, fmt.Errorf("failed to get OIDC clients: %s", err)
+	}
+	for _, oc := range clients {
+		backup.Clients = append(backup.Clients, TenantBackupClient{
+			ID:                   oc.ID,
+			ClientID:             oc.ClientID,
+			Enabled:              oc.Enabled,
+			SecretExpirationDays: oc.SecretExpirationDays,
+			NotificationEmails:   oc.NotificationEmails,
+		})
+	}
+
+	if backup.Applications, err = c.GetAllApplications(); err != nil {
+		return backup, fmt.Errorf("failed to get applications: %s", err)
+	}
+
+	projects, err := c.GetAllProjects()
+	if err != nil {
+		return backup, fmt.Errorf("failed to get projects: %s", err)
+	}
+	for _, p := range projects {
+		settings, err := c.GetProjectConfigurationByID(p.ProjectID)
+		if err != nil {
+			return backup, fmt.Errorf("failed to get configuration of project %v: %s", p.String(), err)
+		}
+		project := TenantBackupProject{Project: p, Configuration: []ConfigurationSetting{}}
+		for _, s := range settings {
+			if s.OriginLevel == "Project" {
+				project.Configuration = append(project.Configuration, s)
+			}
+		}
+		backup.Projects = append(backup.Projects, project)
+	}
+
+	if !options.SkipSchedules {
+		if backup.ScanSchedules, err = c.GetAllScanSchedules(); err != nil {
+			return backup, fmt.Errorf("failed to get scan schedules: %s", err)
+		}
+	}
+
+	if !options.SkipQueries {
+		if backup.Queries, err = c.getTenantBackupQueries(backup.Applications, projects); err != nil {
+			return backup, err
+		}
+	}
+
+	sastPresets, err := c.GetAllSASTPresets()
+	if err != nil {
+		return backup, fmt.Errorf("failed to get SAST presets: %s", err)
+	}
+	iacPresets, err := c.GetAllIACPresets()
+	if err != nil {
+		return backup, fmt.Errorf("failed to get IAC presets: %s", err)
+	}
+	for _, p := range append(sastPresets, iacPresets...) {
+		if !p.Custom {
+			continue
+		}
+		if err := c.GetPresetContents(&p); err != nil {
+			return backup, fmt.Errorf("failed to get contents of preset %v: %s", p.String(), err)
+		}
+		backup.Presets = append(backup.Presets, TenantBackupPreset{Engine: p.Engine, Preset: p})
+	}
+
+	return backup, nil
+}
+
+// returns the custom ast-app roles with their sub-role names
+func (c *Cx1Client) getCustomRoles() ([]TenantBackupRole, error) {
+	customRoles := []TenantBackupRole{}
+	roles, err := c.GetAppRoles()
+	if err != nil {
+		return customRoles, err
+	}
+	for _, r := range roles {
+		if !isCustomRole(r) {
+			continue
+		}
+		composites, err := c.GetRoleComposites(&r)
+		if err != nil {
+			return customRoles, fmt.Errorf("failed to get sub-roles of role %v: %s", r.String(), err)
+		}
+		role := TenantBackupRole{
+			RoleID:      r.RoleID,
+			Name:        r.Name,
+			Description: r.Description,
+			Creator:     r.Attributes.Creator[0],
+			SubRoles:    []string{},
+		}
+		for _, s := range composites {
+			role.SubRoles = append(role.SubRoles, s.Name)
+		}
+		customRoles = append(customRoles, role)
+	}
+	return customRoles, nil
+}
+
+// custom SAST queries at the tenant, application and project levels, read through audit sessions: one per application on the first of
+// its projects with a completed SAST scan, one per project which is not in an application, and one tenant-level session per language
+// with custom queries. Only the project-level queries of the projects are included, and the application and project-level queries
+// of applications and projects without a completed SAST scan can not be read.
+func (c *Cx1Client) getTenantBackupQueries(applications []Application, projects []Project) ([]TenantBackupQuery, error) {
+	queries := []TenantBackupQuery{}
+	languages := []string{}
+	addLanguage := func(language string) {
+		if !slices.ContainsFunc(languages, func(l string) bool { return strings.EqualFold(l, language) }) {
+			languages = append(languages, language)
+		}
+	}
+	unread := map[string]bool{}
+	for _, p := range projects {
+		unread[p.ProjectID] = true
+	}
+
+	// application-level queries are read through the session's project, project-level queries of the other projects are read through the same session
+	read := func(session AuditSession, applicationID string, projectIDs []string) error {
+		defer func() {
+			if err := c.DeleteAuditSession(&session); err != nil {
+				c.config.Logger.Warnf("Failed to delete audit session %v: %s", session.ID, err)
+			}
+		}()
+		for _, language := range session.Languages {
+			addLanguage(language)
+		}
+
+		for _, projectID := range projectIDs {
+			include := unread[projectID]
+			found, err := c.getAuditSessionQueries(&session, AUDIT_QUERY.PROJECT, projectID, func(q SASTQuery) bool {
+				if q.Level == AUDIT_QUERY.APPLICATION {
+					return applicationID != "" && q.LevelID == applicationID && projectID == session.ProjectID
+				}
+				return q.Level == AUDIT_QUERY.PROJECT && include
+			})
+			if err != nil {
+				return fmt.Errorf("failed to get queries for project %v: %s", projectID, err)
+			}
+			for _, q := range found {
+				queries = append(queries, newTenantBackupQuery(q))
+			}
+			delete(unread, projectID)
+		}
+		return nil
+	}
+
+	sessions := 0
+	for _, app := range applications {
+		if app.ProjectIds == nil || len(*app.ProjectIds) == 0 {
+			continue
+		}
+		session, err := c.getApplicationAuditSession(app)
+		if err != nil {
+			c.config.Logger.Warnf("Queries for application %v and its projects will not be included: %s", app.String(), err)
+			continue
+		}
+		sessions++
+
+		projectIDs := []string{session.ProjectID}
+		for _, id := range *app.ProjectIds {
+			if id != session.ProjectID && unread[id] {
+				projectIDs = append(projectIDs, id)
+			}
+		}
+		if err := read(session, app.ApplicationID, projectIDs); err != nil {
+			return queries, fmt.Errorf("failed to get queries for application %v: %s", app.String(), err)
+		}
+	}
+
+	for _, p := range projects {
+		if !unread[p.ProjectID] || (p.Applications != nil && len(*p.Applications) > 0) {
+			continue
+		}
+		session, err := c.getLastScanAuditSession(p.ProjectID)
+		if err != nil {
+			c.config.Logger.Warnf("Project-level queries for project %v will not be included: %s", p.String(), err)
+			continue
+		}
+		sessions++
+		if err := read(session, "", []string{p.ProjectID}); err != nil {
+			return queries, err
+		}
+	}
+
+	if sessions == 0 && len(projects) > 0 {
+		c.config.Logger.Warnf("No project has a completed SAST scan, application and project-level queries are not included in the backup")
+	}
+
+	// executable tenant-level queries are listed for presets, other tenant-level queries are only found in the languages of the scans read above
+	presetQueries, err := c.GetSASTPresetQueries()
+	if err != nil {
+		return queries, fmt.Errorf("failed to get SAST preset queries: %s", err)
+	}
+	for _, q := range presetQueries.GetQueries() {
+		if q.Level == AUDIT_QUERY.TENANT {
+			addLanguage(q.Language)
+		}
+	}
+	slices.Sort(languages)
+
+	for _, language := range languages {
+		session, err := c.GetAuditSession("sast", language)
+		if err != nil {
+			c.config.Logger.Warnf("Tenant-level %v queries will not be included: %s", language, err)
+			continue
+		}
+		found, err := c.getAuditSessionQueries(&session, AUDIT_QUERY.TENANT, AUDIT_QUERY.TENANT, func(q SASTQuery) bool {
+			return q.Level == AUDIT_QUERY.TENANT && strings.EqualFold(q.Language, language)
+		})
+		if derr := c.DeleteAuditSession(&session); derr != nil {
+			c.config.Logger.Warnf("Failed to delete audit session %v: %s", session.ID, derr)
+		}
+		if err != nil {
+			return queries, fmt.Errorf("failed to get tenant-level %v queries: %s", language, err)
+		}
+		for _, q := range found {
+			queries = append(queries, newTenantBackupQuery(q))
+		}
+	}
+
+	return queries, nil
+}
+
+// returns the custom queries matching the filter in the audit session's query tree for the level and levelID, with their source
+func (c *Cx1Client) getAuditSessionQueries(auditSession *AuditSession, level, levelID string, filter func(SASTQuery) bool) ([]SASTQuery, error) {
+	queries := []SASTQuery{}
+	collection, err := c.GetAuditSASTQueriesByLevelID(auditSession, level, levelID)
+	if err != nil {
+		return queries, err
+	}
+
+	for _, q := range collection.GetQueries() {
+		if q.Level == AUDIT_QUERY.PRODUCT || !q.Custom || !filter(q) {
+			continue
+		}
+		editorKey := q.EditorKey
+		if editorKey == "" {
+			editorKey = strconv.FormatUint(q.QueryID, 10)
+		}
+		query, err := c.GetAuditSASTQueryByKey(auditSession, editorKey)
+		if err != nil {
+			return queries, fmt.Errorf("failed to get source of query %v: %s", q.Path, err)
+		}
+		// project-level queries may be read through another project's session
+		query.Level, query.LevelID = q.Level, q.LevelID
+		queries = append(queries, query)
+	}
+	return queries, nil
+}
+
+// creates an audit session on the first project in the application which has a completed SAST scan
+func (c *Cx1Client) getApplicationAuditSession(app Application) (AuditSession, error) {
+	if app.ProjectIds != nil {
+		for _, projectID := range *app.ProjectIds {
+			session, err := c.getLastScanAuditSession(projectID)
+			if err == nil {
+				return session, nil
+			}
+			c.config.Logger.Debugf("Unable to create an audit session for application %v on project %v: %s", app.String(), projectID, err)
+		}
+	}
+	return AuditSession{}, fmt.Errorf("application %v has no project with a completed SAST scan", app.String())
+}
+
+// creates an audit session on the last completed SAST scan of the project
+func (c *Cx1Client) getLastScanAuditSession(projectID string) (AuditSession, error) {
+	scans, err := c.GetLastScansByEngineFiltered("sast", 1, ScanFilter{
+		BaseFilter: BaseFilter{Limit: c.config.Pagination.Scans},
+		ProjectID:  projectID,
+		Statuses:   []string{ScanStatus.Completed},
+		Sort:       []string{ScanSortCreatedDescending},
+	})
+	if err != nil {
+		return AuditSession{}, fmt.Errorf("failed to get the last SAST scan: %s", err)
+	}
+	if len(scans) == 0 {
+		return AuditSession{}, fmt.Errorf("no completed SAST scan")
+	}
+	return c.GetAuditSessionByID("sast", projectID, scans[0].ScanID)
+}
+
+// Writes the backup as a zip archive
+func (b TenantBackup) Write(w io.Writer) error {
+	archive := zip.NewWriter(w)
+
+	manifest := map[string]interface{}{
+		"version": b.Version,
+		"created": b.Created,
+		"tenant":  b.Tenant,
+	}
+	write := func(name string, data interface{}) error {
+		f, err := archive.Create(name)
+		if err != nil {
+			return err
+		}
+		encoder := json.NewEncoder(f)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(data)
+	}
+
+	if err := write("manifest.json", manifest); err != nil {
+		return fmt.Errorf("failed to write backup manifest: %s", err)
+	}
+	for _, f := range b.archiveFiles() {
+		if err := write(f.name, f.data); err != nil {
+			return fmt.Errorf("failed to write %v to backup: %s", f.name, err)
+		}
+	}
+
+	return archive.Close()
+}
+
+// Reads a backup archive written by BackupTenant
+func ReadTenantBackup(r io.ReaderAt, size int64) (TenantBackup, error) {
+	var backup TenantBackup
+	archive, err := zip.NewReader(r, size)
+	if err != nil {
+		return backup, fmt.Errorf("failed to open backup archive: %s", err)
+	}
+
+	read := func(name string, data interface{}) (bool, error) {
+		for _, f := range archive.File {
+			if f.Name != name {
+				continue
+			}
+			rc, err := f.Open()
+			if err != nil {
+				return true, err
+			}
+			defer rc.Close()
+			return true, json.NewDecoder(rc).Decode(data)
+		}
+		return false, nil
+	}
+
+	found, err := read("manifest.json", &backup)
+	if err != nil || !found {
+		return backup, fmt.Errorf("failed to read backup manifest: %v", err)
+	}
+	if backup.Version > TenantBackupVersion {
+		return backup, fmt.Errorf("backup version %d is newer than the supported version %d", backup.Version, TenantBackupVersion)
+	}
+
+	for _, f := range backup.archiveFiles() {
+		if _, err := read(f.name, f.data); err != nil {
+			return backup, fmt.Errorf("failed to read %v from backup: %s", f.name, err)
+		}
+	}
+
+	return backup, nil
+}
+
+// Restores a backup archive written by BackupTenant, see RestoreTenantBackup
+func (c *Cx1Client) RestoreTenant(r io.ReaderAt, size int64) (TenantRestoreResult, error) {
+	backup, err := ReadTenantBackup(r, size)
+	if err != nil {
+		return TenantRestoreResult{}, err
+	}
+	return c.RestoreTenantBackup(backup)
+}
+
+// Recreates the objects in the backup, intended for an empty tenant. Objects which already exist with the same name are
+// mapped to the existing object and left unchanged. References between objects (eg: project groups and applications,
+// group members) are remapped to the new IDs, which are returned in the result.
+// Objects which fail to restore are listed in result.Errors and the restore continues. The error is set if the backup can not be restored,
+// or if every object which was attempted failed to restore.
+// Tenant-level custom queries are restored using a tenant-level audit session. Application and project-level queries require
+// an audit session for a scan of the restored project, so they are listed in result.Skipped and can be restored with RestoreTenantQueries.
+// Group realm roles and OIDC client secrets are not restored.
+func (c *Cx1Client) RestoreTenantBackup(backup TenantBackup) (TenantRestoreResult, error) {
+	result := TenantRestoreResult{
+		Groups:       map[string]string{},
+		Users:        map[string]string{},
+		Clients:      map[string]string{},
+		Roles:        map[string]string{},
+		Applications: map[string]string{},
+		Projects:     map[string]string{},
+		Presets:      map[string]string{},
+		ResultStates: map[uint64]uint64{},
+		Errors:       []string{},
+		Skipped:      []string{},
+	}
+
+	if backup.Version > TenantBackupVersion {
+		return result, fmt.Errorf("backup version %d is newer than the supported version %d", backup.Version, TenantBackupVersion)
+	}
+
+	c.restoreResultStates(backup, &result)
+	c.restoreRoles(backup, &result)
+	c.restoreGroups(backup, &result)
+	c.restoreUsers(backup, &result)
+	c.restoreClients(backup, &result)
+
+	// presets may include custom queries, and the tenant and project configuration may refer to presets
+	restoredQueries := c.restoreTenantLevelQueries(backup, &result)
+	c.restorePresets(backup, &result)
+
+	if len(backup.TenantConfiguration) > 0 {
+		settings := make([]ConfigurationSetting, len(backup.TenantConfiguration))
+		for i, s := range backup.TenantConfiguration {
+			settings[i] = ConfigurationSetting{Key: s.Key, Value: s.Value, AllowOverride: s.AllowOverride}
+		}
+		if err := c.UpdateTenantConfiguration(settings); err != nil {
+			result.fail(c, "tenant configuration", err)
+		}
+	}
+
+	c.restoreApplications(backup, &result)
+	createdProjects := c.restoreProjects(backup, &result)
+
+	for _, s := range backup.ScanSchedules {
+		projectID, ok := result.Projects[s.ProjectID]
+		if !ok || !createdProjects[projectID] {
+			continue
+		}
+		if s.StartTime == "" {
+			s.StartTime = s.NextStartTime.Format("15:04")
+		}
+		if err := c.CreateScanScheduleByID(projectID, s); err != nil {
+			result.fail(c, fmt.Sprintf("scan schedule for project %v", s.ProjectID), err)
+		}
+	}
+
+	for _, level := range []string{AUDIT_QUERY.APPLICATION, AUDIT_QUERY.PROJECT} {
+		count := 0
+		for _, q := range backup.Queries {
+			if q.Level == level {
+				count++
+			}
+		}
+		if count > 0 {
+			c.config.Logger.Warnf("%d %v-level queries were not restored, use RestoreTenantQueries with an audit session for each restored project", count, level)
+			result.Skipped = append(result.Skipped, fmt.Sprintf("%d %v-level queries: restore with RestoreTenantQueries", count, level))
+		}
+	}
+
+	if len(result.Errors) > 0 && restoredQueries == 0 && result.restoredNothing() {
+		return result, fmt.Errorf("failed to restore any of the objects in the backup: %v", strings.Join(result.Errors, "; "))
+	}
+	return result, nil
+}
+
+// restores the tenant-level queries with a tenant-level audit session per language, and returns the number of queries restored
+func (c *Cx1Client) restoreTenantLevelQueries(backup TenantBackup, result *TenantRestoreResult) int {
+	restoredQueries := 0
+	languages := []string{}
+	for _, q := range backup.Queries {
+		if q.Level == AUDIT_QUERY.TENANT && !slices.Contains(languages, q.Language) {
+			languages = append(languages, q.Language)
+		}
+	}
+	for _, language := range languages {
+		session, err := c.GetAuditSession("sast", language)
+		if err != nil {
+			result.fail(c, fmt.Sprintf("tenant-level %v queries", language), err)
+			continue
+		}
+		for _, q := range backup.Queries {
+			if q.Level == AUDIT_QUERY.TENANT && q.Language == language {
+				if err := c.restoreQuery(&session, q, AUDIT_QUERY.TENANT); err != nil {
+					result.fail(c, fmt.Sprintf("query %v", q.Path), err)
+				} else {
+					restoredQueries++
+				}
+			}
+		}
+		if err := c.DeleteAuditSession(&session); err != nil {
+			c.config.Logger.Warnf("Failed to delete audit session %v: %s", session.ID, err)
+		}
+	}
+
+	return restoredQueries
+}
+
+// true if no object was restored or mapped to an existing object
+func (r TenantRestoreResult) restoredNothing() bool {
+	return len(r.Groups) == 0 && len(r.Users) == 0 && len(r.Clients) == 0 && len(r.Roles) == 0 &&
+		len(r.Applications) == 0 && len(r.Projects) == 0 && len(r.Presets) == 0 && len(r.ResultStates) == 0
+}
+
+// Restores the application and project-level queries in the backup which belong to the audit session's project and application.
+// The session should be created with GetAuditSessionByID for a scan of a project restored by RestoreTenantBackup.
+func (c *Cx1Client) RestoreTenantQueries(backup TenantBackup, result TenantRestoreResult, auditSession *AuditSession) error {
+	failures := []string{}
+	for _, q := range backup.Queries {
+		var err error
+		switch {
+		case q.Level == AUDIT_QUERY.APPLICATION && auditSession.ApplicationID != "" && result.Applications[q.LevelID] == auditSession.ApplicationID:
+			err = c.restoreQuery(auditSession, q, AUDIT_QUERY.APPLICATION)
+		case q.Level == AUDIT_QUERY.PROJECT && result.Projects[q.LevelID] == auditSession.ProjectID:
+			err = c.restoreQuery(auditSession, q, AUDIT_QUERY.PROJECT)
+		default:
+			continue
+		}
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("query %v: %s", q.Path, err))
+		}
+	}
+
+	if len(failures) > 0 {
+		return fmt.Errorf("failed to restore %d queries: %v", len(failures), strings.Join(failures, "; "))
+	}
+	return nil
+}
+
+// creates or overrides the query at the level in the audit session and sets the source
+func (c *Cx1Client) restoreQuery(auditSession *AuditSession, q TenantBackupQuery, level string) error {
+	treeLevel, levelID := AUDIT_QUERY.TENANT, AUDIT_QUERY.TENANT
+	if auditSession.ProjectID != "" {
+		treeLevel, levelID = AUDIT_QUERY.PROJECT, auditSession.ProjectID
+	}
+	collection, err := c.GetAuditSASTQueriesByLevelID(auditSession, treeLevel, levelID)
+	if err != nil {
+		return fmt.Errorf("failed to get queries for %v: %s", auditSession.String(), err)
+	}
+
+	targetLevelID := AUDIT_QUERY.TENANT
+	switch level {
+	case AUDIT_QUERY.APPLICATION:
+		targetLevelID = auditSession.ApplicationID
+	case AUDIT_QUERY.PROJECT:
+		targetLevelID = auditSession.ProjectID
+	}
+
+	var failures []QueryFailure
+	var updated SASTQuery
+	if existing := collection.GetQueryByLevelAndName(level, targetLevelID, q.Language, q.Group, q.Name); existing != nil {
+		updated, failures, err = c.UpdateSASTQuerySource(auditSession, *existing, q.Source)
+	} else if base := collection.GetQueryByName(q.Language, q.Group, q.Name); base != nil {
+		var override SASTQuery
+		if override, err = c.CreateSASTQueryOverride(auditSession, level, base); err == nil {
+			updated, failures, err = c.UpdateSASTQuerySource(auditSession, override, q.Source)
+		}
+	} else if level == AUDIT_QUERY.TENANT {
+		_, failures, err = c.CreateNewSASTQuery(auditSession, SASTQuery{
+			Name:               q.Name,
+			Language:           q.Language,
+			Group:              q.Group,
+			Severity:           q.Severity,
+			CweID:              q.CweID,
+			IsExecutable:       q.IsExecutable,
+			QueryDescriptionId: q.QueryDescriptionId,
+			Source:             q.Source,
+		})
+	} else {
+		return fmt.Errorf("no base query %v/%v/%v to override", q.Language, q.Group, q.Name)
+	}
+
+	if err != nil {
+		return err
+	}
+	if len(failures) > 0 {
+		return fmt.Errorf("query source has %d compilation failures", len(failures))
+	}
+
+	// overrides inherit the severity of the base query
+	if updated.EditorKey != "" && q.Severity != "" && updated.Severity != q.Severity {
+		metadata := updated.GetMetadata()
+		metadata.Severity = q.Severity
+		if _, err = c.UpdateSASTQueryMetadata(auditSession, updated, metadata); err != nil {
+			return fmt.Errorf("failed to update severity: %s", err)
+		}
+	}
+	return nil
+}
+
+func (c *Cx1Client) restoreResultStates(backup TenantBackup, result *TenantRestoreResult) {
+	existing, err := c.GetCustomResultStates()
+	if err != nil {
+		result.fail(c, "custom result states", err)
+		return
+	}
+	for _, s := range backup.ResultStates {
+		if e := findResultState(existing, s.Name); e != nil {
+			result.ResultStates[s.ID] = e.ID
+			continue
+		}
+		state, err := c.CreateCustomResultState(s.Name)
+		if err != nil {
+			result.fail(c, fmt.Sprintf("result state %v", s.Name), err)
+			continue
+		}
+		result.ResultStates[s.ID] = state.ID
+	}
+}
+
+func (c *Cx1Client) restoreRoles(backup TenantBackup, result *TenantRestoreResult) {
+	created := []Role{}
+	for _, r := range backup.Roles {
+		if role, err := c.GetAppRoleByName(r.Name); err == nil {
+			result.Roles[r.RoleID] = role.RoleID
+			continue
+		}
+		role, err := c.CreateAppRole(r.Name, r.Creator)
+		if err != nil {
+			result.fail(c, fmt.Sprintf("role %v", r.Name), err)
+			continue
+		}
+		result.Roles[r.RoleID] = role.RoleID
+		created = append(created, role)
+	}
+
+	if len(created) == 0 {
+		return
+	}
+	// sub-roles may be other custom roles, so composites are added once all roles exist
+	roles, err := c.GetRoles()
+	if err != nil {
+		result.fail(c, "role composites", err)
+		return
+	}
+	for _, role := range created {
+		for _, r := range backup.Roles {
+			if r.Name != role.Name || len(r.SubRoles) == 0 {
+				continue
+			}
+			subRoles := findRoles(roles, r.SubRoles, func(name string) {
+				result.fail(c, fmt.Sprintf("sub-role %v of role %v", name, r.Name), fmt.Errorf("role not found"))
+			})
+			if len(subRoles) > 0 {
+				if err := c.AddRoleComposites(&role, &subRoles); err != nil {
+					result.fail(c, fmt.Sprintf("sub-roles of role %v", r.Name), err)
+				}
+			}
+		}
+	}
+}
+
+func (c *Cx1Client) restoreGroups(backup TenantBackup, result *TenantRestoreResult) {
+	current, err := c.GetAllGroups()
+	if err != nil {
+		result.fail(c, "groups", err)
+		return
+	}
+	existing := map[string]Group{}
+	for _, g := range flattenGroups(current, "") {
+		existing[g.Path] = g
+	}
+
+	// parents are created before their subgroups
+	groups := make([]TenantBackupGroup, len(backup.Groups))
+	copy(groups, backup.Groups)
+	sort.SliceStable(groups, func(i, j int) bool {
+		return strings.Count(groups[i].Path, "/") < strings.Count(groups[j].Path, "/")
+	})
+
+	for _, g := range groups {
+		if e, ok := existing[g.Path]; ok {
+			result.Groups[g.GroupID] = e.GroupID
+			continue
+		}
+
+		var group Group
+		var err error
+		if g.ParentID == "" {
+			group, err = c.CreateGroup(g.Name)
+		} else if parentID, ok := result.Groups[g.ParentID]; ok {
+			group, err = c.CreateChildGroup(&Group{GroupID: parentID}, g.Name)
+		} else {
+			err = fmt.Errorf("parent group was not restored")
+		}
+		if err != nil {
+			result.fail(c, fmt.Sprintf("group %v", g.Path), err)
+			continue
+		}
+		result.Groups[g.GroupID] = group.GroupID
+
+		if len(g.ClientRoles) > 0 {
+			if err := c.AddRolesToGroup(&group, g.ClientRoles); err != nil {
+				result.fail(c, fmt.Sprintf("roles of group %v", g.Path), err)
+			}
+		}
+	}
+}
+
+func (c *Cx1Client) restoreUsers(backup TenantBackup, result *TenantRestoreResult) {
+	roles, err := c.GetRoles()
+	if err != nil {
+		result.fail(c, "users", err)
+		return
+	}
+	appRoles, iamRoles := []Role{}, []Role{}
+	for _, r := range roles {
+		if r.ClientID == c.GetASTAppID() {
+			appRoles = append(appRoles, r)
+		} else {
+			iamRoles = append(iamRoles, r)
+		}
+	}
+
+	for _, u := range backup.Users {
+		if user, err := c.GetUserByUserName(u.User.UserName); err == nil {
+			result.Users[u.User.UserID] = user.UserID
+			continue
+		}
+
+		user, err := c.CreateUser(User{
+			Enabled:   u.User.Enabled,
+			FirstName: u.User.FirstName,
+			LastName:  u.User.LastName,
+			UserName:  u.User.UserName,
+			Email:     u.User.Email,
+		})
+		if err != nil {
+			result.fail(c, fmt.Sprintf("user %v", u.User.UserName), err)
+			continue
+		}
+		result.Users[u.User.UserID] = user.UserID
+
+		missing := func(name string) {
+			result.fail(c, fmt.Sprintf("role %v of user %v", name, u.User.UserName), fmt.Errorf("role not found"))
+		}
+		userRoles := append(findRoles(appRoles, u.AppRoles, missing), findRoles(iamRoles, u.IAMRoles, missing)...)
+		if len(userRoles) > 0 {
+			if err := c.AddUserRoles(&user, &userRoles); err != nil {
+				result.fail(c, fmt.Sprintf("roles of user %v", u.User.UserName), err)
+			}
+		}
+	}
+
+	for _, g := range backup.Groups {
+		groupID, ok := result.Groups[g.GroupID]
+		if !ok {
+			continue
+		}
+		for _, member := range g.Members {
+			userID, ok := result.Users[member]
+			if !ok {
+				continue
+			}
+			user := User{UserID: userID, Groups: []Group{}, FilledGroups: true}
+			if err := c.AssignUserToGroupByID(&user, groupID); err != nil {
+				result.fail(c, fmt.Sprintf("membership of user %v in group %v", member, g.Path), err)
+			}
+		}
+	}
+}
+
+func (c *Cx1Client) restoreClients(backup TenantBackup, result *TenantRestoreResult) {
+	for _, oc := range backup.Clients {
+		if client, err := c.GetClientByName(oc.ClientID); err == nil {
+			result.Clients[oc.ID] = client.ID
+			continue
+		}
+
+		days := int(oc.SecretExpirationDays)
+		if days == 0 {
+			days = 365
+		}
+		client, err := c.CreateClient(oc.ClientID, oc.NotificationEmails, days)
+		if err != nil {
+			result.fail(c, fmt.Sprintf("OIDC client %v", oc.ClientID), err)
+			continue
+		}
+		result.Clients[oc.ID] = client.ID
+	}
+}
+
+func (c *Cx1Client) restoreApplications(backup TenantBackup, result *TenantRestoreResult) {
+	for _, a := range backup.Applications {
+		if app, err := c.GetApplicationByName(a.Name); err == nil {
+			result.Applications[a.ApplicationID] = app.ApplicationID
+			continue
+		}
+
+		app, err := c.CreateApplication(a.Name)
+		if err != nil {
+			result.fail(c, fmt.Sprintf("application %v", a.Name), err)
+			continue
+		}
+		result.Applications[a.ApplicationID] = app.ApplicationID
+
+		app.Description = a.Description
+		app.Criticality = a.Criticality
+		app.Tags = a.Tags
+		app.ProjectIds = nil // projects are assigned when they are restored
+		app.Rules = []ApplicationRule{}
+		for _, r := range a.Rules {
+			app.Rules = append(app.Rules, ApplicationRule{Type: r.Type, Value: r.Value})
+		}
+		if err := c.UpdateApplication(&app); err != nil {
+			result.fail(c, fmt.Sprintf("application %v", a.Name), err)
+		}
+	}
+}
+
+// returns the IDs of the projects which were created
+func (c *Cx1Client) restoreProjects(backup TenantBackup, result *TenantRestoreResult) map[string]bool {
+	created := map[string]bool{}
+	existing, err := c.GetAllProjects()
+	if err != nil {
+		result.fail(c, "projects", err)
+		return created
+	}
+
+	for _, bp := range backup.Projects {
+		p := bp.Project
+		if e := findProject(existing, p.Name); e != nil {
+			result.Projects[p.ProjectID] = e.ProjectID
+			continue
+		}
+
+		groupIDs := []string{}
+		for _, id := range p.Groups {
+			if newID, ok := result.Groups[id]; ok {
+				groupIDs = append(groupIDs, newID)
+			}
+		}
+
+		project, err := c.CreateProject(p.Name, groupIDs, p.Tags)
+		if err != nil {
+			result.fail(c, fmt.Sprintf("project %v", p.Name), err)
+			continue
+		}
+		result.Projects[p.ProjectID] = project.ProjectID
+		created[project.ProjectID] = true
+
+		patch := ProjectPatch{Criticality: &p.Criticality}
+		if p.RepoUrl != "" {
+			patch.RepoUrl = &p.RepoUrl
+		}
+		if p.MainBranch != "" {
+			patch.MainBranch = &p.MainBranch
+		}
+		if err := c.PatchProjectByID(project.ProjectID, patch); err != nil {
+			result.fail(c, fmt.Sprintf("project %v", p.Name), err)
+		}
+
+		if p.Applications != nil && len(*p.Applications) > 0 {
+			applicationIDs := []string{}
+			for _, id := range *p.Applications {
+				if newID, ok := result.Applications[id]; ok {
+					applicationIDs = append(applicationIDs, newID)
+				}
+			}
+			if project, err = c.GetProjectByID(project.ProjectID); err == nil {
+				project.Applications = &applicationIDs
+				err = c.UpdateProject(&project)
+			}
+			if err != nil {
+				result.fail(c, fmt.Sprintf("applications of project %v", p.Name), err)
+			}
+		}
+
+		if len(bp.Configuration) > 0 {
+			settings := make([]ConfigurationSetting, len(bp.Configuration))
+			for i, s := range bp.Configuration {
+				settings[i] = ConfigurationSetting{Key: s.Key, Value: s.Value, AllowOverride: s.AllowOverride}
+			}
+			if err := c.UpdateProjectConfigurationByID(project.ProjectID, settings); err != nil {
+				result.fail(c, fmt.Sprintf("configuration of project %v", p.Name), err)
+			}
+		}
+	}
+
+	return created
+}
+
+func (c *Cx1Client) restorePresets(backup TenantBackup, result *TenantRestoreResult) {
+	existing := map[string][]Preset{}
+	var err error
+	if existing["sast"], err = c.GetAllSASTPresets(); err != nil {
+		result.fail(c, "SAST presets", err)
+		return
+	}
+	if existing["iac"], err = c.GetAllIACPresets(); err != nil {
+		result.fail(c, "IAC presets", err)
+		return
+	}
+
+	for _, bp := range backup.Presets {
+		p := bp.Preset
+		if e := findPreset(existing[bp.Engine], p.Name); e != nil {
+			result.Presets[p.PresetID] = e.PresetID
+			continue
+		}
+
+		presetID, err := c.createPresetWithFamilies(bp.Engine, p.Name, p.Description, p.QueryFamilies)
+		if err != nil {
+			result.fail(c, fmt.Sprintf("%v preset %v", bp.Engine, p.Name), err)
+			continue
+		}
+		result.Presets[p.PresetID] = presetID
+	}
+}
+
+// creates a preset from query families, falling back to the pre-3.30 preset API for SAST where needed
+func (c *Cx1Client) createPresetWithFamilies(engine, name, description string, families []QueryFamily) (string, error) {
+	if engine != "sast" || c.newPresetsEnabled() {
+		return c.createPreset(engine, name, description, families)
+	}
+
+	queryIDs := []uint64{}
+	for _, f := range families {
+		for _, id := range f.QueryIDs {
+			if qid, err := strconv.ParseUint(id, 10, 64); err == nil {
+				queryIDs = append(queryIDs, qid)
+			}
+		}
+	}
+	preset, err := c.CreatePreset_v330(name, description, queryIDs)
+	return strconv.FormatUint(preset.PresetID, 10), err
+}
+
+func newTenantBackupQuery(q SASTQuery) TenantBackupQuery {
+	return TenantBackupQuery{
+		QueryID:            q.QueryID,
+		Level:              q.Level,
+		LevelID:            q.LevelID,
+		Language:           q.Language,
+		Group:              q.Group,
+		Name:               q.Name,
+		Path:               q.Path,
+		Severity:           q.Severity,
+		CweID:              q.CweID,
+		IsExecutable:       q.IsExecutable,
+		QueryDescriptionId: q.QueryDescriptionId,
+		Source:             q.Source,
+	}
+}
+
+func (r *TenantRestoreResult) fail(c *Cx1Client, object string, err error) {
+	c.config.Logger.Warnf("Failed to restore %v: %s", object, err)
+	r.Errors = append(r.Errors, fmt.Sprintf("%v: %s", object, err))
+}
+
+// roles created by a user rather than predefined
+func isCustomRole(r Role) bool {
+	return len(r.Attributes.Creator) > 0 && r.Attributes.Creator[0] != "" && !strings.EqualFold(r.Attributes.Creator[0], "Checkmarx")
+}
+
+// returns the group and all subgroups as a flat list, with paths set
+func flattenGroups(groups []Group, parentPath string) []Group {
+	flat := []Group{}
+	for _, g := range groups {
+		if g.Path == "" {
+			g.Path = parentPath + "/" + g.Name
+		}
+		flat = append(flat, g)
+		flat = append(flat, flattenGroups(g.SubGroups, g.Path)...)
+	}
+	return flat
+}
+
+func findRoles(roles []Role, names []string, missing func(string)) []Role {
+	found := []Role{}
+	for _, name := range names {
+		match := false
+		for _, r := range roles {
+			if strings.EqualFold(r.Name, name) {
+				found = append(found, r)
+				match = true
+				break
+			}
+		}
+		if !match {
+			missing(name)
+		}
+	}
+	return found
+}
+
+func findResultState(states []ResultState, name string) *ResultState {
+	for i := range states {
+		if strings.EqualFold(states[i].Name, name) {
+			return &states[i]
+		}
+	}
+	return nil
+}
+
+func findProject(projects []Project, name string) *Project {
+	for i := range projects {
+		if projects[i].Name == name {
+			return &projects[i]
+		}
+	}
+	return nil
+}
+
+func findPreset(presets []Preset, name string) *Preset {
+	for i := range presets {
+		if presets[i].Name == name {
+			return &presets[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,220 @@
+package Cx1ClientGo
+
+import (
+	"fmt"
+	"time"
+
+	"golang.org/x/exp/slices"
+)
+
+// Returns each branch of the project with its scan count, last scan (any status) and latest completed scan per engine.
+// If withSummary is set, the result summary of the most recent completed scan on each branch is also included.
+func (c *Cx1Client) GetProjectBranchOverview(projectID string, withSummary bool) ([]ProjectBranch, error) {
+	branches := []ProjectBranch{}
+	names, err := c.GetProjectBranchesByID(projectID)
+	if err != nil {
+		return branches, fmt.Errorf("failed to get branches of project %v: %s", ShortenGUID(projectID), err)
+	}
+
+	for _, name := range names {
+		branch, err := c.getProjectBranch(projectID, name)
+		if err != nil {
+			return branches, err
+		}
+		branches = append(branches, branch)
+	}
+
+	if withSummary {
+		scanIDs := []string{}
+		for _, b := range branches {
+			if latest := b.latestCompletedScan(); latest != nil {
+				scanIDs = append(scanIDs, latest.ScanID)
+			}
+		}
+		if len(scanIDs) > 0 {
+			summaries, err := c.GetScanSummariesByID(scanIDs)
+			if err != nil {
+				return branches, fmt.Errorf("failed to get result summaries for project %v: %s", ShortenGUID(projectID), err)
+			}
+			for i := range branches {
+				latest := branches[i].latestCompletedScan()
+				if latest == nil {
+					continue
+				}
+				if index := slices.IndexFunc(summaries, func(s ScanSummary) bool { return s.ScanID == latest.ScanID }); index >= 0 {
+					branches[i].ResultSummary = &summaries[index]
+				}
+			}
+		}
+	}
+
+	return branches, nil
+}
+
+func (c *Cx1Client) getProjectBranch(projectID, name string) (ProjectBranch, error) {
+	branch := ProjectBranch{ProjectID: projectID, Name: name, LatestScans: map[string]Scan{}}
+
+	count, scans, err := c.GetScansFiltered(ScanFilter{
+		BaseFilter: BaseFilter{Limit: 1},
+		ProjectID:  projectID,
+		Branches:   []string{name},
+		Sort:       []string{ScanSortCreatedDescending},
+	})
+	if err != nil {
+		return branch, fmt.Errorf("failed to get scans of project %v branch %v: %s", ShortenGUID(projectID), name, err)
+	}
+	branch.ScanCount = count
+	if len(scans) > 0 {
+		branch.LastScan = &scans[0]
+	}
+
+	if count > 0 {
+		if branch.LatestScans, err = c.GetLatestCompletedScansByBranch(projectID, name, []string{}); err != nil {
+			return branch, err
+		}
+	}
+	return branch, nil
+}
+
+// the most recent of the branch's latest completed scans
+func (b ProjectBranch) latestCompletedScan() *Scan {
+	var latest *Scan
+	for _, s := range b.LatestScans {
+		if latest == nil || s.CreatedAt.After(latest.CreatedAt) {
+			scan := s
+			latest = &scan
+		}
+	}
+	return latest
+}
+
+// Returns the latest scan on the branch in which each engine completed, by engine.
+// Completed and partial scans are considered, using the per-engine status where available.
+// Scans are read most-recent first and only until every engine has been found. If engines is empty,
+// the engines of the most recent completed or partial scan on the branch are used.
+func (c *Cx1Client) GetLatestCompletedScansByBranch(projectID, branch string, engines []string) (map[string]Scan, error) {
+	latest := map[string]Scan{}
+	filter := ScanFilter{
+		BaseFilter: BaseFilter{Limit: c.config.Pagination.Scans},
+		ProjectID:  projectID,
+		Branches:   []string{branch},
+		Statuses:   []string{ScanStatus.Completed, ScanStatus.Partial},
+		Sort:       []string{ScanSortCreatedDescending},
+	}
+
+	for {
+		count, scans, err := c.GetScansFiltered(filter)
+		if err != nil {
+			return latest, fmt.Errorf("failed to get scans of project %v branch %v: %s", ShortenGUID(projectID), branch, err)
+		}
+		if len(engines) == 0 && len(scans) > 0 {
+			engines = scans[0].Engines
+		}
+
+		for _, scan := range scans {
+			for _, engine := range scan.Engines {
+				if _, ok := latest[engine]; !ok && slices.Contains(engines, engine) && scan.engineCompleted(engine) {
+					latest[engine] = scan
+				}
+			}
+			if len(latest) == len(engines) {
+				return latest, nil
+			}
+		}
+
+		if count <= filter.Offset+filter.Limit || len(scans) == 0 {
+			return latest, nil
+		}
+		filter.Bump()
+	}
+}
+
+// returns true if the engine completed in this scan, based on the status details where available
+func (s Scan) engineCompleted(engine string) bool {
+	if s.Status == ScanStatus.Completed {
+		return true
+	}
+	for _, d := range s.StatusDetails {
+		if d.Name == engine {
+			return d.Status == ScanStatus.Completed
+		}
+	}
+	return false
+}
+
+// Returns the latest completed scan per engine for every branch of the project, by branch and then engine
+func (c *Cx1Client) GetLatestCompletedScansPerBranch(projectID string, engines []string) (map[string]map[string]Scan, error) {
+	latest := map[string]map[string]Scan{}
+	branches, err := c.GetProjectBranchesByID(projectID)
+	if err != nil {
+		return latest, fmt.Errorf("failed to get branches of project %v: %s", ShortenGUID(projectID), err)
+	}
+	for _, branch := range branches {
+		if latest[branch], err = c.GetLatestCompletedScansByBranch(projectID, branch, engines); err != nil {
+			return latest, err
+		}
+	}
+	return latest, nil
+}
+
+// Returns the branches of the project which have not been scanned since the cutoff
+func (c *Cx1Client) GetStaleBranches(projectID string, cutoff time.Time) ([]ProjectBranch, error) {
+	stale := []ProjectBranch{}
+	names, err := c.GetProjectBranchesByID(projectID)
+	if err != nil {
+		return stale, fmt.Errorf("failed to get branches of project %v: %s", ShortenGUID(projectID), err)
+	}
+
+	for _, name := range names {
+		count, scans, err := c.GetScansFiltered(ScanFilter{
+			BaseFilter: BaseFilter{Limit: 1},
+			ProjectID:  projectID,
+			Branches:   []string{name},
+			Sort:       []string{ScanSortCreatedDescending},
+		})
+		if err != nil {
+			return stale, fmt.Errorf("failed to get scans of project %v branch %v: %s", ShortenGUID(projectID), name, err)
+		}
+		if len(scans) > 0 && scans[0].CreatedAt.After(cutoff) {
+			continue
+		}
+		branch := ProjectBranch{ProjectID: projectID, Name: name, ScanCount: count, LatestScans: map[string]Scan{}}
+		if len(scans) > 0 {
+			branch.LastScan = &scans[0]
+		}
+		stale = append(stale, branch)
+	}
+	return stale, nil
+}
+
+// Deletes the finished (completed, partial, failed or canceled) scans on the branch which are older than the given number of days,
+// returning the IDs of the deleted scans. This may delete every scan on the branch. There is no UNDO.
+func (c *Cx1Client) DeleteBranchScansOlderThan(projectID, branch string, days int) ([]string, error) {
+	deleted := []string{}
+	_, scans, err := c.GetAllScansFiltered(ScanFilter{
+		BaseFilter: BaseFilter{Limit: c.config.Pagination.Scans},
+		ProjectID:  projectID,
+		Branches:   []string{branch},
+		Statuses:   []string{ScanStatus.Completed, ScanStatus.Partial, ScanStatus.Failed, ScanStatus.Canceled},
+		ToDate:     time.Now().AddDate(0, 0, -days),
+	})
+	if err != nil {
+		return deleted, fmt.Errorf("failed to get scans of project %v branch %v: %s", ShortenGUID(projectID), branch, err)
+	}
+
+	for _, scan := range scans {
+		if err := c.DeleteScanByID(scan.ScanID); err != nil {
+			return deleted, err
+		}
+		deleted = append(deleted, scan.ScanID)
+	}
+	c.config.Logger.Debugf("Deleted %d scans older than %d days on project %v branch %v", len(deleted), days, ShortenGUID(projectID), branch)
+	return deleted, nil
+}
+
+func (b ProjectBranch) String() string {
+	if b.LastScan == nil {
+		return fmt.Sprintf("%v: no scans", b.Name)
+	}
+	return fmt.Sprintf("%v: %d scans, last scanned %v (%v)", b.Name, b.ScanCount, b.LastScan.CreatedAt.Format(time.DateOnly), b.LastScan.Status)
+}
@@ -0,0 +1,389 @@
+package Cx1ClientGo
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"golang.org/x/exp/slices"
+)
+
+// The keys which can be used to match CMDB records to projects
+var CMDBMatchKeys = struct {
+	RepoUrl     string
+	Name        string
+	NamePattern string
+	Tag         string
+}{
+	RepoUrl:     "repo-url",
+	Name:        "name",
+	NamePattern: "name-pattern",
+	Tag:         "tag",
+}
+
+// The keys which can be used to match CMDB records to applications
+var CMDBApplicationMatchKeys = struct {
+	Name string
+	ID   string
+	Tag  string
+}{
+	Name: "name", // the record's application is the application name, compared case-insensitively
+	ID:   "id",   // the record's application is the Cx1 application ID
+	Tag:  "tag",  // the application tag options.ApplicationTagKey holds the record ID
+}
+
+// Returns the default CSV columns: id, application, project, repoUrl, owner and criticality
+func DefaultCMDBColumns() CMDBColumns {
+	return CMDBColumns{
+		ID:          "id",
+		Application: "application",
+		Project:     "project",
+		RepoUrl:     "repoUrl",
+		Owner:       "owner",
+		Criticality: "criticality",
+		TagColumns:  []string{},
+	}
+}
+
+// Loads CMDB records from a JSON (.json) or CSV file, the columns are only used for CSV
+func LoadCMDBRecords(filename string, columns CMDBColumns) ([]CMDBRecord, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return []CMDBRecord{}, fmt.Errorf("failed to open CMDB export %v: %s", filename, err)
+	}
+	defer file.Close()
+
+	if strings.EqualFold(filepath.Ext(filename), ".json") {
+		return ReadCMDBRecordsJSON(file)
+	}
+	return ReadCMDBRecordsCSV(file, columns)
+}
+
+// Reads CMDB records from a JSON array of CMDBRecord
+func ReadCMDBRecordsJSON(r io.Reader) ([]CMDBRecord, error) {
+	records := []CMDBRecord{}
+	if err := json.NewDecoder(r).Decode(&records); err != nil {
+		return records, fmt.Errorf("failed to parse CMDB records: %s", err)
+	}
+	return records, nil
+}
+
+// Reads CMDB records from CSV with a header row. Columns which are not named in the header are left empty.
+func ReadCMDBRecordsCSV(r io.Reader, columns CMDBColumns) ([]CMDBRecord, error) {
+	records := []CMDBRecord{}
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return records, fmt.Errorf("failed to read CMDB header: %s", err)
+	}
+	index := map[string]int{}
+	for i, name := range header {
+		index[strings.TrimSpace(name)] = i
+	}
+	field := func(row []string, column string) string {
+		if i, ok := index[column]; ok && column != "" && i < len(row) {
+			return strings.TrimSpace(row[i])
+		}
+		return ""
+	}
+
+	for line := 2; ; line++ {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return records, fmt.Errorf("failed to read CMDB line %d: %s", line, err)
+		}
+
+		record := CMDBRecord{
+			ID:          field(row, columns.ID),
+			Application: field(row, columns.Application),
+			Project:     field(row, columns.Project),
+			RepoUrl:     field(row, columns.RepoUrl),
+			Owner:       field(row, columns.Owner),
+			Tags:        map[string]string{},
+		}
+		if value := field(row, columns.Criticality); value != "" {
+			criticality, err := strconv.ParseUint(value, 10, 32)
+			if err != nil {
+				return records, fmt.Errorf("invalid criticality %v on CMDB line %d: %s", value, line, err)
+			}
+			c := uint(criticality)
+			record.Criticality = &c
+		}
+		for _, column := range columns.TagColumns {
+			if value := field(row, column); value != "" {
+				record.Tags[column] = value
+			}
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// Matches the CMDB records to the applications and projects in the tenant, see ReconcileCMDBRecords
+func (c *Cx1Client) ReconcileCMDB(records []CMDBRecord, options CMDBReconcileOptions) (CMDBReconciliation, error) {
+	applications, err := c.GetAllApplications()
+	if err != nil {
+		return CMDBReconciliation{}, fmt.Errorf("failed to get applications: %s", err)
+	}
+	projects, err := c.GetAllProjects()
+	if err != nil {
+		return CMDBReconciliation{}, fmt.Errorf("failed to get projects: %s", err)
+	}
+	return ReconcileCMDBRecords(records, applications, projects, options)
+}
+
+// Matches the CMDB records to applications by options.ApplicationMatchBy and to projects by options.MatchBy, reporting orphans on both sides
+// and applications (and optionally projects) whose criticality, owner or tags differ from the CMDB.
+// Projects are only matched and reported as orphans when options.MatchBy is set.
+func ReconcileCMDBRecords(records []CMDBRecord, applications []Application, projects []Project, options CMDBReconcileOptions) (CMDBReconciliation, error) {
+	reconciliation := CMDBReconciliation{
+		Matches:            []CMDBMatch{},
+		OrphanRecords:      []CMDBRecord{},
+		OrphanApplications: []Application{},
+		OrphanProjects:     []Project{},
+		Mismatches:         []CMDBMismatch{},
+	}
+	if options.MatchBy == CMDBMatchKeys.Tag && options.ProjectTagKey == "" {
+		return reconciliation, fmt.Errorf("a project tag key is required to match by tag")
+	}
+	if options.ApplicationMatchBy == "" {
+		options.ApplicationMatchBy = CMDBApplicationMatchKeys.Name
+	}
+	if options.ApplicationMatchBy == CMDBApplicationMatchKeys.Tag && options.ApplicationTagKey == "" {
+		return reconciliation, fmt.Errorf("an application tag key is required to match applications by tag")
+	}
+	if options.OwnerTagKey == "" {
+		options.OwnerTagKey = "owner"
+	}
+
+	matchedApplications := map[string]bool{}
+	matchedProjects := map[string]bool{}
+	for _, record := range records {
+		match := CMDBMatch{RecordID: record.ID, ProjectIDs: []string{}}
+
+		var application *Application
+		for i := range applications {
+			matched, err := record.matchesApplication(applications[i], options)
+			if err != nil {
+				return reconciliation, err
+			}
+			if matched {
+				application = &applications[i]
+				match.ApplicationID = application.ApplicationID
+				break
+			}
+		}
+
+		for _, p := range projects {
+			matched, err := record.matchesProject(p, options)
+			if err != nil {
+				return reconciliation, err
+			}
+			if matched {
+				match.ProjectIDs = append(match.ProjectIDs, p.ProjectID)
+			}
+		}
+
+		if application == nil && len(match.ProjectIDs) == 0 {
+			reconciliation.OrphanRecords = append(reconciliation.OrphanRecords, record)
+			continue
+		}
+		reconciliation.Matches = append(reconciliation.Matches, match)
+
+		// several records may describe the same application, only the first is compared
+		if application != nil && !matchedApplications[application.ApplicationID] {
+			matchedApplications[application.ApplicationID] = true
+			mismatch := record.compare(TenantResourceTypes.Application, application.ApplicationID, application.Name, application.Criticality, application.Tags, options)
+			if len(mismatch.Fields) > 0 {
+				reconciliation.Mismatches = append(reconciliation.Mismatches, mismatch)
+			}
+		}
+
+		for _, projectID := range match.ProjectIDs {
+			matchedProjects[projectID] = true
+			p := projects[slices.IndexFunc(projects, func(p Project) bool { return p.ProjectID == projectID })]
+
+			mismatch := CMDBMismatch{RecordID: record.ID, ResourceType: TenantResourceTypes.Project, ID: p.ProjectID, Name: p.Name, Fields: []TenantFieldChange{}}
+			if options.ApplyToProjects {
+				mismatch = record.compare(TenantResourceTypes.Project, p.ProjectID, p.Name, p.Criticality, p.Tags, options)
+			}
+			if options.AssignToApplication && application != nil && (p.Applications == nil || !p.IsInApplicationID(application.ApplicationID)) {
+				mismatch.AssignApplicationID = application.ApplicationID
+				mismatch.Fields = append(mismatch.Fields, TenantFieldChange{Field: "application", Old: "", New: application.Name})
+			}
+			if len(mismatch.Fields) > 0 {
+				reconciliation.Mismatches = append(reconciliation.Mismatches, mismatch)
+			}
+		}
+	}
+
+	for _, a := range applications {
+		if !matchedApplications[a.ApplicationID] {
+			reconciliation.OrphanApplications = append(reconciliation.OrphanApplications, a)
+		}
+	}
+	if options.MatchBy != "" {
+		for _, p := range projects {
+			if !matchedProjects[p.ProjectID] {
+				reconciliation.OrphanProjects = append(reconciliation.OrphanProjects, p)
+			}
+		}
+	}
+
+	return reconciliation, nil
+}
+
+func (r CMDBRecord) matchesApplication(application Application, options CMDBReconcileOptions) (bool, error) {
+	switch options.ApplicationMatchBy {
+	case CMDBApplicationMatchKeys.Name:
+		return r.Application != "" && strings.EqualFold(application.Name, r.Application), nil
+	case CMDBApplicationMatchKeys.ID:
+		return r.Application != "" && application.ApplicationID == r.Application, nil
+	case CMDBApplicationMatchKeys.Tag:
+		value, ok := application.Tags[options.ApplicationTagKey]
+		return ok && r.ID != "" && value == r.ID, nil
+	}
+	return false, fmt.Errorf("unknown CMDB application match key %v", options.ApplicationMatchBy)
+}
+
+func (r CMDBRecord) matchesProject(project Project, options CMDBReconcileOptions) (bool, error) {
+	switch options.MatchBy {
+	case "":
+		return false, nil
+	case CMDBMatchKeys.RepoUrl:
+		return r.RepoUrl != "" && normalizeRepoUrl(r.RepoUrl) == normalizeRepoUrl(project.RepoUrl), nil
+	case CMDBMatchKeys.Name:
+		return r.Project != "" && r.Project == project.Name, nil
+	case CMDBMatchKeys.NamePattern:
+		if r.Project == "" {
+			return false, nil
+		}
+		matched, err := path.Match(r.Project, project.Name)
+		if err != nil {
+			return false, fmt.Errorf("invalid project name pattern %v in CMDB record %v: %s", r.Project, r.ID, err)
+		}
+		return matched, nil
+	case CMDBMatchKeys.Tag:
+		value, ok := project.Tags[options.ProjectTagKey]
+		return ok && value == r.ID, nil
+	}
+	return false, fmt.Errorf("unknown CMDB match key %v", options.MatchBy)
+}
+
+// compares the record's criticality, owner and tags against an application or project
+func (r CMDBRecord) compare(resourceType, id, name string, criticality uint, tags map[string]string, options CMDBReconcileOptions) CMDBMismatch {
+	mismatch := CMDBMismatch{
+		RecordID:     r.ID,
+		ResourceType: resourceType,
+		ID:           id,
+		Name:         name,
+		Fields:       []TenantFieldChange{},
+	}
+
+	if r.Criticality != nil && *r.Criticality != criticality {
+		mismatch.Fields = diffField(mismatch.Fields, "criticality", uintString(true, criticality), uintString(true, *r.Criticality))
+		mismatch.Criticality = r.Criticality
+	}
+
+	desired := map[string]string{}
+	for k, v := range r.Tags {
+		desired[k] = v
+	}
+	if r.Owner != "" {
+		desired[options.OwnerTagKey] = r.Owner
+	}
+
+	merged := map[string]string{}
+	for k, v := range tags {
+		merged[k] = v
+	}
+	changed := false
+	for _, k := range sortedKeys(desired) {
+		if current, ok := tags[k]; !ok || current != desired[k] {
+			mismatch.Fields = append(mismatch.Fields, TenantFieldChange{Field: "tag " + k, Old: current, New: desired[k]})
+			merged[k] = desired[k]
+			changed = true
+		}
+	}
+	if changed {
+		mismatch.Tags = &merged
+	}
+
+	return mismatch
+}
+
+// reduces a repository URL to host/path for comparison, eg: git@github.com:org/repo.git and https://user@github.com/org/repo/ are the same
+func normalizeRepoUrl(repoUrl string) string {
+	url := strings.ToLower(strings.TrimSpace(repoUrl))
+	if i := strings.Index(url, "://"); i >= 0 {
+		url = url[i+3:]
+	} else if strings.Contains(url, "@") && strings.Contains(url, ":") {
+		url = strings.Replace(url, ":", "/", 1)
+	}
+	if i := strings.Index(url, "@"); i >= 0 {
+		url = url[i+1:]
+	}
+	url = strings.TrimSuffix(url, "/")
+	return strings.TrimSuffix(url, ".git")
+}
+
+// Applies the mismatches from a reconciliation: patches the criticality and tags of applications and projects,
+// and assigns projects to their CMDB application. All mismatches are attempted, and the error lists those which failed.
+func (c *Cx1Client) ApplyCMDBReconciliation(reconciliation CMDBReconciliation) error {
+	failures := []string{}
+	for _, m := range reconciliation.Mismatches {
+		var err error
+		if m.Criticality != nil || m.Tags != nil {
+			if m.ResourceType == TenantResourceTypes.Application {
+				err = c.PatchApplicationByID(m.ID, ApplicationPatch{Criticality: m.Criticality, Tags: m.Tags})
+			} else {
+				err = c.PatchProjectByID(m.ID, ProjectPatch{Criticality: m.Criticality, Tags: m.Tags})
+			}
+		}
+		if err == nil && m.AssignApplicationID != "" {
+			err = c.AssignProjectToApplicationsByIDs(m.ID, []string{m.AssignApplicationID})
+		}
+		if err != nil {
+			c.config.Logger.Warnf("Failed to apply CMDB record %v to %v %v: %s", m.RecordID, m.ResourceType, m.Name, err)
+			failures = append(failures, fmt.Sprintf("%v %v: %s", m.ResourceType, m.Name, err))
+		}
+	}
+
+	if len(failures) > 0 {
+		return fmt.Errorf("failed to apply %d CMDB changes: %v", len(failures), strings.Join(failures, "; "))
+	}
+	return nil
+}
+
+// Returns the reconciliation in a human-readable form
+func (r CMDBReconciliation) String() string {
+	lines := []string{}
+	for _, m := range r.Mismatches {
+		lines = append(lines, fmt.Sprintf("~ %v %v (record %v)", m.ResourceType, m.Name, m.RecordID))
+		for _, f := range m.Fields {
+			lines = append(lines, fmt.Sprintf("    %v: %q -> %q", f.Field, f.Old, f.New))
+		}
+	}
+	for _, o := range r.OrphanRecords {
+		lines = append(lines, fmt.Sprintf("? record %v (application %v) has no match in Cx1", o.ID, o.Application))
+	}
+	for _, a := range r.OrphanApplications {
+		lines = append(lines, fmt.Sprintf("? application %v is not in the CMDB", a.String()))
+	}
+	for _, p := range r.OrphanProjects {
+		lines = append(lines, fmt.Sprintf("? project %v is not in the CMDB", p.String()))
+	}
+	lines = append(lines, fmt.Sprintf("CMDB: %d matched, %d mismatched, %d orphan records, %d orphan applications, %d orphan projects",
+		len(r.Matches), len(r.Mismatches), len(r.OrphanRecords), len(r.OrphanApplications), len(r.OrphanProjects)))
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,279 @@
+package Cx1ClientGo
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"golang.org/x/exp/slices"
+)
+
+// The value types of configuration settings, as reported in ConfigurationSetting.ValueType
+var ConfigurationValueTypes = struct {
+	String    string
+	Bool      string
+	Number    string
+	List      string
+	MultiList string
+}{
+	String:    "String",
+	Bool:      "Bool",
+	Number:    "Number",
+	List:      "List",
+	MultiList: "MultiList",
+}
+
+// Returns the built-in catalogue of the settings in ConfigurationSettings. It is not complete, so settings with other keys are not rejected.
+// Known presets are only listed after RefreshConfigurationCatalogue.
+func DefaultConfigurationCatalogue() *ConfigurationCatalogue {
+	catalogue := &ConfigurationCatalogue{Settings: map[string]ConfigurationSettingDefinition{}}
+	add := func(key, valueType string, allowed []string, defaultValue string) {
+		parts := strings.Split(key, ".")
+		catalogue.Settings[key] = ConfigurationSettingDefinition{
+			Key:           key,
+			Name:          parts[len(parts)-1],
+			Category:      parts[len(parts)-2],
+			ValueType:     valueType,
+			AllowedValues: allowed,
+			Default:       defaultValue,
+		}
+	}
+
+	types := ConfigurationValueTypes
+	add(ConfigurationSettings.SAST.BaseBranch, types.String, nil, "")
+	add(ConfigurationSettings.SAST.EngineVerbose, types.Bool, nil, "false")
+	add(ConfigurationSettings.SAST.FastScanMode, types.Bool, nil, "")
+	add(ConfigurationSettings.SAST.Filter, types.String, nil, "")
+	add(ConfigurationSettings.SAST.Incremental, types.Bool, nil, "false")
+	add(ConfigurationSettings.SAST.LanguageMode, types.List, []string{"primary", "multi"}, "multi")
+	add(ConfigurationSettings.SAST.LightQueries, types.Bool, nil, "")
+	add(ConfigurationSettings.SAST.PresetName, types.List, nil, "")
+	add(ConfigurationSettings.SAST.RecommendedExclusions, types.Bool, nil, "")
+	add(ConfigurationSettings.SAST.ScanMode, types.String, nil, "")
+	add(ConfigurationSettings.IAC.Filter, types.String, nil, "")
+	add(ConfigurationSettings.IAC.Platforms, types.MultiList, nil, "")
+	add(ConfigurationSettings.IAC.PresetID, types.String, nil, "")
+	add(ConfigurationSettings.SCA.ExploitablePath, types.Bool, nil, "false")
+	add(ConfigurationSettings.SCA.Filter, types.String, nil, "")
+	add(ConfigurationSettings.SCA.SBOM, types.Bool, nil, "false")
+	return catalogue
+}
+
+// Returns the catalogue used to validate configuration updates: the one loaded by RefreshConfigurationCatalogue, or the built-in catalogue
+func (c *Cx1Client) GetConfigurationCatalogue() *ConfigurationCatalogue {
+	c.catalogueLock.Lock()
+	defer c.catalogueLock.Unlock()
+	if c.catalogue == nil {
+		return DefaultConfigurationCatalogue()
+	}
+	return c.catalogue
+}
+
+// Loads the catalogue of all settings from the tenant configuration, including their value types, allowed values and tenant defaults.
+// SAST preset names are filled from the tenant's presets as known values rather than allowed values, since presets created after the refresh are also valid.
+// The catalogue is kept by the client and used to validate later configuration updates.
+func (c *Cx1Client) RefreshConfigurationCatalogue() (*ConfigurationCatalogue, error) {
+	settings, err := c.GetTenantConfiguration()
+	if err != nil {
+		return c.GetConfigurationCatalogue(), fmt.Errorf("failed to get tenant configuration: %s", err)
+	}
+
+	catalogue := &ConfigurationCatalogue{Complete: true, Settings: map[string]ConfigurationSettingDefinition{}}
+	for _, s := range settings {
+		definition := ConfigurationSettingDefinition{
+			Key:       s.Key,
+			Name:      s.Name,
+			Category:  s.Category,
+			ValueType: s.ValueType,
+			Default:   s.Value,
+		}
+		if isListValueType(s.ValueType) && s.ValueTypeParams != "" {
+			definition.AllowedValues = splitConfigurationValues(s.ValueTypeParams)
+		}
+		catalogue.Settings[s.Key] = definition
+	}
+
+	if preset, ok := catalogue.Settings[ConfigurationSettings.SAST.PresetName]; ok {
+		presets, err := c.GetAllSASTPresets()
+		if err != nil {
+			return c.GetConfigurationCatalogue(), fmt.Errorf("failed to get SAST presets: %s", err)
+		}
+		preset.KnownValues = preset.AllowedValues
+		preset.AllowedValues = nil
+		for _, p := range presets {
+			if !slices.Contains(preset.KnownValues, p.Name) {
+				preset.KnownValues = append(preset.KnownValues, p.Name)
+			}
+		}
+		catalogue.Settings[preset.Key] = preset
+	}
+
+	c.config.Logger.Debugf("Loaded configuration catalogue with %d settings", len(catalogue.Settings))
+	c.catalogueLock.Lock()
+	c.catalogue = catalogue
+	c.catalogueLock.Unlock()
+	return catalogue, nil
+}
+
+// Returns the definition of the setting by key or name, or nil if it is not in the catalogue.
+// Names shared by several settings (eg: "filter") are ambiguous and also return nil, use the full key instead.
+func (cc *ConfigurationCatalogue) Get(key string) *ConfigurationSettingDefinition {
+	definition, _ := cc.lookup(key)
+	return definition
+}
+
+// returns an error if the key is a name shared by several settings
+func (cc *ConfigurationCatalogue) lookup(key string) (*ConfigurationSettingDefinition, error) {
+	if d, ok := cc.Settings[key]; ok {
+		return &d, nil
+	}
+	matches := []string{}
+	for _, k := range sortedKeys(cc.Settings) {
+		if cc.Settings[k].Name == key {
+			matches = append(matches, k)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return nil, nil
+	case 1:
+		d := cc.Settings[matches[0]]
+		return &d, nil
+	}
+	return nil, fmt.Errorf("configuration setting name %v is ambiguous, use one of the keys: %v", key, strings.Join(matches, ", "))
+}
+
+// Checks that the value is valid for the setting. Empty values are always valid, as they unset the setting.
+// Unknown keys are only rejected by a complete catalogue. Errors suggest the closest key or value where one is similar.
+func (cc *ConfigurationCatalogue) Validate(key, value string) error {
+	definition, err := cc.lookup(key)
+	if err != nil {
+		return err
+	}
+	if definition == nil {
+		if !cc.Complete {
+			return nil
+		}
+		if suggestion := closestMatch(key, sortedKeys(cc.Settings)); suggestion != "" {
+			return fmt.Errorf("unknown configuration setting %v, did you mean %v?", key, suggestion)
+		}
+		return fmt.Errorf("unknown configuration setting %v", key)
+	}
+	if value == "" {
+		return nil
+	}
+
+	switch {
+	case strings.EqualFold(definition.ValueType, ConfigurationValueTypes.Bool):
+		if _, err := strconv.ParseBool(value); err != nil {
+			return fmt.Errorf("invalid value %v for %v: expected true or false", value, definition.Key)
+		}
+	case strings.EqualFold(definition.ValueType, ConfigurationValueTypes.Number):
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return fmt.Errorf("invalid value %v for %v: expected a number", value, definition.Key)
+		}
+	case isListValueType(definition.ValueType) && len(definition.AllowedValues) > 0:
+		values := []string{value}
+		if strings.EqualFold(definition.ValueType, ConfigurationValueTypes.MultiList) {
+			values = splitConfigurationValues(value)
+		}
+		for _, v := range values {
+			if slices.Contains(definition.AllowedValues, v) {
+				continue
+			}
+			if suggestion := closestMatch(v, definition.AllowedValues); suggestion != "" {
+				return fmt.Errorf("invalid value %v for %v, did you mean %v?", v, definition.Key, suggestion)
+			}
+			return fmt.Errorf("invalid value %v for %v, allowed values are: %v", v, definition.Key, strings.Join(definition.AllowedValues, ", "))
+		}
+	}
+	return nil
+}
+
+// Validates each setting, returning an error listing every invalid setting
+func (cc *ConfigurationCatalogue) ValidateSettings(settings []ConfigurationSetting) error {
+	errs := []string{}
+	for _, s := range settings {
+		if err := cc.Validate(s.Key, s.Value); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid configuration: %v", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// Returns a warning for each setting whose value is not one of the setting's known values, suggesting the closest known value.
+// These values are valid but may be mistyped, eg: a preset name which did not exist when the catalogue was loaded.
+func (cc *ConfigurationCatalogue) Warnings(settings []ConfigurationSetting) []string {
+	warnings := []string{}
+	for _, s := range settings {
+		definition, _ := cc.lookup(s.Key)
+		if definition == nil || s.Value == "" || len(definition.KnownValues) == 0 || slices.Contains(definition.KnownValues, s.Value) {
+			continue
+		}
+		if suggestion := closestMatch(s.Value, definition.KnownValues); suggestion != "" {
+			warnings = append(warnings, fmt.Sprintf("unknown value %v for %v, did you mean %v?", s.Value, definition.Key, suggestion))
+		} else {
+			warnings = append(warnings, fmt.Sprintf("unknown value %v for %v", s.Value, definition.Key))
+		}
+	}
+	return warnings
+}
+
+func isListValueType(valueType string) bool {
+	return strings.EqualFold(valueType, ConfigurationValueTypes.List) || strings.EqualFold(valueType, ConfigurationValueTypes.MultiList)
+}
+
+func splitConfigurationValues(values string) []string {
+	list := []string{}
+	for _, v := range strings.Split(values, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			list = append(list, v)
+		}
+	}
+	return list
+}
+
+// returns the option closest to value (case-insensitive match, or an edit distance of at most 2 or a third of its length), or "" if none are close
+func closestMatch(value string, options []string) string {
+	best, bestDistance := "", max(2, len(value)/3)+1
+	for _, o := range options {
+		if strings.EqualFold(o, value) {
+			return o
+		}
+		if d := editDistance(strings.ToLower(value), strings.ToLower(o)); d < bestDistance {
+			best, bestDistance = o, d
+		}
+	}
+	return best
+}
+
+// Levenshtein distance
+func editDistance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
+
+func (d ConfigurationSettingDefinition) String() string {
+	if len(d.AllowedValues) > 0 {
+		return fmt.Sprintf("%v (%v: %v) default %v", d.Key, d.ValueType, strings.Join(d.AllowedValues, ", "), configurationValueString(d.Default))
+	}
+	return fmt.Sprintf("%v (%v) default %v", d.Key, d.ValueType, configurationValueString(d.Default))
+}
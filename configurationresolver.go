@@ -0,0 +1,266 @@
+package Cx1ClientGo
+
+import (
+	"fmt"
+	"strings"
+)
+
+// The levels at which a configuration setting can be set, from the highest to the lowest
+var ConfigurationLevels = struct {
+	Tenant      string
+	Application string
+	Project     string
+	Scan        string
+}{
+	Tenant:      "Tenant",
+	Application: "Application",
+	Project:     "Project",
+	Scan:        "Scan",
+}
+
+// Resolves the effective configuration of a project, with the chain of levels (tenant -> application -> project) each setting was set at
+func (c *Cx1Client) ResolveProjectConfiguration(projectID string) (ConfigurationResolution, error) {
+	return c.resolveConfiguration(projectID, "")
+}
+
+// Resolves the configuration used by a scan, with the chain of levels (tenant -> application -> project -> scan) each setting was set at
+func (c *Cx1Client) ResolveScanConfiguration(projectID, scanID string) (ConfigurationResolution, error) {
+	return c.resolveConfiguration(projectID, scanID)
+}
+
+func (c *Cx1Client) resolveConfiguration(projectID, scanID string) (ConfigurationResolution, error) {
+	resolution := ConfigurationResolution{
+		ProjectID: projectID,
+		ScanID:    scanID,
+		Settings:  []ResolvedConfigurationSetting{},
+	}
+
+	tenant, err := c.GetTenantConfiguration()
+	if err != nil {
+		return resolution, fmt.Errorf("failed to get tenant configuration: %s", err)
+	}
+	project, err := c.GetProjectConfigurationByID(projectID)
+	if err != nil {
+		return resolution, fmt.Errorf("failed to get configuration of project %v: %s", ShortenGUID(projectID), err)
+	}
+	levels := [][]ConfigurationSetting{tenant, project}
+
+	if scanID != "" {
+		scan, err := c.GetScanConfigurationByID(projectID, scanID)
+		if err != nil {
+			return resolution, fmt.Errorf("failed to get configuration of scan %v: %s", ShortenGUID(scanID), err)
+		}
+		levels = append(levels, scan)
+	}
+
+	resolution.Settings = ResolveConfiguration(levels...)
+	return resolution, nil
+}
+
+// Resolves configuration settings lists from the highest level to the lowest, for example tenant, project and scan.
+// Each list contributes a step to the chain of a setting when its OriginLevel has not already been seen for that key.
+// Once a level sets AllowOverride=false, differing values at lower levels are flagged as blocked and do not take effect.
+func ResolveConfiguration(levels ...[]ConfigurationSetting) []ResolvedConfigurationSetting {
+	resolved := []ResolvedConfigurationSetting{}
+	index := map[string]int{}
+
+	// the most specific list determines the order of the keys
+	for i := len(levels) - 1; i >= 0; i-- {
+		for _, s := range levels[i] {
+			if _, ok := index[s.Key]; ok {
+				continue
+			}
+			index[s.Key] = len(resolved)
+			resolved = append(resolved, ResolvedConfigurationSetting{
+				Key:      s.Key,
+				Name:     s.Name,
+				Category: s.Category,
+				Chain:    []ConfigurationOrigin{},
+			})
+		}
+	}
+
+	for _, level := range levels {
+		for _, s := range level {
+			r := &resolved[index[s.Key]]
+			if len(r.Chain) > 0 && r.Chain[len(r.Chain)-1].Level == s.OriginLevel {
+				continue
+			}
+			r.Chain = append(r.Chain, ConfigurationOrigin{
+				Level:         s.OriginLevel,
+				Value:         s.Value,
+				AllowOverride: s.AllowOverride,
+			})
+		}
+	}
+
+	for i := range resolved {
+		r := &resolved[i]
+		locked := -1
+		for j := range r.Chain {
+			if locked >= 0 {
+				r.Chain[j].Blocked = r.Chain[j].Value != r.Chain[locked].Value
+			} else if !r.Chain[j].AllowOverride {
+				locked = j
+				r.LockedAt = r.Chain[j].Level
+			}
+			if !r.Chain[j].Blocked {
+				r.Value = r.Chain[j].Value
+				r.OriginLevel = r.Chain[j].Level
+			}
+		}
+	}
+
+	return resolved
+}
+
+// Returns the resolved setting by key or name, or nil if it is not set
+func (r ConfigurationResolution) GetSetting(key string) *ResolvedConfigurationSetting {
+	for i := range r.Settings {
+		if r.Settings[i].Key == key || r.Settings[i].Name == key {
+			return &r.Settings[i]
+		}
+	}
+	return nil
+}
+
+// Returns only the settings which have a blocked override somewhere in their chain
+func (r ConfigurationResolution) GetBlockedSettings() []ResolvedConfigurationSetting {
+	blocked := []ResolvedConfigurationSetting{}
+	for _, s := range r.Settings {
+		if s.IsBlocked() {
+			blocked = append(blocked, s)
+		}
+	}
+	return blocked
+}
+
+// Returns true if a lower level attempted to override a locked value
+func (s ResolvedConfigurationSetting) IsBlocked() bool {
+	for _, o := range s.Chain {
+		if o.Blocked {
+			return true
+		}
+	}
+	return false
+}
+
+func (s ResolvedConfigurationSetting) String() string {
+	chain := []string{}
+	for _, o := range s.Chain {
+		entry := fmt.Sprintf("%v: %v", o.Level, configurationValueString(o.Value))
+		if !o.AllowOverride {
+			entry += " [locked]"
+		}
+		if o.Blocked {
+			entry += " [blocked]"
+		}
+		chain = append(chain, entry)
+	}
+	return fmt.Sprintf("%v = %v (%v)", s.Key, configurationValueString(s.Value), strings.Join(chain, " -> "))
+}
+
+// Compares the effective configuration of two projects, returning the settings with different values
+func (c *Cx1Client) DiffProjectConfiguration(projectID, otherProjectID string) ([]ConfigurationDifference, error) {
+	settings, err := c.GetProjectConfigurationByID(projectID)
+	if err != nil {
+		return []ConfigurationDifference{}, fmt.Errorf("failed to get configuration of project %v: %s", ShortenGUID(projectID), err)
+	}
+	other, err := c.GetProjectConfigurationByID(otherProjectID)
+	if err != nil {
+		return []ConfigurationDifference{}, fmt.Errorf("failed to get configuration of project %v: %s", ShortenGUID(otherProjectID), err)
+	}
+	return DiffConfiguration(settings, other), nil
+}
+
+// Compares two configuration settings lists, returning the keys which have different values or are only present in one list
+func DiffConfiguration(settings, other []ConfigurationSetting) []ConfigurationDifference {
+	diffs := diffConfigurationKeys(settings, other)
+	for _, o := range other {
+		if getConfigurationByKey(&settings, o.Key) == nil {
+			diffs = append(diffs, ConfigurationDifference{
+				Key:         o.Key,
+				Name:        o.Name,
+				Other:       o.Value,
+				OtherOrigin: o.OriginLevel,
+			})
+		}
+	}
+	return diffs
+}
+
+// compares the keys present in settings against other
+func diffConfigurationKeys(settings, other []ConfigurationSetting) []ConfigurationDifference {
+	diffs := []ConfigurationDifference{}
+	for _, s := range settings {
+		diff := ConfigurationDifference{
+			Key:         s.Key,
+			Name:        s.Name,
+			Value:       s.Value,
+			OriginLevel: s.OriginLevel,
+		}
+		if o := getConfigurationByKey(&other, s.Key); o != nil {
+			if o.Value == s.Value {
+				continue
+			}
+			diff.Other = o.Value
+			diff.OtherOrigin = o.OriginLevel
+		}
+		diffs = append(diffs, diff)
+	}
+	return diffs
+}
+
+// Compares the configuration of every project against the baseline, returning the projects which differ.
+// Only the keys in the baseline are compared, so a partial baseline (eg: only the preset) can be used.
+// In the returned differences, Value is the baseline value and Other is the project's value.
+func (c *Cx1Client) GetConfigurationDrift(baseline []ConfigurationSetting) ([]ProjectConfigurationDrift, error) {
+	drift := []ProjectConfigurationDrift{}
+	projects, err := c.GetAllProjects()
+	if err != nil {
+		return drift, fmt.Errorf("failed to get projects: %s", err)
+	}
+
+	for _, p := range projects {
+		settings, err := c.GetProjectConfigurationByID(p.ProjectID)
+		if err != nil {
+			return drift, fmt.Errorf("failed to get configuration of project %v: %s", p.String(), err)
+		}
+		if diffs := diffConfigurationKeys(baseline, settings); len(diffs) > 0 {
+			c.config.Logger.Tracef("Project %v has %d settings which differ from the baseline", p.String(), len(diffs))
+			drift = append(drift, ProjectConfigurationDrift{
+				ProjectID:   p.ProjectID,
+				ProjectName: p.Name,
+				Differences: diffs,
+			})
+		}
+	}
+	return drift, nil
+}
+
+// Compares the configuration of every project against the configuration of the baseline project
+func (c *Cx1Client) GetConfigurationDriftFromProject(baselineProjectID string) ([]ProjectConfigurationDrift, error) {
+	baseline, err := c.GetProjectConfigurationByID(baselineProjectID)
+	if err != nil {
+		return []ProjectConfigurationDrift{}, fmt.Errorf("failed to get configuration of project %v: %s", ShortenGUID(baselineProjectID), err)
+	}
+	drift, err := c.GetConfigurationDrift(baseline)
+	for i := range drift {
+		if drift[i].ProjectID == baselineProjectID {
+			drift = append(drift[:i], drift[i+1:]...)
+			break
+		}
+	}
+	return drift, err
+}
+
+func (d ConfigurationDifference) String() string {
+	return fmt.Sprintf("%v: %v (%v) != %v (%v)", d.Key, configurationValueString(d.Value), d.OriginLevel, configurationValueString(d.Other), d.OtherOrigin)
+}
+
+func configurationValueString(value string) string {
+	if value == "" {
+		return "[UNSET]"
+	}
+	return value
+}
@@ -104,6 +104,7 @@ func (s *ScanConfigurationSet) AddConfig(engine, key, value string) {
 }
 
 // To be used with full key names - shortcuts are defined in Cx1ClientGo.ConfigurationSettings
+// The value is validated against s.Catalogue, or the built-in catalogue if it is not set
 // eg to set a scan to incremental: s.SetKey( Cx1ClientGo.ConfigurationSettings.SAST.Incremental, "true" )
 // Consumed when starting a scan: cx1client.ScanProjectZipByID( projectId, repoUrl, branch, s.Configurations, tags )
 func (s *ScanConfigurationSet) SetKey(key, value string) error {
@@ -112,6 +113,14 @@ func (s *ScanConfigurationSet) SetKey(key, value string) error {
 		return fmt.Errorf("invalid configuration key - should have 4 parts eg: scan.config.sast.incremental")
 	}
 
+	catalogue := s.Catalogue
+	if catalogue == nil {
+		catalogue = DefaultConfigurationCatalogue()
+	}
+	if err := catalogue.Validate(key, value); err != nil {
+		return err
+	}
+
 	s.AddConfig(parts[2], parts[3], value)
 
 	return nil
@@ -4,6 +4,7 @@ import (
 	"flag"
 	"fmt"
 	"net/http"
+	"sync"
 )
 
 // Reads command-line flags to create a Cx1Client
@@ -105,7 +106,7 @@ func NewClientWithOptions(options Cx1ClientConfiguration) (*Cx1Client, error) {
 		return http.ErrUseLastResponse
 	}
 
-	cli := Cx1Client{config: options}
+	cli := Cx1Client{config: options, catalogueLock: &sync.Mutex{}, tokenLock: &sync.Mutex{}}
 	err := cli.InitializeClient(options.QuickStart)
 	return &cli, err
 }
@@ -0,0 +1,155 @@
+package Cx1ClientGo
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"strings"
+)
+
+// Fingerprints identify a finding independently of the SimilarityID assigned by Cx1, which changes when queries are overridden or code is moved.
+// They are built from the query identity, the normalized source and sink snippets, and the file path, and exclude line and column numbers
+// so that a finding keeps the same fingerprint when code is added above it.
+
+// Returns a fingerprint for the result using default options, see FingerprintWithOptions
+func (r ScanSASTResult) Fingerprint() string {
+	return r.FingerprintWithOptions(ResultFingerprintOptions{})
+}
+
+// SAST fingerprints use the language, query group and query name (rather than the query ID, which changes when a query is overridden)
+// and the file, method and element name of the source (first) and sink (last) nodes
+func (r ScanSASTResult) FingerprintWithOptions(options ResultFingerprintOptions) string {
+	parts := []string{"sast", r.Data.LanguageName, r.Data.Group, r.Data.QueryName}
+
+	if len(r.Data.Nodes) > 0 {
+		parts = append(parts, options.sastNode(r.Data.Nodes[0])...)
+		parts = append(parts, options.sastNode(r.Data.Nodes[len(r.Data.Nodes)-1])...)
+	}
+
+	return fingerprintHash(parts)
+}
+
+func (r ScanIACResult) Fingerprint() string {
+	return r.FingerprintWithOptions(ResultFingerprintOptions{})
+}
+
+// IAC fingerprints use the platform, query group and query name, the file and the issue type and expected value
+func (r ScanIACResult) FingerprintWithOptions(options ResultFingerprintOptions) string {
+	parts := []string{"iac", r.Data.Platform, r.Data.Group, r.Data.QueryName, options.path(r.Data.FileName), r.Data.IssueType, normalizeFingerprintText(r.Data.ExpectedValue)}
+	if !options.ExcludeSnippets {
+		parts = append(parts, snippetFingerprintText(r.Data.Snippet))
+	}
+	return fingerprintHash(parts)
+}
+
+func (r ScanSCAResult) Fingerprint() string {
+	return r.FingerprintWithOptions(ResultFingerprintOptions{})
+}
+
+// SCA fingerprints use the package manager, package name and vulnerability ID, and the manifest file if available.
+// The package version is excluded so that the fingerprint survives an upgrade which does not fix the vulnerability.
+func (r ScanSCAResult) FingerprintWithOptions(options ResultFingerprintOptions) string {
+	manager, name, _ := r.Data.ParsePackageIdentifier()
+	parts := []string{"sca", strings.ToLower(manager), name, r.scaVulnerabilityID(), options.path(r.SourceFileName)}
+	return fingerprintHash(parts)
+}
+
+func (r ScanSCAContainerResult) Fingerprint() string {
+	return r.FingerprintWithOptions(ResultFingerprintOptions{})
+}
+
+// SCA container fingerprints use the package name and vulnerability ID
+func (r ScanSCAContainerResult) FingerprintWithOptions(options ResultFingerprintOptions) string {
+	id := r.VulnerabilityDetails.CveName
+	if id == "" {
+		id = r.SimilarityID
+	}
+	parts := []string{"sca-container", r.Data.PackageName, id, options.path(r.SourceFileName)}
+	return fingerprintHash(parts)
+}
+
+func (r ScanContainersResult) Fingerprint() string {
+	return r.FingerprintWithOptions(ResultFingerprintOptions{})
+}
+
+// Container fingerprints use the image name, package name and vulnerability ID, and the image file (eg: Dockerfile).
+// Image tags and package versions are excluded.
+func (r ScanContainersResult) FingerprintWithOptions(options ResultFingerprintOptions) string {
+	id := r.VulnerabilityDetails.CveName
+	if id == "" {
+		id = r.SimilarityID
+	}
+	parts := []string{"containers", r.Data.ImageName, r.Data.PackageName, id, options.path(r.Data.ImageFilePath)}
+	return fingerprintHash(parts)
+}
+
+// Returns a map of fingerprint to the results of all types in the set with that fingerprint
+// Multiple results may share a fingerprint, eg: the same flow reported from different entry points in the same method
+func (s ScanResultSet) GetFingerprints(options ResultFingerprintOptions) map[string][]ScanResultBase {
+	fingerprints := make(map[string][]ScanResultBase)
+
+	for _, r := range s.SAST {
+		f := r.FingerprintWithOptions(options)
+		fingerprints[f] = append(fingerprints[f], r.ScanResultBase)
+	}
+	for _, r := range s.SCA {
+		f := r.FingerprintWithOptions(options)
+		fingerprints[f] = append(fingerprints[f], r.ScanResultBase)
+	}
+	for _, r := range s.SCAContainer {
+		f := r.FingerprintWithOptions(options)
+		fingerprints[f] = append(fingerprints[f], r.ScanResultBase)
+	}
+	for _, r := range s.IAC {
+		f := r.FingerprintWithOptions(options)
+		fingerprints[f] = append(fingerprints[f], r.ScanResultBase)
+	}
+	for _, r := range s.Containers {
+		f := r.FingerprintWithOptions(options)
+		fingerprints[f] = append(fingerprints[f], r.ScanResultBase)
+	}
+
+	return fingerprints
+}
+
+func (o ResultFingerprintOptions) sastNode(node ScanSASTResultNodes) []string {
+	parts := []string{o.path(node.FileName), node.Method, node.Name}
+	if !o.ExcludeSnippets {
+		parts = append(parts, snippetFingerprintText(node.Snippet))
+	}
+	return parts
+}
+
+// normalizes separators and removes the first matching prefix, which must end at a directory boundary: "/src" strips "/src/a.go" but not "/srcgen/a.go"
+func (o ResultFingerprintOptions) path(path string) string {
+	path = "/" + normalizeSourcePath(path)
+	for _, prefix := range o.StripPathPrefixes {
+		prefix = strings.TrimRight("/"+normalizeSourcePath(prefix), "/") + "/"
+		if prefix != "/" && strings.HasPrefix(path, prefix) {
+			path = "/" + strings.TrimLeft(strings.TrimPrefix(path, prefix), "/")
+			break
+		}
+	}
+	return path
+}
+
+// returns the normalized text of the result line in the snippet
+func snippetFingerprintText(snippet *ResultSourceSnippet) string {
+	if snippet == nil {
+		return ""
+	}
+	for _, l := range snippet.Lines {
+		if l.Number == snippet.Line {
+			return normalizeFingerprintText(l.Text)
+		}
+	}
+	return ""
+}
+
+// collapses whitespace so that formatting changes do not affect the fingerprint
+func normalizeFingerprintText(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
+
+func fingerprintHash(parts []string) string {
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(strings.Join(parts, "\x00"))))
+}
@@ -0,0 +1,144 @@
+package Cx1ClientGo
+
+import "testing"
+
+func fingerprintSnippet(line uint64, text string) *ResultSourceSnippet {
+	return &ResultSourceSnippet{
+		Line:      line,
+		StartLine: line - 1,
+		EndLine:   line + 1,
+		Lines: []ResultSourceLine{
+			{Number: line - 1, Text: "// before"},
+			{Number: line, Text: text},
+			{Number: line + 1, Text: "// after"},
+		},
+	}
+}
+
+func fingerprintSASTResult(file string, sourceLine, sinkLine uint64, sinkText string) ScanSASTResult {
+	var r ScanSASTResult
+	r.SimilarityID = "12345"
+	r.Data.QueryID = 1
+	r.Data.QueryName = "SQL_Injection"
+	r.Data.Group = "Java_High_Risk"
+	r.Data.LanguageName = "Java"
+	r.Data.Nodes = []ScanSASTResultNodes{
+		{FileName: file, Line: sourceLine, Column: 5, Method: "handle", Name: "getParameter", Snippet: fingerprintSnippet(sourceLine, `String id = request.getParameter("id");`)},
+		{FileName: file, Line: sinkLine, Column: 9, Method: "query", Name: "executeQuery", Snippet: fingerprintSnippet(sinkLine, sinkText)},
+	}
+	return r
+}
+
+func TestSASTFingerprint(t *testing.T) {
+	const sink = `stmt.executeQuery("SELECT * FROM users WHERE id=" + id);`
+	base := fingerprintSASTResult("/src/main/java/Users.java", 10, 20, sink)
+
+	tests := []struct {
+		name    string
+		result  ScanSASTResult
+		options ResultFingerprintOptions
+		same    bool
+	}{
+		{"identical", fingerprintSASTResult("/src/main/java/Users.java", 10, 20, sink), ResultFingerprintOptions{}, true},
+		{"line shift", fingerprintSASTResult("/src/main/java/Users.java", 14, 27, sink), ResultFingerprintOptions{}, true},
+		{"whitespace change", fingerprintSASTResult("/src/main/java/Users.java", 10, 20, "\t  stmt.executeQuery(\"SELECT * FROM users WHERE id=\"   +  id);  "), ResultFingerprintOptions{}, true},
+		{"windows separators", fingerprintSASTResult(`src\main\java\Users.java`, 10, 20, sink), ResultFingerprintOptions{}, true},
+		{"changed sink", fingerprintSASTResult("/src/main/java/Users.java", 10, 20, `stmt.executeQuery("SELECT * FROM orders WHERE id=" + id);`), ResultFingerprintOptions{}, false},
+		{"changed sink, snippets excluded", fingerprintSASTResult("/src/main/java/Users.java", 10, 20, `stmt.executeQuery("SELECT * FROM orders WHERE id=" + id);`), ResultFingerprintOptions{ExcludeSnippets: true}, true},
+		{"different file", fingerprintSASTResult("/src/main/java/Orders.java", 10, 20, sink), ResultFingerprintOptions{}, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			expected := base.FingerprintWithOptions(test.options)
+			if same := test.result.FingerprintWithOptions(test.options) == expected; same != test.same {
+				t.Errorf("expected same fingerprint: %v, got: %v", test.same, same)
+			}
+		})
+	}
+}
+
+func TestSASTFingerprintIgnoresSimilarityID(t *testing.T) {
+	a := fingerprintSASTResult("/src/Users.java", 10, 20, "db.query(id);")
+	b := fingerprintSASTResult("/src/Users.java", 10, 20, "db.query(id);")
+	b.SimilarityID = "-98765"
+	b.Data.QueryID = 2 // eg: the query was overridden
+	if a.Fingerprint() != b.Fingerprint() {
+		t.Errorf("fingerprint changed with the similarity and query IDs")
+	}
+}
+
+func TestFingerprintPathPrefix(t *testing.T) {
+	tests := []struct {
+		name     string
+		prefixes []string
+		path     string
+		expected string
+	}{
+		{"no prefix", nil, "/build/src/app.go", "/build/src/app.go"},
+		{"prefix", []string{"/build"}, "/build/src/app.go", "/src/app.go"},
+		{"prefix with trailing separator", []string{"/build/"}, "/build/src/app.go", "/src/app.go"},
+		{"prefix without leading separator", []string{"build"}, "build/src/app.go", "/src/app.go"},
+		{"windows prefix", []string{`C:\agent\work`}, `C:\agent\work\src\app.go`, "/src/app.go"},
+		{"partial directory name", []string{"/build"}, "/buildtools/app.go", "/buildtools/app.go"},
+		{"first matching prefix", []string{"/other", "/build", "/build/src"}, "/build/src/app.go", "/src/app.go"},
+		{"empty prefix", []string{""}, "/build/src/app.go", "/build/src/app.go"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			options := ResultFingerprintOptions{StripPathPrefixes: test.prefixes}
+			if path := options.path(test.path); path != test.expected {
+				t.Errorf("expected %v, got %v", test.expected, path)
+			}
+		})
+	}
+}
+
+func TestSASTFingerprintPathPrefix(t *testing.T) {
+	options := ResultFingerprintOptions{StripPathPrefixes: []string{"/checkout/repo-a", "/checkout/repo-b"}}
+	a := fingerprintSASTResult("/checkout/repo-a/src/Users.java", 10, 20, "db.query(id);")
+	b := fingerprintSASTResult("/checkout/repo-b/src/Users.java", 12, 22, "db.query(id);")
+	if a.FingerprintWithOptions(options) != b.FingerprintWithOptions(options) {
+		t.Errorf("fingerprints differ after stripping the path prefixes")
+	}
+	if a.Fingerprint() == b.Fingerprint() {
+		t.Errorf("fingerprints match without stripping the path prefixes")
+	}
+}
+
+func TestIACFingerprint(t *testing.T) {
+	result := func(file string, line int, text string) ScanIACResult {
+		var r ScanIACResult
+		r.Data.Platform = "Terraform"
+		r.Data.Group = "Encryption"
+		r.Data.QueryName = "S3 Bucket Without Encryption"
+		r.Data.FileName = file
+		r.Data.Line = line
+		r.Data.IssueType = "MissingAttribute"
+		r.Data.ExpectedValue = "'server_side_encryption_configuration' should be defined"
+		r.Data.Snippet = fingerprintSnippet(uint64(line), text)
+		return r
+	}
+	base := result("/infra/s3.tf", 5, `resource "aws_s3_bucket" "logs" {`).Fingerprint()
+
+	tests := []struct {
+		name   string
+		result ScanIACResult
+		same   bool
+	}{
+		{"line shift", result("/infra/s3.tf", 42, `resource "aws_s3_bucket" "logs" {`), true},
+		{"whitespace change", result("/infra/s3.tf", 5, "resource  \"aws_s3_bucket\"\t\"logs\"   {"), true},
+		{"removed whitespace", result("/infra/s3.tf", 5, `resource "aws_s3_bucket" "logs"{`), false},
+		{"indentation change", result("/infra/s3.tf", 5, `    resource "aws_s3_bucket" "logs" {`), true},
+		{"different resource", result("/infra/s3.tf", 5, `resource "aws_s3_bucket" "data" {`), false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if same := test.result.Fingerprint() == base; same != test.same {
+				t.Errorf("expected same fingerprint: %v, got: %v", test.same, same)
+			}
+		})
+	}
+}
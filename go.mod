@@ -7,3 +7,5 @@ require (
 	github.com/google/go-querystring v1.1.0
 	golang.org/x/exp v0.0.0-20250408133849-7e4ce0ab07d0
 )
+
+require gopkg.in/yaml.v3 v3.0.1
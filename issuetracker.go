@@ -0,0 +1,368 @@
+package Cx1ClientGo
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/exp/slices"
+)
+
+// How IssueSync groups results into issues. Groupings which do not apply to a result type fall back to QueryFile (SAST, IAC) or Package (SCA, containers)
+var IssueGroupings = struct {
+	QueryFile string // one issue per query per file
+	Package   string // one issue per vulnerable package
+	CVE       string // one issue per vulnerability ID, across packages
+	Finding   string // one issue per result, using the result fingerprint
+}{"QueryFile", "Package", "CVE", "Finding"}
+
+type issueGroup struct {
+	Key      string
+	Title    string
+	Severity string
+	Results  []string
+	Lines    []string
+}
+
+// Creates an IssueSync with default settings: results in Not Exploitable state are considered fixed
+// mapping may be nil, in which case a new empty mapping is used. The mapping should be saved after each Sync, see IssueSyncMapping.Save
+func NewIssueSync(tracker IssueTracker, mapping *IssueSyncMapping, groupBy string) *IssueSync {
+	if mapping == nil {
+		mapping = NewIssueSyncMapping()
+	}
+	return &IssueSync{
+		Tracker: tracker,
+		Mapping: mapping,
+		GroupBy: groupBy,
+		States:  []string{"NOT_EXPLOITABLE"},
+	}
+}
+
+// Convenience function: retrieves the results for a scan and syncs them to the issue tracker for the scan's project
+func (c *Cx1Client) SyncScanIssuesByID(sync *IssueSync, scanID string) (IssueSyncSummary, error) {
+	c.config.Logger.Debugf("Syncing issues for scan %v", scanID)
+	scan, err := c.GetScanByID(scanID)
+	if err != nil {
+		return IssueSyncSummary{}, err
+	}
+
+	results, err := c.GetAllScanResultsByID(scanID)
+	if err != nil {
+		return IssueSyncSummary{}, err
+	}
+
+	summary, err := sync.Sync(scan.ProjectID, scanID, results)
+	c.config.Logger.Debugf("Issue sync for scan %v: %v", scanID, summary.String())
+	return summary, err
+}
+
+// Syncs the results of a scan to the issue tracker:
+// new groups of results create issues, changed groups update them, closed issues are reopened when the results recur,
+// and open issues for the project which no longer have any results are closed.
+// Issues which are not yet in the mapping are looked up in the tracker by group key before being created.
+func (s *IssueSync) Sync(projectID, scanID string, results ScanResultSet) (IssueSyncSummary, error) {
+	var summary IssueSyncSummary
+	if s.Mapping.Issues == nil {
+		s.Mapping.Issues = make(map[string]IssueSyncRecord)
+	}
+
+	groups := s.groupResults(projectID, results)
+	keys := make([]string, 0, len(groups))
+	for key := range groups {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		group := groups[key]
+		issue := TrackerIssue{
+			Key:         key,
+			Title:       group.Title,
+			Description: group.description(projectID, scanID),
+			Severity:    group.Severity,
+			Labels:      append([]string{key}, s.Labels...),
+		}
+
+		record, ok := s.Mapping.Issues[key]
+		if !ok {
+			existing, err := s.Tracker.FindByKey(key)
+			if err != nil {
+				return summary, fmt.Errorf("failed to find issue for %v: %s", key, err)
+			}
+			if existing != nil {
+				record = IssueSyncRecord{IssueID: existing.ID, Closed: existing.Closed}
+				ok = true
+			}
+		}
+
+		if !ok {
+			id, err := s.Tracker.CreateIssue(issue)
+			if err != nil {
+				return summary, fmt.Errorf("failed to create issue for %v: %s", key, err)
+			}
+			record.IssueID = id
+			summary.Created = append(summary.Created, id)
+		} else {
+			issue.ID = record.IssueID
+			if record.Closed {
+				if err := s.Tracker.ReopenIssue(record.IssueID, fmt.Sprintf("Results detected again in scan %v", scanID)); err != nil {
+					return summary, fmt.Errorf("failed to reopen issue %v: %s", record.IssueID, err)
+				}
+				if err := s.Tracker.UpdateIssue(issue); err != nil {
+					return summary, fmt.Errorf("failed to update issue %v: %s", record.IssueID, err)
+				}
+				summary.Reopened = append(summary.Reopened, record.IssueID)
+			} else if !slices.Equal(record.Results, group.Results) || record.Title != group.Title {
+				if err := s.Tracker.UpdateIssue(issue); err != nil {
+					return summary, fmt.Errorf("failed to update issue %v: %s", record.IssueID, err)
+				}
+				summary.Updated = append(summary.Updated, record.IssueID)
+			} else {
+				summary.Unchanged = append(summary.Unchanged, record.IssueID)
+			}
+		}
+
+		record.ProjectID = projectID
+		record.Title = group.Title
+		record.Closed = false
+		record.LastScan = scanID
+		record.LastSeen = time.Now()
+		record.Results = group.Results
+		s.Mapping.Issues[key] = record
+	}
+
+	for key, record := range s.Mapping.Issues {
+		if record.ProjectID != projectID || record.Closed {
+			continue
+		}
+		if _, ok := groups[key]; ok {
+			continue
+		}
+
+		if err := s.Tracker.CloseIssue(record.IssueID, fmt.Sprintf("Results no longer detected in scan %v", scanID)); err != nil {
+			return summary, fmt.Errorf("failed to close issue %v: %s", record.IssueID, err)
+		}
+		record.Closed = true
+		record.LastScan = scanID
+		s.Mapping.Issues[key] = record
+		summary.Closed = append(summary.Closed, record.IssueID)
+	}
+
+	return summary, nil
+}
+
+func (s IssueSync) include(r ScanResultBase) bool {
+	if len(s.Severities) > 0 && !slices.ContainsFunc(s.Severities, func(sev string) bool { return strings.EqualFold(sev, r.Severity) }) {
+		return false
+	}
+	return !slices.ContainsFunc(s.States, func(state string) bool { return strings.EqualFold(state, r.State) })
+}
+
+// group keys are stored as tracker labels, so they are hashed to avoid spaces and length limits
+func (s IssueSync) groupKey(projectID string, parts ...string) string {
+	return "cx1-" + fingerprintHash(append([]string{projectID}, parts...))[:16]
+}
+
+func (s IssueSync) groupResults(projectID string, results ScanResultSet) map[string]*issueGroup {
+	groups := make(map[string]*issueGroup)
+	add := func(r ScanResultBase, key, title, line string) {
+		group, ok := groups[key]
+		if !ok {
+			group = &issueGroup{Key: key, Title: title}
+			groups[key] = group
+		}
+		if GetSeverityID(r.Severity) >= GetSeverityID(group.Severity) {
+			group.Severity = r.Severity
+		}
+		group.Results = append(group.Results, r.SimilarityID)
+		group.Lines = append(group.Lines, fmt.Sprintf("[%v, %v] %v", r.Severity, r.State, line))
+	}
+
+	for _, r := range results.SAST {
+		if !s.include(r.ScanResultBase) || len(r.Data.Nodes) == 0 {
+			continue
+		}
+		file := s.FingerprintOptions.path(r.Data.Nodes[len(r.Data.Nodes)-1].FileName)
+		if s.GroupBy == IssueGroupings.Finding {
+			add(r.ScanResultBase, s.groupKey(projectID, "finding", r.FingerprintWithOptions(s.FingerprintOptions)), fmt.Sprintf("[Cx1] %v in %v (%v)", r.Data.QueryName, file, r.Data.Nodes[0].Name), r.String())
+		} else {
+			add(r.ScanResultBase, s.groupKey(projectID, "sast", r.Data.LanguageName, r.Data.QueryName, file), fmt.Sprintf("[Cx1] %v in %v", r.Data.QueryName, file), r.String())
+		}
+	}
+
+	for _, r := range results.IAC {
+		if !s.include(r.ScanResultBase) {
+			continue
+		}
+		file := s.FingerprintOptions.path(r.Data.FileName)
+		if s.GroupBy == IssueGroupings.Finding {
+			add(r.ScanResultBase, s.groupKey(projectID, "finding", r.FingerprintWithOptions(s.FingerprintOptions)), fmt.Sprintf("[Cx1] %v in %v (%v)", r.Data.QueryName, file, r.Data.IssueType), r.String())
+		} else {
+			add(r.ScanResultBase, s.groupKey(projectID, "iac", r.Data.Platform, r.Data.QueryName, file), fmt.Sprintf("[Cx1] %v in %v", r.Data.QueryName, file), r.String())
+		}
+	}
+
+	for _, r := range results.SCA {
+		if !s.include(r.ScanResultBase) {
+			continue
+		}
+		manager, name, _ := r.Data.ParsePackageIdentifier()
+		switch s.GroupBy {
+		case IssueGroupings.Finding:
+			add(r.ScanResultBase, s.groupKey(projectID, "finding", r.FingerprintWithOptions(s.FingerprintOptions)), fmt.Sprintf("[Cx1] %v in package %v", r.scaVulnerabilityID(), name), r.String())
+		case IssueGroupings.CVE:
+			add(r.ScanResultBase, s.groupKey(projectID, "cve", r.scaVulnerabilityID()), fmt.Sprintf("[Cx1] %v", r.scaVulnerabilityID()), r.String())
+		default:
+			add(r.ScanResultBase, s.groupKey(projectID, "package", strings.ToLower(manager), name), fmt.Sprintf("[Cx1] Vulnerable package %v (%v)", name, manager), r.String())
+		}
+	}
+
+	for _, r := range results.SCAContainer {
+		if !s.include(r.ScanResultBase) {
+			continue
+		}
+		switch s.GroupBy {
+		case IssueGroupings.Finding:
+			add(r.ScanResultBase, s.groupKey(projectID, "finding", r.FingerprintWithOptions(s.FingerprintOptions)), fmt.Sprintf("[Cx1] %v in container package %v", r.VulnerabilityDetails.CveName, r.Data.PackageName), r.String())
+		case IssueGroupings.CVE:
+			if r.VulnerabilityDetails.CveName != "" {
+				add(r.ScanResultBase, s.groupKey(projectID, "cve", r.VulnerabilityDetails.CveName), fmt.Sprintf("[Cx1] %v", r.VulnerabilityDetails.CveName), r.String())
+				break
+			}
+			fallthrough
+		default:
+			add(r.ScanResultBase, s.groupKey(projectID, "package", "container", r.Data.PackageName), fmt.Sprintf("[Cx1] Vulnerable container package %v", r.Data.PackageName), r.String())
+		}
+	}
+
+	for _, r := range results.Containers {
+		if !s.include(r.ScanResultBase) {
+			continue
+		}
+		switch s.GroupBy {
+		case IssueGroupings.Finding:
+			add(r.ScanResultBase, s.groupKey(projectID, "finding", r.FingerprintWithOptions(s.FingerprintOptions)), fmt.Sprintf("[Cx1] %v in %v (image %v)", r.VulnerabilityDetails.CveName, r.Data.PackageName, r.Data.ImageName), r.String())
+		case IssueGroupings.CVE:
+			if r.VulnerabilityDetails.CveName != "" {
+				add(r.ScanResultBase, s.groupKey(projectID, "cve", r.VulnerabilityDetails.CveName), fmt.Sprintf("[Cx1] %v", r.VulnerabilityDetails.CveName), r.String())
+				break
+			}
+			fallthrough
+		default:
+			add(r.ScanResultBase, s.groupKey(projectID, "package", "image", r.Data.ImageName, r.Data.PackageName), fmt.Sprintf("[Cx1] Vulnerable package %v in image %v", r.Data.PackageName, r.Data.ImageName), r.String())
+		}
+	}
+
+	for _, group := range groups {
+		sort.Strings(group.Results)
+		sort.Strings(group.Lines)
+	}
+
+	return groups
+}
+
+func (g issueGroup) description(projectID, scanID string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Checkmarx One project %v, scan %v\n", projectID, scanID)
+	fmt.Fprintf(&b, "%d result(s):\n", len(g.Lines))
+	for _, line := range g.Lines {
+		fmt.Fprintf(&b, "- %v\n", line)
+	}
+	fmt.Fprintf(&b, "\nSync key: %v\n", g.Key)
+	return b.String()
+}
+
+func NewIssueSyncMapping() *IssueSyncMapping {
+	return &IssueSyncMapping{Issues: make(map[string]IssueSyncRecord)}
+}
+
+// Loads a mapping previously saved with IssueSyncMapping.Save. If the file does not exist, an empty mapping is returned
+func LoadIssueSyncMapping(filename string) (*IssueSyncMapping, error) {
+	data, err := os.ReadFile(filename)
+	if errors.Is(err, os.ErrNotExist) {
+		return NewIssueSyncMapping(), nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read issue mapping %v: %s", filename, err)
+	}
+
+	mapping := NewIssueSyncMapping()
+	if err = json.Unmarshal(data, mapping); err != nil {
+		return nil, fmt.Errorf("failed to parse issue mapping %v: %s", filename, err)
+	}
+	if mapping.Issues == nil {
+		mapping.Issues = make(map[string]IssueSyncRecord)
+	}
+	return mapping, nil
+}
+
+func (m IssueSyncMapping) Save(filename string) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filename, data, 0644)
+}
+
+func (s IssueSyncSummary) String() string {
+	return fmt.Sprintf("%d created, %d updated, %d reopened, %d closed, %d unchanged", len(s.Created), len(s.Updated), len(s.Reopened), len(s.Closed), len(s.Unchanged))
+}
+
+func NewMemoryIssueTracker() *MemoryIssueTracker {
+	return &MemoryIssueTracker{Issues: make(map[string]*TrackerIssue)}
+}
+
+func (t *MemoryIssueTracker) CreateIssue(issue TrackerIssue) (string, error) {
+	t.nextID++
+	issue.ID = fmt.Sprintf("MEM-%d", t.nextID)
+	issue.Labels = slices.Clone(issue.Labels)
+	t.Issues[issue.ID] = &issue
+	return issue.ID, nil
+}
+
+func (t *MemoryIssueTracker) UpdateIssue(issue TrackerIssue) error {
+	existing, ok := t.Issues[issue.ID]
+	if !ok {
+		return fmt.Errorf("issue %v not found", issue.ID)
+	}
+	issue.Closed = existing.Closed
+	issue.Labels = slices.Clone(issue.Labels)
+	*existing = issue
+	return nil
+}
+
+func (t *MemoryIssueTracker) CloseIssue(issueID, comment string) error {
+	existing, ok := t.Issues[issueID]
+	if !ok {
+		return fmt.Errorf("issue %v not found", issueID)
+	}
+	existing.Closed = true
+	return nil
+}
+
+func (t *MemoryIssueTracker) ReopenIssue(issueID, comment string) error {
+	existing, ok := t.Issues[issueID]
+	if !ok {
+		return fmt.Errorf("issue %v not found", issueID)
+	}
+	existing.Closed = false
+	return nil
+}
+
+// Returns the most recently created issue with the key, or nil if there is none
+func (t *MemoryIssueTracker) FindByKey(key string) (*TrackerIssue, error) {
+	var found *TrackerIssue
+	for id := 1; id <= t.nextID; id++ {
+		if issue, ok := t.Issues[fmt.Sprintf("MEM-%d", id)]; ok && issue.Key == key {
+			found = issue
+		}
+	}
+	if found == nil {
+		return nil, nil
+	}
+	issue := *found
+	return &issue, nil
+}
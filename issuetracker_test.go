@@ -0,0 +1,119 @@
+package Cx1ClientGo
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func issueSyncResults(files ...string) ScanResultSet {
+	var results ScanResultSet
+	for i, file := range files {
+		var r ScanSASTResult
+		r.SimilarityID = file + string(rune('a'+i))
+		r.Severity = "HIGH"
+		r.State = "TO_VERIFY"
+		r.Data.LanguageName = "Java"
+		r.Data.QueryName = "SQL_Injection"
+		r.Data.Nodes = []ScanSASTResultNodes{{FileName: file, Line: uint64(10 + i), Name: "id"}}
+		results.SAST = append(results.SAST, r)
+	}
+	return results
+}
+
+func TestIssueSyncJira(t *testing.T) {
+	jira, tracker := newFakeJira(t)
+	sync := NewIssueSync(tracker, nil, IssueGroupings.QueryFile)
+	sync.Labels = []string{"security"}
+
+	summary, err := sync.Sync("project", "scan1", issueSyncResults("/src/A.java", "/src/B.java"))
+	if err != nil {
+		t.Fatalf("failed to sync: %s", err)
+	}
+	if len(summary.Created) != 2 || len(jira.Issues) != 2 {
+		t.Fatalf("expected 2 issues to be created, got %v", summary)
+	}
+	for _, issue := range jira.Issues {
+		if !strings.HasPrefix(issue.Labels[0], "cx1-") || issue.Labels[1] != "security" || !strings.HasPrefix(issue.Summary, "[Cx1] SQL_Injection in /src/") {
+			t.Errorf("unexpected issue: %+v", issue)
+		}
+	}
+
+	// a second result in A.java updates its issue, B.java is fixed
+	summary, err = sync.Sync("project", "scan2", issueSyncResults("/src/A.java", "/src/A.java"))
+	if err != nil {
+		t.Fatalf("failed to sync: %s", err)
+	}
+	if len(summary.Updated) != 1 || len(summary.Closed) != 1 || len(summary.Created) != 0 {
+		t.Fatalf("expected 1 update and 1 close, got %v", summary)
+	}
+	updated, closed := jira.Issues[summary.Updated[0]], jira.Issues[summary.Closed[0]]
+	if !strings.Contains(updated.Description, "2 result(s)") || updated.Done {
+		t.Errorf("issue was not updated: %+v", updated)
+	}
+	if !closed.Done || len(closed.Comments) != 1 || !strings.Contains(closed.Comments[0], "scan2") {
+		t.Errorf("issue was not closed: %+v", closed)
+	}
+
+	// unchanged results do not touch jira, B.java recurring reopens its issue
+	requests := len(jira.Requests)
+	summary, err = sync.Sync("project", "scan3", issueSyncResults("/src/A.java", "/src/A.java"))
+	if err != nil || len(summary.Unchanged) != 1 || len(jira.Requests) != requests {
+		t.Errorf("expected an unchanged issue without requests, got %v, %v, %d requests", summary, err, len(jira.Requests)-requests)
+	}
+	summary, err = sync.Sync("project", "scan4", issueSyncResults("/src/A.java", "/src/A.java", "/src/B.java"))
+	if err != nil || len(summary.Reopened) != 1 || summary.Reopened[0] != closed.Key || closed.Done {
+		t.Errorf("expected issue %v to be reopened, got %v, %v", closed.Key, summary, err)
+	}
+}
+
+func TestIssueSyncJiraExistingIssue(t *testing.T) {
+	jira, tracker := newFakeJira(t)
+	first := NewIssueSync(tracker, nil, IssueGroupings.QueryFile)
+	if _, err := first.Sync("project", "scan1", issueSyncResults("/src/A.java")); err != nil {
+		t.Fatalf("failed to sync: %s", err)
+	}
+
+	// a sync with a lost mapping finds the issue by its key label instead of creating a duplicate
+	second := NewIssueSync(tracker, nil, IssueGroupings.QueryFile)
+	summary, err := second.Sync("project", "scan2", issueSyncResults("/src/A.java"))
+	if err != nil {
+		t.Fatalf("failed to sync: %s", err)
+	}
+	if len(summary.Created) != 0 || len(jira.Issues) != 1 {
+		t.Errorf("expected the existing issue to be reused, got %v and %d issues", summary, len(jira.Issues))
+	}
+}
+
+func TestIssueSyncJiraErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		fail string
+		scan ScanResultSet
+	}{
+		{"create", "POST /issue", issueSyncResults("/src/A.java", "/src/C.java")},
+		{"update", "PUT /issue/SEC-1", issueSyncResults("/src/A.java", "/src/A.java")},
+		{"close", "POST /issue/SEC-1/transitions", issueSyncResults()},
+		{"search", "GET /search", issueSyncResults("/src/A.java", "/src/C.java")},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			jira, tracker := newFakeJira(t)
+			sync := NewIssueSync(tracker, nil, IssueGroupings.QueryFile)
+			if _, err := sync.Sync("project", "scan1", issueSyncResults("/src/A.java")); err != nil {
+				t.Fatalf("failed to sync: %s", err)
+			}
+
+			jira.Fail[test.fail] = http.StatusServiceUnavailable
+			if _, err := sync.Sync("project", "scan2", test.scan); err == nil || !strings.Contains(err.Error(), "503") {
+				t.Errorf("expected the HTTP error to be returned, got: %v", err)
+			}
+			for _, record := range sync.Mapping.Issues {
+				if record.Closed || record.LastScan == "" {
+					t.Errorf("mapping was changed despite the error: %+v", record)
+				}
+			}
+		})
+	}
+}
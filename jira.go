@@ -0,0 +1,192 @@
+package Cx1ClientGo
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Creates a Jira issue tracker using basic authentication with a username and API token (Jira Cloud)
+// or, if username is empty, bearer authentication with a personal access token (Jira Data Center)
+// client may be nil to use http.DefaultClient
+func NewJiraIssueTracker(baseURL, projectKey, username, token string, client *http.Client) *JiraIssueTracker {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &JiraIssueTracker{
+		BaseURL:          strings.TrimRight(baseURL, "/"),
+		ProjectKey:       projectKey,
+		IssueType:        "Bug",
+		Username:         username,
+		Token:            token,
+		CloseTransition:  "Done",
+		ReopenTransition: "To Do",
+		Client:           client,
+	}
+}
+
+func (j *JiraIssueTracker) CreateIssue(issue TrackerIssue) (string, error) {
+	fields := j.issueFields(issue)
+	fields["project"] = map[string]string{"key": j.ProjectKey}
+	fields["issuetype"] = map[string]string{"name": j.IssueType}
+	fields["labels"] = issue.Labels
+
+	var response struct {
+		ID  string `json:"id"`
+		Key string `json:"key"`
+	}
+	if err := j.sendRequest(http.MethodPost, "/issue", map[string]interface{}{"fields": fields}, &response); err != nil {
+		return "", fmt.Errorf("failed to create jira issue: %s", err)
+	}
+	return response.Key, nil
+}
+
+// Updates the summary and description of the issue. Labels are not changed, to preserve any added in Jira
+func (j *JiraIssueTracker) UpdateIssue(issue TrackerIssue) error {
+	if err := j.sendRequest(http.MethodPut, fmt.Sprintf("/issue/%v", url.PathEscape(issue.ID)), map[string]interface{}{"fields": j.issueFields(issue)}, nil); err != nil {
+		return fmt.Errorf("failed to update jira issue %v: %s", issue.ID, err)
+	}
+	return nil
+}
+
+func (j *JiraIssueTracker) CloseIssue(issueID, comment string) error {
+	return j.transitionIssue(issueID, j.CloseTransition, comment)
+}
+
+func (j *JiraIssueTracker) ReopenIssue(issueID, comment string) error {
+	return j.transitionIssue(issueID, j.ReopenTransition, comment)
+}
+
+// Returns the most recently created issue in the project with the key as a label, or nil if there is none
+func (j *JiraIssueTracker) FindByKey(key string) (*TrackerIssue, error) {
+	params := url.Values{
+		"jql":        {fmt.Sprintf("project = \"%v\" AND labels = \"%v\" ORDER BY created DESC", j.ProjectKey, key)},
+		"fields":     {"summary,description,labels,status"},
+		"maxResults": {"1"},
+	}
+
+	var response struct {
+		Issues []struct {
+			Key    string `json:"key"`
+			Fields struct {
+				Summary     string   `json:"summary"`
+				Description string   `json:"description"`
+				Labels      []string `json:"labels"`
+				Status      struct {
+					StatusCategory struct {
+						Key string `json:"key"`
+					} `json:"statusCategory"`
+				} `json:"status"`
+			} `json:"fields"`
+		} `json:"issues"`
+	}
+	if err := j.sendRequest(http.MethodGet, "/search?"+params.Encode(), nil, &response); err != nil {
+		return nil, fmt.Errorf("failed to search jira issues for %v: %s", key, err)
+	}
+	if len(response.Issues) == 0 {
+		return nil, nil
+	}
+
+	i := response.Issues[0]
+	return &TrackerIssue{
+		ID:          i.Key,
+		Key:         key,
+		Title:       i.Fields.Summary,
+		Description: i.Fields.Description,
+		Labels:      i.Fields.Labels,
+		Closed:      i.Fields.Status.StatusCategory.Key == "done",
+	}, nil
+}
+
+func (j *JiraIssueTracker) issueFields(issue TrackerIssue) map[string]interface{} {
+	return map[string]interface{}{
+		"summary":     issue.Title,
+		"description": issue.Description,
+	}
+}
+
+// adds the comment and then applies the named workflow transition
+func (j *JiraIssueTracker) transitionIssue(issueID, transition, comment string) error {
+	if comment != "" {
+		if err := j.sendRequest(http.MethodPost, fmt.Sprintf("/issue/%v/comment", url.PathEscape(issueID)), map[string]string{"body": comment}, nil); err != nil {
+			return fmt.Errorf("failed to comment on jira issue %v: %s", issueID, err)
+		}
+	}
+
+	var response struct {
+		Transitions []struct {
+			ID   string `json:"id"`
+			Name string `json:"name"`
+			To   struct {
+				Name string `json:"name"`
+			} `json:"to"`
+		} `json:"transitions"`
+	}
+	if err := j.sendRequest(http.MethodGet, fmt.Sprintf("/issue/%v/transitions", url.PathEscape(issueID)), nil, &response); err != nil {
+		return fmt.Errorf("failed to get transitions for jira issue %v: %s", issueID, err)
+	}
+
+	for _, t := range response.Transitions {
+		if strings.EqualFold(t.Name, transition) || strings.EqualFold(t.To.Name, transition) {
+			body := map[string]interface{}{"transition": map[string]string{"id": t.ID}}
+			if err := j.sendRequest(http.MethodPost, fmt.Sprintf("/issue/%v/transitions", url.PathEscape(issueID)), body, nil); err != nil {
+				return fmt.Errorf("failed to transition jira issue %v to %v: %s", issueID, transition, err)
+			}
+			return nil
+		}
+	}
+
+	return fmt.Errorf("jira issue %v has no transition %v", issueID, transition)
+}
+
+func (j *JiraIssueTracker) sendRequest(method, path string, body interface{}, result interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	request, err := http.NewRequest(method, j.BaseURL+"/rest/api/2"+path, reader)
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Accept", "application/json")
+	if body != nil {
+		request.Header.Set("Content-Type", "application/json")
+	}
+	if j.Username != "" {
+		request.SetBasicAuth(j.Username, j.Token)
+	} else {
+		request.Header.Set("Authorization", "Bearer "+j.Token)
+	}
+
+	client := j.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	response, err := client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	data, err := io.ReadAll(response.Body)
+	if err != nil {
+		return err
+	}
+	if response.StatusCode >= 400 {
+		return fmt.Errorf("HTTP %v: %v", response.Status, string(data))
+	}
+
+	if result != nil && len(data) > 0 {
+		return json.Unmarshal(data, result)
+	}
+	return nil
+}
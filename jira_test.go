@@ -0,0 +1,249 @@
+package Cx1ClientGo
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type fakeJiraIssue struct {
+	Key         string
+	Summary     string
+	Description string
+	Labels      []string
+	Done        bool
+	Comments    []string
+}
+
+// a minimal Jira REST v2 server holding issues in memory
+type fakeJira struct {
+	sync.Mutex
+	Issues   map[string]*fakeJiraIssue
+	Requests []string
+	Fail     map[string]int // "METHOD path" to the HTTP status returned instead of handling the request
+	next     int
+}
+
+func newFakeJira(t *testing.T) (*fakeJira, *JiraIssueTracker) {
+	jira := &fakeJira{Issues: map[string]*fakeJiraIssue{}, Fail: map[string]int{}}
+	server := httptest.NewServer(jira)
+	t.Cleanup(server.Close)
+	return jira, NewJiraIssueTracker(server.URL+"/", "SEC", "user@example.com", "token", server.Client())
+}
+
+func (f *fakeJira) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.Lock()
+	defer f.Unlock()
+
+	path := strings.TrimPrefix(r.URL.Path, "/rest/api/2")
+	f.Requests = append(f.Requests, r.Method+" "+path)
+	if status, ok := f.Fail[r.Method+" "+path]; ok {
+		http.Error(w, `{"errorMessages":["failed"]}`, status)
+		return
+	}
+	if user, token, ok := r.BasicAuth(); !ok || user != "user@example.com" || token != "token" {
+		http.Error(w, `{"errorMessages":["unauthorized"]}`, http.StatusUnauthorized)
+		return
+	}
+
+	var body struct {
+		Fields struct {
+			Summary     string   `json:"summary"`
+			Description string   `json:"description"`
+			Labels      []string `json:"labels"`
+		} `json:"fields"`
+		Body       string            `json:"body"`
+		Transition map[string]string `json:"transition"`
+	}
+	if data, _ := io.ReadAll(r.Body); len(data) > 0 {
+		if err := json.Unmarshal(data, &body); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	var issue *fakeJiraIssue
+	if len(parts) > 1 && parts[0] == "issue" {
+		if issue = f.Issues[parts[1]]; issue == nil {
+			http.Error(w, `{"errorMessages":["Issue does not exist"]}`, http.StatusNotFound)
+			return
+		}
+	}
+
+	switch {
+	case r.Method == http.MethodPost && path == "/issue":
+		f.next++
+		issue = &fakeJiraIssue{Key: fmt.Sprintf("SEC-%d", f.next), Summary: body.Fields.Summary, Description: body.Fields.Description, Labels: body.Fields.Labels}
+		f.Issues[issue.Key] = issue
+		json.NewEncoder(w).Encode(map[string]string{"id": fmt.Sprint(10000 + f.next), "key": issue.Key})
+	case r.Method == http.MethodPut && len(parts) == 2:
+		issue.Summary = body.Fields.Summary
+		issue.Description = body.Fields.Description
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodPost && len(parts) == 3 && parts[2] == "comment":
+		issue.Comments = append(issue.Comments, body.Body)
+		w.WriteHeader(http.StatusCreated)
+	case r.Method == http.MethodGet && len(parts) == 3 && parts[2] == "transitions":
+		fmt.Fprint(w, `{"transitions":[{"id":"11","name":"Start","to":{"name":"In Progress"}},{"id":"21","name":"Resolve","to":{"name":"Done"}},{"id":"31","name":"Reopen","to":{"name":"To Do"}}]}`)
+	case r.Method == http.MethodPost && len(parts) == 3 && parts[2] == "transitions":
+		switch body.Transition["id"] {
+		case "21":
+			issue.Done = true
+		case "31", "11":
+			issue.Done = false
+		}
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodGet && path == "/search":
+		issues := []map[string]interface{}{}
+		for _, i := range f.Issues {
+			if !strings.Contains(r.URL.Query().Get("jql"), fmt.Sprintf("labels = \"%v\"", i.Labels[0])) {
+				continue
+			}
+			status := "new"
+			if i.Done {
+				status = "done"
+			}
+			issues = append(issues, map[string]interface{}{
+				"key": i.Key,
+				"fields": map[string]interface{}{
+					"summary": i.Summary, "description": i.Description, "labels": i.Labels,
+					"status": map[string]interface{}{"statusCategory": map[string]string{"key": status}},
+				},
+			})
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"issues": issues})
+	default:
+		http.Error(w, "unexpected request", http.StatusNotImplemented)
+	}
+}
+
+func TestJiraCreateIssue(t *testing.T) {
+	jira, tracker := newFakeJira(t)
+
+	id, err := tracker.CreateIssue(TrackerIssue{Key: "cx1-abc", Title: "SQL Injection", Description: "details", Labels: []string{"cx1-abc", "security"}})
+	if err != nil {
+		t.Fatalf("failed to create issue: %s", err)
+	}
+	if id != "SEC-1" {
+		t.Errorf("expected issue SEC-1, got %v", id)
+	}
+	issue := jira.Issues[id]
+	if issue == nil || issue.Summary != "SQL Injection" || issue.Description != "details" || strings.Join(issue.Labels, ",") != "cx1-abc,security" {
+		t.Errorf("unexpected issue in jira: %+v", issue)
+	}
+
+	found, err := tracker.FindByKey("cx1-abc")
+	if err != nil {
+		t.Fatalf("failed to find issue: %s", err)
+	}
+	if found == nil || found.ID != id || found.Closed {
+		t.Errorf("unexpected issue found: %+v", found)
+	}
+	if found, err = tracker.FindByKey("cx1-other"); err != nil || found != nil {
+		t.Errorf("expected no issue, got %+v, %v", found, err)
+	}
+}
+
+func TestJiraUpdateIssue(t *testing.T) {
+	jira, tracker := newFakeJira(t)
+	id, _ := tracker.CreateIssue(TrackerIssue{Key: "cx1-abc", Title: "old", Labels: []string{"cx1-abc", "manual"}})
+
+	if err := tracker.UpdateIssue(TrackerIssue{ID: id, Key: "cx1-abc", Title: "new", Description: "updated", Labels: []string{"cx1-abc"}}); err != nil {
+		t.Fatalf("failed to update issue: %s", err)
+	}
+	issue := jira.Issues[id]
+	if issue.Summary != "new" || issue.Description != "updated" {
+		t.Errorf("issue was not updated: %+v", issue)
+	}
+	if len(issue.Labels) != 2 {
+		t.Errorf("labels should not be changed by an update, got %v", issue.Labels)
+	}
+}
+
+func TestJiraCloseAndReopenIssue(t *testing.T) {
+	jira, tracker := newFakeJira(t)
+	id, _ := tracker.CreateIssue(TrackerIssue{Key: "cx1-abc", Title: "finding", Labels: []string{"cx1-abc"}})
+
+	if err := tracker.CloseIssue(id, "fixed"); err != nil {
+		t.Fatalf("failed to close issue: %s", err)
+	}
+	if issue := jira.Issues[id]; !issue.Done || len(issue.Comments) != 1 || issue.Comments[0] != "fixed" {
+		t.Errorf("issue was not closed with a comment: %+v", issue)
+	}
+	if found, _ := tracker.FindByKey("cx1-abc"); found == nil || !found.Closed {
+		t.Errorf("expected a closed issue, got %+v", found)
+	}
+
+	if err := tracker.ReopenIssue(id, ""); err != nil {
+		t.Fatalf("failed to reopen issue: %s", err)
+	}
+	if issue := jira.Issues[id]; issue.Done || len(issue.Comments) != 1 {
+		t.Errorf("issue was not reopened without a comment: %+v", issue)
+	}
+}
+
+func TestJiraErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		fail   string
+		status int
+		call   func(tracker *JiraIssueTracker) error
+	}{
+		{"create", "POST /issue", http.StatusBadRequest, func(tracker *JiraIssueTracker) error {
+			_, err := tracker.CreateIssue(TrackerIssue{Key: "cx1-new", Labels: []string{"cx1-new"}})
+			return err
+		}},
+		{"update", "PUT /issue/SEC-1", http.StatusForbidden, func(tracker *JiraIssueTracker) error {
+			return tracker.UpdateIssue(TrackerIssue{ID: "SEC-1"})
+		}},
+		{"update missing issue", "", 0, func(tracker *JiraIssueTracker) error {
+			return tracker.UpdateIssue(TrackerIssue{ID: "SEC-99"})
+		}},
+		{"close comment", "POST /issue/SEC-1/comment", http.StatusInternalServerError, func(tracker *JiraIssueTracker) error {
+			return tracker.CloseIssue("SEC-1", "fixed")
+		}},
+		{"close transition", "POST /issue/SEC-1/transitions", http.StatusConflict, func(tracker *JiraIssueTracker) error {
+			return tracker.CloseIssue("SEC-1", "")
+		}},
+		{"unknown transition", "", 0, func(tracker *JiraIssueTracker) error {
+			tracker.CloseTransition = "Closed"
+			return tracker.CloseIssue("SEC-1", "")
+		}},
+		{"search", "GET /search", http.StatusBadRequest, func(tracker *JiraIssueTracker) error {
+			_, err := tracker.FindByKey("cx1-abc")
+			return err
+		}},
+		{"authentication", "", 0, func(tracker *JiraIssueTracker) error {
+			tracker.Token = "wrong"
+			_, err := tracker.CreateIssue(TrackerIssue{Key: "cx1-new", Labels: []string{"cx1-new"}})
+			return err
+		}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			jira, tracker := newFakeJira(t)
+			tracker.CreateIssue(TrackerIssue{Key: "cx1-abc", Title: "finding", Labels: []string{"cx1-abc"}})
+			if test.fail != "" {
+				jira.Fail[test.fail] = test.status
+			}
+
+			err := test.call(tracker)
+			if err == nil {
+				t.Fatalf("expected an error")
+			}
+			if test.status != 0 && !strings.Contains(err.Error(), fmt.Sprint(test.status)) {
+				t.Errorf("expected the HTTP status in the error, got: %s", err)
+			}
+			if jira.Issues["SEC-1"].Done {
+				t.Errorf("issue was closed despite the error")
+			}
+		})
+	}
+}
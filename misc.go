@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
+	"sync"
 )
 
 var scanEngineLicenseMap = map[string]string{
@@ -275,6 +276,8 @@ func (c *Cx1Client) GetVersion() (VersionInfo, error) {
 }
 
 func (c *Cx1Client) GetAccessToken() string {
+	c.tokenLock.Lock()
+	defer c.tokenLock.Unlock()
 	return c.config.Auth.AccessToken
 }
 
@@ -289,7 +292,14 @@ func (c *Cx1Client) SetLogger(logger Logger) {
 // returns a copy of this client which can be used separately
 // they will not share access tokens or other data after the clone.
 func (c *Cx1Client) Clone() Cx1Client {
-	return *c
+	c.tokenLock.Lock()
+	defer c.tokenLock.Unlock()
+	c.catalogueLock.Lock()
+	defer c.catalogueLock.Unlock()
+	clone := *c
+	clone.catalogueLock = &sync.Mutex{}
+	clone.tokenLock = &sync.Mutex{}
+	return clone
 }
 
 // If you are heavily using functions that throw deprecation warnings you can mute them here
@@ -44,11 +44,11 @@ func (c *Cx1Client) createRequest(method, url string, body io.Reader, header *ht
 	}
 
 	// add auth header
-	err = c.refreshAccessToken()
+	token, err := c.getAccessToken()
 	if err != nil {
 		return &http.Request{}, fmt.Errorf("failed to get access token: %s", err)
 	}
-	request.Header.Set("Authorization", fmt.Sprintf("Bearer %v", c.config.Auth.AccessToken))
+	request.Header.Set("Authorization", fmt.Sprintf("Bearer %v", token))
 
 	for _, cookie := range cookies {
 		request.AddCookie(cookie)
@@ -98,7 +98,18 @@ func (c *Cx1Client) sendTokenRequest(body io.Reader) (access_token string, err e
 	return
 }
 
+// refreshes the access token if it is missing or about to expire
 func (c *Cx1Client) refreshAccessToken() error {
+	_, err := c.getAccessToken()
+	return err
+}
+
+// returns the access token, refreshing it first if required.
+// tokenLock is held throughout since requests may be sent from several goroutines at once (eg: runConcurrently)
+func (c *Cx1Client) getAccessToken() (string, error) {
+	c.tokenLock.Lock()
+	defer c.tokenLock.Unlock()
+
 	if c.config.Auth.AccessToken == "" || c.config.Auth.Expiry.Before(time.Now().Add(30*time.Second)) {
 		c.config.Logger.Tracef("Refreshing access token (%v) with expiry %v", ShortenGUID(c.config.Auth.AccessToken), c.config.Auth.Expiry)
 		if c.config.Auth.APIKey != "" {
@@ -109,13 +120,13 @@ func (c *Cx1Client) refreshAccessToken() error {
 
 			access_token, err := c.sendTokenRequest(strings.NewReader(data.Encode()))
 			if err != nil {
-				return err
+				return "", err
 			}
 			c.config.Auth.AccessToken = access_token
 
 			claims, err := parseJWT(c.config.Auth.AccessToken)
 			if err != nil {
-				return fmt.Errorf("failed to parse API Key JWT: %v", err)
+				return "", fmt.Errorf("failed to parse API Key JWT: %v", err)
 			}
 			c.claims = claims
 			c.config.Auth.Expiry = c.claims.ExpiryTime
@@ -128,19 +139,19 @@ func (c *Cx1Client) refreshAccessToken() error {
 
 			access_token, err := c.sendTokenRequest(strings.NewReader(data.Encode()))
 			if err != nil {
-				return err
+				return "", err
 			}
 			c.config.Auth.AccessToken = access_token
 			claims, err := parseJWT(c.config.Auth.AccessToken)
 			if err != nil {
-				return fmt.Errorf("failed to parse API Key JWT: %v", err)
+				return "", fmt.Errorf("failed to parse API Key JWT: %v", err)
 			}
 			c.claims = claims
 			c.config.Auth.Expiry = c.claims.ExpiryTime
 			c.config.Logger.Tracef("New token (%v) has expiry %v", ShortenGUID(access_token), c.config.Auth.Expiry)
 		}
 	}
-	return nil
+	return c.config.Auth.AccessToken, nil
 }
 
 func (c *Cx1Client) sendRequestInternal(method, url string, body io.Reader, header http.Header) ([]byte, error) {
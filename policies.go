@@ -1,11 +1,13 @@
 package Cx1ClientGo
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"net/http"
 
 	"github.com/google/go-querystring/query"
+	"golang.org/x/exp/slices"
 )
 
 func (f *BasePolicyFilter) Bump() {
@@ -143,6 +145,227 @@ func (c *Cx1Client) GetPolicyViolationCountFiltered(filter PolicyViolationFilter
 	return count, err
 }
 
+func (c *Cx1Client) GetPolicyByID(policyID uint64) (Policy, error) {
+	var policy Policy
+	response, err := c.sendRequest(http.MethodGet, fmt.Sprintf("/policy_management_service_uri/policies/%d", policyID), nil, nil)
+	if err != nil {
+		return policy, err
+	}
+
+	err = json.Unmarshal(response, &policy)
+	return policy, err
+}
+
+func (c *Cx1Client) GetPolicyByName(name string) (Policy, error) {
+	policies, err := c.GetAllPolicies()
+	if err != nil {
+		return Policy{}, err
+	}
+	for _, p := range policies {
+		if p.Name == name {
+			return p, nil
+		}
+	}
+	return Policy{}, fmt.Errorf("no policy found named %v", name)
+}
+
+// the policy fields which can be set by CreatePolicy and UpdatePolicy, the rest (eg: LastViolated, UpdatedAt, ViolatingAssocProjects) are read-only
+type policyWrite struct {
+	Name             string          `json:"name"`
+	Description      string          `json:"description"`
+	IsActivated      bool            `json:"isActivated"`
+	Tags             []string        `json:"tags"`
+	BreakBuild       bool            `json:"breakBuild"`
+	PinPolicy        bool            `json:"pinPolicy"`
+	NetNewBreakBuild bool            `json:"netNewBreakBuild"`
+	Projects         []PolicyProject `json:"projects"`
+}
+
+var policyReadOnlyFields = []string{"lastViolated", "updatedAt", "violatingAssocProjects"}
+
+func (p Policy) write() policyWrite {
+	if p.Tags == nil {
+		p.Tags = []string{}
+	}
+	if p.Projects == nil {
+		p.Projects = []PolicyProject{}
+	}
+	return policyWrite{
+		Name:             p.Name,
+		Description:      p.Description,
+		IsActivated:      p.IsActivated,
+		Tags:             p.Tags,
+		BreakBuild:       p.BreakBuild,
+		PinPolicy:        p.PinPolicy,
+		NetNewBreakBuild: p.NetNewBreakBuild,
+		Projects:         p.Projects,
+	}
+}
+
+// Creates the policy with its rules and project associations, returning the policy with its new ID
+func (c *Cx1Client) CreatePolicy(policy Policy) (Policy, error) {
+	c.config.Logger.Debugf("Create Policy: %v", policy.Name)
+	var created Policy
+
+	rules := policy.Rules
+	if rules == nil {
+		rules = []PolicyRule{}
+	}
+	jsonBody, err := json.Marshal(struct {
+		policyWrite
+		Rules []PolicyRule `json:"rules"`
+	}{policy.write(), rules})
+	if err != nil {
+		return created, err
+	}
+
+	response, err := c.sendRequest(http.MethodPost, "/policy_management_service_uri/policies", bytes.NewReader(jsonBody), nil)
+	if err != nil {
+		c.config.Logger.Tracef("Error while creating policy: %s", err)
+		return created, err
+	}
+
+	err = json.Unmarshal(response, &created)
+	return created, err
+}
+
+// Updates the policy's writable fields, including its rules, project associations and activation.
+// The stored policy is fetched first and only the writable fields are replaced, so fields which are not modelled by Policy are kept.
+// The rules are only replaced if policy.Rules is not nil, as policies from GetAllPolicies do not include their rules.
+func (c *Cx1Client) UpdatePolicy(policy *Policy) error {
+	c.config.Logger.Debugf("Update Policy: %v", policy.String())
+
+	return c.putPolicy(policy.PolicyID, func(fields map[string]json.RawMessage) error {
+		data, err := json.Marshal(policy.write())
+		if err != nil {
+			return err
+		}
+		if err = json.Unmarshal(data, &fields); err != nil {
+			return err
+		}
+
+		if policy.Rules == nil {
+			return nil
+		}
+		var current []PolicyRule
+		if rules, ok := fields["rules"]; ok {
+			if err := json.Unmarshal(rules, &current); err == nil && slices.EqualFunc(current, policy.Rules, policyRulesEqual) {
+				return nil // unchanged, keep any rule fields not modelled by PolicyRule
+			}
+		}
+		fields["rules"], err = json.Marshal(policy.Rules)
+		return err
+	})
+}
+
+// fetches the stored policy, applies the update to its fields and saves it without the read-only fields
+func (c *Cx1Client) putPolicy(policyID uint64, update func(fields map[string]json.RawMessage) error) error {
+	response, err := c.sendRequest(http.MethodGet, fmt.Sprintf("/policy_management_service_uri/policies/%d", policyID), nil, nil)
+	if err != nil {
+		return fmt.Errorf("failed to get policy %d: %s", policyID, err)
+	}
+	fields := map[string]json.RawMessage{}
+	if err = json.Unmarshal(response, &fields); err != nil {
+		return fmt.Errorf("failed to parse policy %d: %s", policyID, err)
+	}
+
+	if err = update(fields); err != nil {
+		return fmt.Errorf("failed to update policy %d: %s", policyID, err)
+	}
+	for _, field := range policyReadOnlyFields {
+		delete(fields, field)
+	}
+
+	jsonBody, err := json.Marshal(fields)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.sendRequest(http.MethodPut, fmt.Sprintf("/policy_management_service_uri/policies/%d", policyID), bytes.NewReader(jsonBody), nil)
+	return err
+}
+
+func policyRulesEqual(a, b PolicyRule) bool {
+	return a.ID == b.ID && a.Name == b.Name && a.Type == b.Type && slices.EqualFunc(a.Conditions, b.Conditions, func(x, y PolicyRuleCondition) bool {
+		return x.Key == y.Key && x.Operator == y.Operator && slices.Equal(x.Values, y.Values)
+	})
+}
+
+// Delete a policy
+// There is no UNDO
+func (c *Cx1Client) DeletePolicy(policy *Policy) error {
+	return c.DeletePolicyByID(policy.PolicyID)
+}
+func (c *Cx1Client) DeletePolicyByID(policyID uint64) error {
+	c.config.Logger.Debugf("Delete Policy: %d", policyID)
+	_, err := c.sendRequest(http.MethodDelete, fmt.Sprintf("/policy_management_service_uri/policies/%d", policyID), nil, nil)
+	return err
+}
+
+// Activates the stored policy, without changing any of its other fields
+func (c *Cx1Client) ActivatePolicy(policy *Policy) error {
+	return c.setPolicyActivation(policy, true)
+}
+
+// Deactivates the stored policy, without changing any of its other fields
+func (c *Cx1Client) DeactivatePolicy(policy *Policy) error {
+	return c.setPolicyActivation(policy, false)
+}
+
+func (c *Cx1Client) setPolicyActivation(policy *Policy, active bool) error {
+	c.config.Logger.Debugf("Set Policy %v activation: %v", policy.String(), active)
+	err := c.putPolicy(policy.PolicyID, func(fields map[string]json.RawMessage) error {
+		fields["isActivated"], _ = json.Marshal(active)
+		return nil
+	})
+	if err == nil {
+		policy.IsActivated = active
+	}
+	return err
+}
+
+// Returns true if the policy is associated with the project
+func (p Policy) HasProject(projectID string) bool {
+	return slices.ContainsFunc(p.Projects, func(pp PolicyProject) bool { return pp.AstProjectID == projectID })
+}
+
+// AssignProject associates the policy with the project, UpdatePolicy must be called to save the change
+func (p *Policy) AssignProject(projectID string) {
+	if !p.HasProject(projectID) {
+		p.Projects = append(p.Projects, PolicyProject{AstProjectID: projectID})
+	}
+}
+
+// UnassignProject removes the association between the policy and the project, UpdatePolicy must be called to save the change
+func (p *Policy) UnassignProject(projectID string) {
+	p.Projects = slices.DeleteFunc(p.Projects, func(pp PolicyProject) bool { return pp.AstProjectID == projectID })
+}
+
+// returns the rule with this name, or nil
+func (p *Policy) GetRuleByName(name string) *PolicyRule {
+	for id := range p.Rules {
+		if p.Rules[id].Name == name {
+			return &p.Rules[id]
+		}
+	}
+	return nil
+}
+
+// SetRule adds the rule, or replaces the existing rule with the same name. UpdatePolicy must be called to save the change
+func (p *Policy) SetRule(rule PolicyRule) {
+	if existing := p.GetRuleByName(rule.Name); existing != nil {
+		rule.ID = existing.ID
+		*existing = rule
+		return
+	}
+	p.Rules = append(p.Rules, rule)
+}
+
+// RemoveRule removes the rule with this name, UpdatePolicy must be called to save the change
+func (p *Policy) RemoveRule(name string) {
+	p.Rules = slices.DeleteFunc(p.Rules, func(r PolicyRule) bool { return r.Name == name })
+}
+
 func (p Policy) String() string {
 	status := ""
 	if p.IsActivated {
@@ -0,0 +1,361 @@
+package Cx1ClientGo
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"golang.org/x/exp/slices"
+)
+
+// The condition keys of policy rules which can be simulated by Policy.Evaluate
+var PolicyConditionKeys = struct {
+	Scanner     string
+	Severity    string
+	State       string
+	Status      string
+	QueryName   string
+	CVSSScore   string
+	ResultCount string
+}{
+	Scanner:     "scanner",
+	Severity:    "severity",
+	State:       "state",
+	Status:      "status",
+	QueryName:   "queryName",
+	CVSSScore:   "cvssScore",
+	ResultCount: "resultCount",
+}
+
+// The condition operators which can be simulated, In and NotIn for lists of values and the comparisons for numbers
+var PolicyConditionOperators = struct {
+	In             string
+	NotIn          string
+	Equal          string
+	Greater        string
+	GreaterOrEqual string
+	Less           string
+	LessOrEqual    string
+}{
+	In:             "in",
+	NotIn:          "notIn",
+	Equal:          "=",
+	Greater:        ">",
+	GreaterOrEqual: ">=",
+	Less:           "<",
+	LessOrEqual:    "<=",
+}
+
+// The statuses reported in simulated PolicyViolationDetails
+var PolicyEvaluationStatuses = struct {
+	Violated  string
+	Compliant string
+}{
+	Violated:  "Violated",
+	Compliant: "Compliant",
+}
+
+// a result of any scanner, as seen by policy rules
+type policyResult struct {
+	scanner string
+	query   string
+	score   float64
+	ScanResultBase
+}
+
+func policyResults(results ScanResultSet) []policyResult {
+	list := []policyResult{}
+	for _, r := range results.SAST {
+		list = append(list, policyResult{"sast", r.Data.QueryName, r.CVSSScore, r.ScanResultBase})
+	}
+	for _, r := range results.SCA {
+		list = append(list, policyResult{"sca", "", max(r.CVSSScore, r.VulnerabilityDetails.CVSSScore), r.ScanResultBase})
+	}
+	for _, r := range results.SCAContainer {
+		list = append(list, policyResult{"sca", "", max(r.CVSSScore, r.VulnerabilityDetails.CVSSScore), r.ScanResultBase})
+	}
+	for _, r := range results.IAC {
+		list = append(list, policyResult{"kics", r.Data.QueryName, r.CVSSScore, r.ScanResultBase})
+	}
+	for _, r := range results.Containers {
+		list = append(list, policyResult{"containers", "", r.CVSSScore, r.ScanResultBase})
+	}
+	return list
+}
+
+func policyScanner(scanner string) string {
+	if scanner = strings.ToLower(scanner); scanner == "iac" {
+		return "kics"
+	}
+	return scanner
+}
+
+func (cond PolicyRuleCondition) matchesValue(value string) (bool, error) {
+	contains := slices.ContainsFunc(cond.Values, func(v string) bool {
+		if cond.Key == PolicyConditionKeys.Scanner {
+			return policyScanner(v) == policyScanner(value)
+		}
+		return strings.EqualFold(v, value)
+	})
+	switch cond.Operator {
+	case PolicyConditionOperators.In:
+		return contains, nil
+	case PolicyConditionOperators.NotIn:
+		return !contains, nil
+	}
+	return false, fmt.Errorf("operator %v of condition %v can not be simulated", cond.Operator, cond.Key)
+}
+
+func (cond PolicyRuleCondition) matchesNumber(value float64) (bool, error) {
+	if len(cond.Values) != 1 {
+		return false, fmt.Errorf("condition %v must have a single value", cond.Key)
+	}
+	limit, err := strconv.ParseFloat(cond.Values[0], 64)
+	if err != nil {
+		return false, fmt.Errorf("invalid value %v for condition %v: %s", cond.Values[0], cond.Key, err)
+	}
+	switch cond.Operator {
+	case PolicyConditionOperators.Equal:
+		return value == limit, nil
+	case PolicyConditionOperators.Greater:
+		return value > limit, nil
+	case PolicyConditionOperators.GreaterOrEqual:
+		return value >= limit, nil
+	case PolicyConditionOperators.Less:
+		return value < limit, nil
+	case PolicyConditionOperators.LessOrEqual:
+		return value <= limit, nil
+	}
+	return false, fmt.Errorf("operator %v of condition %v can not be simulated", cond.Operator, cond.Key)
+}
+
+// checks that every condition of the rule can be simulated, and that the rule has conditions at all:
+// a rule without any (eg: not loaded with GetPolicyByID) would otherwise match every result
+func (r PolicyRule) validate(keys []string) error {
+	if len(r.Conditions) == 0 {
+		return fmt.Errorf("rule %v has no conditions", r.Name)
+	}
+	for _, cond := range r.Conditions {
+		if !slices.Contains(keys, cond.Key) {
+			return fmt.Errorf("condition %v of rule %v can not be simulated", cond.Key, r.Name)
+		}
+	}
+	return nil
+}
+
+// returns true if the result matches every condition of the rule, other than the result count.
+// Results which are not exploitable never match unless the rule has a state condition.
+func (r PolicyRule) matches(result policyResult) (bool, error) {
+	hasState := false
+	for _, cond := range r.Conditions {
+		var ok bool
+		var err error
+		switch cond.Key {
+		case PolicyConditionKeys.Scanner:
+			ok, err = cond.matchesValue(result.scanner)
+		case PolicyConditionKeys.Severity:
+			ok, err = cond.matchesValue(result.Severity)
+		case PolicyConditionKeys.State:
+			hasState = true
+			ok, err = cond.matchesValue(result.State)
+		case PolicyConditionKeys.Status:
+			ok, err = cond.matchesValue(result.Status)
+		case PolicyConditionKeys.QueryName:
+			ok, err = cond.matchesValue(result.query)
+		case PolicyConditionKeys.CVSSScore:
+			ok, err = cond.matchesNumber(result.score)
+		case PolicyConditionKeys.ResultCount:
+			ok = true
+		default:
+			err = fmt.Errorf("condition %v of rule %v can not be simulated", cond.Key, r.Name)
+		}
+		if err != nil || !ok {
+			return false, err
+		}
+	}
+	return hasState || !strings.EqualFold(result.State, "NOT_EXPLOITABLE"), nil
+}
+
+// returns true if the number of matching results violates the rule
+func (r PolicyRule) violatedBy(count uint64) (bool, error) {
+	violated := count >= 1
+	for _, cond := range r.Conditions {
+		if cond.Key == PolicyConditionKeys.ResultCount {
+			ok, err := cond.matchesNumber(float64(count))
+			if err != nil {
+				return false, err
+			}
+			violated = violated && ok
+		}
+	}
+	return violated, nil
+}
+
+// evaluates each rule using the count of results which match it.
+// A policy without rules can not be simulated, eg: policies from GetAllPolicies do not include them.
+func (p Policy) evaluate(keys []string, count func(rule PolicyRule) (uint64, error)) (PolicyViolationInfoPolicy, error) {
+	info := PolicyViolationInfoPolicy{
+		PolicyName:    p.Name,
+		Description:   p.Description,
+		RulesViolated: []string{},
+		Tags:          p.Tags,
+		BreakBuild:    p.BreakBuild,
+		Status:        PolicyEvaluationStatuses.Compliant,
+	}
+
+	if len(p.Rules) == 0 {
+		return info, fmt.Errorf("failed to evaluate policy %v: the policy has no rules, use GetPolicyByID to load them", p.Name)
+	}
+	for _, rule := range p.Rules {
+		if err := rule.validate(keys); err != nil {
+			return info, fmt.Errorf("failed to evaluate policy %v: %s", p.Name, err)
+		}
+		matched, err := count(rule)
+		if err == nil {
+			var violated bool
+			if violated, err = rule.violatedBy(matched); violated {
+				info.RulesViolated = append(info.RulesViolated, rule.Name)
+			}
+		}
+		if err != nil {
+			return info, fmt.Errorf("failed to evaluate policy %v: %s", p.Name, err)
+		}
+	}
+
+	if len(info.RulesViolated) > 0 {
+		info.Status = PolicyEvaluationStatuses.Violated
+	}
+	return info, nil
+}
+
+// Evaluates the policy's rules locally against the results of a scan, predicting the policy's entry in GetPolicyViolationDetailsByID.
+// This is a simulation: the platform's evaluation may differ. Rules with conditions which are not in PolicyConditionKeys return an error.
+func (p Policy) Evaluate(results ScanResultSet) (PolicyViolationInfoPolicy, error) {
+	list := policyResults(results)
+	keys := []string{
+		PolicyConditionKeys.Scanner, PolicyConditionKeys.Severity, PolicyConditionKeys.State, PolicyConditionKeys.Status,
+		PolicyConditionKeys.QueryName, PolicyConditionKeys.CVSSScore, PolicyConditionKeys.ResultCount,
+	}
+	return p.evaluate(keys, func(rule PolicyRule) (uint64, error) {
+		var count uint64
+		for _, r := range list {
+			ok, err := rule.matches(r)
+			if err != nil {
+				return count, err
+			}
+			if ok {
+				count++
+			}
+		}
+		return count, nil
+	})
+}
+
+// Evaluates the policy's rules against a scan summary instead of the full results, see Evaluate.
+// Summaries only count results by scanner, severity and status, and are assumed to exclude not-exploitable results,
+// so rules with state, query name or CVSS score conditions return an error and need the full results.
+func (p Policy) EvaluateSummary(summary ScanSummary) (PolicyViolationInfoPolicy, error) {
+	counters := map[string][]ScanSummarySeverityStatusCounter{
+		"sast":       summary.SASTCounters.SeverityStatusCounters,
+		"sca":        summary.SCACounters.SeverityStatusCounters,
+		"kics":       summary.IACCounters.SeverityStatusCounters,
+		"containers": summary.ContainersCounters.SeverityStatusCounters,
+	}
+	keys := []string{PolicyConditionKeys.Scanner, PolicyConditionKeys.Severity, PolicyConditionKeys.Status, PolicyConditionKeys.ResultCount}
+
+	return p.evaluate(keys, func(rule PolicyRule) (uint64, error) {
+		var count uint64
+		for _, scanner := range sortedKeys(counters) {
+			for _, c := range counters[scanner] {
+				if c.Counter <= 0 {
+					continue
+				}
+				ok, err := rule.matches(policyResult{scanner: scanner, ScanResultBase: ScanResultBase{Severity: c.Severity, Status: c.Status}})
+				if err != nil {
+					return count, err
+				}
+				if ok {
+					count += uint64(c.Counter)
+				}
+			}
+		}
+		return count, nil
+	})
+}
+
+// Predicts the result of GetPolicyViolationDetailsByID for a scan of the project from its results, without changing anything.
+// Every active policy associated with the project is evaluated (see Policy.Evaluate), and the build breaks if a violated policy has BreakBuild set.
+// This can be used to test new or changed policies before they are rolled out.
+func SimulatePolicies(policies []Policy, projectID string, results ScanResultSet) (PolicyViolationDetails, error) {
+	return simulatePolicies(policies, projectID, func(p Policy) (PolicyViolationInfoPolicy, error) {
+		return p.Evaluate(results)
+	})
+}
+
+// Predicts the result of GetPolicyViolationDetailsByID from a scan summary, see SimulatePolicies and Policy.EvaluateSummary
+func SimulatePoliciesWithSummary(policies []Policy, projectID string, summary ScanSummary) (PolicyViolationDetails, error) {
+	return simulatePolicies(policies, projectID, func(p Policy) (PolicyViolationInfoPolicy, error) {
+		return p.EvaluateSummary(summary)
+	})
+}
+
+func simulatePolicies(policies []Policy, projectID string, evaluate func(p Policy) (PolicyViolationInfoPolicy, error)) (PolicyViolationDetails, error) {
+	details := PolicyViolationDetails{Status: PolicyEvaluationStatuses.Compliant, Policies: []PolicyViolationInfoPolicy{}}
+	for _, p := range policies {
+		if !p.IsActivated || !p.HasProject(projectID) {
+			continue
+		}
+		info, err := evaluate(p)
+		if err != nil {
+			return details, err
+		}
+		details.Policies = append(details.Policies, info)
+		if info.Status == PolicyEvaluationStatuses.Violated {
+			details.Status = PolicyEvaluationStatuses.Violated
+			details.BreakBuild = details.BreakBuild || p.BreakBuild
+		}
+	}
+	return details, nil
+}
+
+// Simulates the policies against an existing scan of the project, see SimulatePolicies.
+// If policies is empty the tenant's current policies are loaded with GetPolicyByID, otherwise eg: modified copies of them can be tested.
+func (c *Cx1Client) SimulatePolicyViolationDetails(projectID, scanID string, policies []Policy) (PolicyViolationDetails, error) {
+	if len(policies) == 0 {
+		list, err := c.GetAllPolicies()
+		if err != nil {
+			return PolicyViolationDetails{}, fmt.Errorf("failed to get policies: %s", err)
+		}
+		for _, p := range list {
+			if !p.IsActivated || !p.HasProject(projectID) {
+				continue
+			}
+			// the list of policies does not include their rules
+			policy, err := c.GetPolicyByID(p.PolicyID)
+			if err != nil {
+				return PolicyViolationDetails{}, fmt.Errorf("failed to get policy %v: %s", p.String(), err)
+			}
+			policies = append(policies, policy)
+		}
+	}
+
+	results, err := c.GetAllScanResultsByID(scanID)
+	if err != nil {
+		return PolicyViolationDetails{}, fmt.Errorf("failed to get results of scan %v: %s", ShortenGUID(scanID), err)
+	}
+	return SimulatePolicies(policies, projectID, results)
+}
+
+// Returns the details in a human-readable form, one policy per line
+func (d PolicyViolationDetails) String() string {
+	lines := []string{}
+	for _, p := range d.Policies {
+		if len(p.RulesViolated) > 0 {
+			lines = append(lines, fmt.Sprintf("%v: %v (%v)", p.PolicyName, p.Status, strings.Join(p.RulesViolated, ", ")))
+		} else {
+			lines = append(lines, fmt.Sprintf("%v: %v", p.PolicyName, p.Status))
+		}
+	}
+	lines = append(lines, fmt.Sprintf("%v, break build: %v", d.Status, d.BreakBuild))
+	return strings.Join(lines, "\n")
+}
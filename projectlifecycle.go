@@ -0,0 +1,289 @@
+package Cx1ClientGo
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// Returns the projects which have not been scanned since the cutoff, including projects which were never scanned.
+// The project overviews are read page by page with GetProjectOverviewsFiltered, and the last scan of each candidate is confirmed with GetScansFiltered.
+// If checkBranches is set the project's branches are also counted, and projects without branches are flagged.
+func (c *Cx1Client) GetStaleProjects(cutoff time.Time, checkBranches bool) ([]StaleProject, error) {
+	stale := []StaleProject{}
+	filter := ProjectOverviewFilter{
+		BaseFilter: BaseFilter{Limit: c.config.Pagination.ProjectOverviews},
+	}
+
+	var checked uint64
+	for {
+		count, overviews, err := c.GetProjectOverviewsFiltered(filter)
+		if err != nil {
+			return stale, fmt.Errorf("failed to get project overviews: %s", err)
+		}
+
+		for _, o := range overviews {
+			checked++
+			if o.LastScanDate != "" {
+				if lastScan, err := time.Parse(time.RFC3339, o.LastScanDate); err == nil && lastScan.After(cutoff) {
+					continue
+				}
+			}
+
+			project, err := c.getStaleProject(o, cutoff, checkBranches)
+			if err != nil {
+				return stale, err
+			}
+			if project != nil {
+				stale = append(stale, *project)
+			}
+		}
+
+		if len(overviews) == 0 || filter.Limit == 0 || filter.Offset+filter.Limit >= count {
+			break
+		}
+		filter.Bump()
+	}
+
+	c.config.Logger.Debugf("Found %d stale projects out of %d", len(stale), checked)
+	return stale, nil
+}
+
+// returns nil if the project was scanned after the cutoff
+func (c *Cx1Client) getStaleProject(o ProjectOverview, cutoff time.Time, checkBranches bool) (*StaleProject, error) {
+	project := StaleProject{ProjectID: o.ProjectID, Name: o.Name, Reasons: []string{}}
+	count, scans, err := c.GetScansFiltered(ScanFilter{
+		BaseFilter: BaseFilter{Limit: 1},
+		ProjectID:  o.ProjectID,
+		Sort:       []string{ScanSortCreatedDescending},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the last scan of project %v: %s", o.Name, err)
+	}
+
+	if count == 0 || len(scans) == 0 {
+		project.Empty = true
+		project.Reasons = append(project.Reasons, "never scanned")
+	} else if scans[0].CreatedAt.After(cutoff) {
+		return nil, nil
+	} else {
+		project.LastScanID = scans[0].ScanID
+		project.LastScanDate = scans[0].CreatedAt
+		project.LastScanBranch = scans[0].Branch
+		project.Reasons = append(project.Reasons, fmt.Sprintf("no scans since %v", cutoff.Format(time.DateOnly)))
+	}
+
+	if checkBranches {
+		branches, err := c.GetProjectBranchesByID(o.ProjectID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get branches of project %v: %s", o.Name, err)
+		}
+		project.BranchCount = len(branches)
+		if len(branches) == 0 {
+			project.Reasons = append(project.Reasons, "no branches")
+		}
+	}
+
+	return &project, nil
+}
+
+// Collects the project's configuration, tags, groups, applications, scan schedules, the latest scan's result summary
+// and the triage history (predicates) of the latest scan's SAST and IAC results
+func (c *Cx1Client) GetProjectArchive(projectID string) (ProjectArchive, error) {
+	archive := ProjectArchive{
+		Created:        time.Now(),
+		Configuration:  []ConfigurationSetting{},
+		GroupPaths:     []string{},
+		Applications:   []string{},
+		ScanSchedules:  []ProjectScanSchedule{},
+		SASTPredicates: []SASTResultsPredicates{},
+		IACPredicates:  []IACResultsPredicates{},
+	}
+
+	project, err := c.GetProjectByID(projectID)
+	if err != nil {
+		return archive, fmt.Errorf("failed to get project %v: %s", ShortenGUID(projectID), err)
+	}
+	archive.Project = project
+
+	settings, err := c.GetProjectConfigurationByID(projectID)
+	if err != nil {
+		return archive, fmt.Errorf("failed to get configuration of project %v: %s", project.String(), err)
+	}
+	for _, s := range settings {
+		if s.OriginLevel == "Project" {
+			archive.Configuration = append(archive.Configuration, s)
+		}
+	}
+
+	for _, groupID := range project.Groups {
+		group, err := c.GetGroupByID(groupID)
+		if err != nil {
+			return archive, fmt.Errorf("failed to get group %v of project %v: %s", ShortenGUID(groupID), project.String(), err)
+		}
+		archive.GroupPaths = append(archive.GroupPaths, group.Path)
+	}
+
+	if project.Applications != nil {
+		for _, applicationID := range *project.Applications {
+			application, err := c.GetApplicationByID(applicationID)
+			if err != nil {
+				return archive, fmt.Errorf("failed to get application %v of project %v: %s", ShortenGUID(applicationID), project.String(), err)
+			}
+			archive.Applications = append(archive.Applications, application.Name)
+		}
+	}
+
+	if archive.ScanSchedules, err = c.GetScanSchedulesByID(projectID); err != nil {
+		return archive, fmt.Errorf("failed to get scan schedules of project %v: %s", project.String(), err)
+	}
+
+	count, scans, err := c.GetScansFiltered(ScanFilter{
+		BaseFilter: BaseFilter{Limit: 1},
+		ProjectID:  projectID,
+		Sort:       []string{ScanSortCreatedDescending},
+	})
+	if err != nil {
+		return archive, fmt.Errorf("failed to get the last scan of project %v: %s", project.String(), err)
+	}
+	if count == 0 || len(scans) == 0 {
+		c.config.Logger.Debugf("Project %v has no scans to archive", project.String())
+		return archive, nil
+	}
+	scan := scans[0]
+	archive.LastScan = &scan
+
+	summary, err := c.GetScanSummaryByID(scan.ScanID)
+	if err != nil {
+		return archive, fmt.Errorf("failed to get result summary of scan %v: %s", scan.String(), err)
+	}
+	archive.ResultSummary = &summary
+
+	results, err := c.GetAllScanResultsByID(scan.ScanID)
+	if err != nil {
+		return archive, fmt.Errorf("failed to get results of scan %v: %s", scan.String(), err)
+	}
+	for _, r := range results.SAST {
+		predicates, err := c.GetSASTResultsPredicatesByID(r.SimilarityID, projectID, scan.ScanID)
+		if err != nil {
+			return archive, fmt.Errorf("failed to get triage of result %v: %s", r.SimilarityID, err)
+		}
+		archive.SASTPredicates = append(archive.SASTPredicates, predicates...)
+	}
+	for _, r := range results.IAC {
+		predicates, err := c.GetIACResultsPredicatesByID(r.SimilarityID, projectID)
+		if err != nil {
+			return archive, fmt.Errorf("failed to get triage of result %v: %s", r.SimilarityID, err)
+		}
+		archive.IACPredicates = append(archive.IACPredicates, predicates...)
+	}
+
+	return archive, nil
+}
+
+// Saves the archive as JSON
+func (a ProjectArchive) Save(filename string) error {
+	data, err := json.MarshalIndent(a, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal archive of project %v: %s", a.Project.String(), err)
+	}
+	if err := os.WriteFile(filename, data, 0644); err != nil {
+		return fmt.Errorf("failed to write project archive %v: %s", filename, err)
+	}
+	return nil
+}
+
+// Loads a project archive saved with ProjectArchive.Save
+func LoadProjectArchive(filename string) (ProjectArchive, error) {
+	var archive ProjectArchive
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return archive, fmt.Errorf("failed to read project archive %v: %s", filename, err)
+	}
+	if err := json.Unmarshal(data, &archive); err != nil {
+		return archive, fmt.Errorf("failed to parse project archive %v: %s", filename, err)
+	}
+	return archive, nil
+}
+
+// Exports the project to an archive file (see GetProjectArchive) and then deletes the project.
+// The project is only deleted if the archive was written successfully.
+// There is no UNDO, the archive does not contain the scans or results themselves.
+func (c *Cx1Client) ArchiveProject(projectID, filename string) (ProjectArchive, error) {
+	archive, err := c.GetProjectArchive(projectID)
+	if err != nil {
+		return archive, err
+	}
+	if err := archive.Save(filename); err != nil {
+		return archive, err
+	}
+	c.config.Logger.Debugf("Archived project %v to %v", archive.Project.String(), filename)
+	return archive, c.DeleteProject(&archive.Project)
+}
+
+// Creates a new project with the same groups, tags, applications, repository, criticality, project-level configuration and scan schedules as the source project.
+// Scans and results are not copied.
+func (c *Cx1Client) CloneProject(source Project, name string) (Project, error) {
+	project, err := c.CreateProject(name, source.Groups, source.Tags)
+	if err != nil {
+		return project, fmt.Errorf("failed to create project %v: %s", name, err)
+	}
+
+	patch := ProjectPatch{Criticality: &source.Criticality}
+	if source.RepoUrl != "" {
+		patch.RepoUrl = &source.RepoUrl
+	}
+	if source.MainBranch != "" {
+		patch.MainBranch = &source.MainBranch
+	}
+	if err := c.PatchProjectByID(project.ProjectID, patch); err != nil {
+		return project, fmt.Errorf("failed to update project %v: %s", project.String(), err)
+	}
+
+	if source.Applications != nil && len(*source.Applications) > 0 {
+		if project, err = c.GetProjectByID(project.ProjectID); err != nil {
+			return project, fmt.Errorf("failed to get project %v: %s", name, err)
+		}
+		applicationIDs := append([]string{}, *source.Applications...)
+		project.Applications = &applicationIDs
+		if err := c.UpdateProject(&project); err != nil {
+			return project, fmt.Errorf("failed to assign project %v to applications: %s", project.String(), err)
+		}
+	}
+
+	settings, err := c.GetProjectConfigurationByID(source.ProjectID)
+	if err != nil {
+		return project, fmt.Errorf("failed to get configuration of project %v: %s", source.String(), err)
+	}
+	overrides := []ConfigurationSetting{}
+	for _, s := range settings {
+		if s.OriginLevel == "Project" {
+			overrides = append(overrides, ConfigurationSetting{Key: s.Key, Value: s.Value, AllowOverride: s.AllowOverride})
+		}
+	}
+	if len(overrides) > 0 {
+		if err := c.UpdateProjectConfigurationByID(project.ProjectID, overrides); err != nil {
+			return project, fmt.Errorf("failed to update configuration of project %v: %s", project.String(), err)
+		}
+	}
+
+	schedules, err := c.GetScanSchedulesByID(source.ProjectID)
+	if err != nil {
+		return project, fmt.Errorf("failed to get scan schedules of project %v: %s", source.String(), err)
+	}
+	for _, s := range schedules {
+		if err := c.CreateScanScheduleByID(project.ProjectID, s); err != nil {
+			return project, fmt.Errorf("failed to create scan schedule for project %v: %s", project.String(), err)
+		}
+	}
+
+	return c.GetProjectByID(project.ProjectID)
+}
+
+func (p StaleProject) String() string {
+	if p.Empty {
+		return fmt.Sprintf("[%v] %v: never scanned", ShortenGUID(p.ProjectID), p.Name)
+	}
+	return fmt.Sprintf("[%v] %v: last scanned %v on branch %v", ShortenGUID(p.ProjectID), p.Name, p.LastScanDate.Format(time.DateOnly), p.LastScanBranch)
+}
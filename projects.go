@@ -353,6 +353,26 @@ func (c *Cx1Client) GetTenantConfiguration() ([]ConfigurationSetting, error) {
 	return tenantConfigurations, err
 }
 
+// update the tenant's configuration
+func (c *Cx1Client) UpdateTenantConfiguration(settings []ConfigurationSetting) error {
+	if len(settings) == 0 {
+		return fmt.Errorf("empty list of settings provided")
+	}
+
+	jsonBody, err := json.Marshal(settings)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.sendRequest(http.MethodPatch, "/configuration/tenant", bytes.NewReader(jsonBody), nil)
+	if err != nil {
+		c.config.Logger.Tracef("Failed to update tenant configuration: %s", err)
+		return err
+	}
+
+	return nil
+}
+
 // return the configuration settings for scans set on the project level
 // this will list default configurations like presets, incremental scan settings etc if set
 func (c *Cx1Client) GetProjectConfigurationByID(projectID string) ([]ConfigurationSetting, error) {
@@ -379,10 +399,18 @@ func (c *Cx1Client) UpdateProjectConfiguration(project *Project, settings []Conf
 }
 
 // update the project's configuration
+// the settings are validated against the client's configuration catalogue before sending, see RefreshConfigurationCatalogue
 func (c *Cx1Client) UpdateProjectConfigurationByID(projectID string, settings []ConfigurationSetting) error {
 	if len(settings) == 0 {
 		return fmt.Errorf("empty list of settings provided")
 	}
+	catalogue := c.GetConfigurationCatalogue()
+	if err := catalogue.ValidateSettings(settings); err != nil {
+		return err
+	}
+	for _, warning := range catalogue.Warnings(settings) {
+		c.config.Logger.Warnf("Project %v configuration: %v", ShortenGUID(projectID), warning)
+	}
 
 	params := url.Values{
 		"project-id": {projectID},
@@ -0,0 +1,280 @@
+package Cx1ClientGo
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
+	"time"
+)
+
+var reportBatchUnsafeChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// status checks of a report which may fail in a row before the attempt is failed, eg: if the report was deleted
+const reportBatchMaxStatusErrors = 5
+
+type reportBatchJob struct {
+	index        int
+	started      time.Time
+	statusErrors int
+}
+
+// Generates one report per entity and saves them to options.OutputDir, together with a JSON manifest of the results.
+// At most options.Concurrency reports are generating at any time, and all outstanding reports are polled together
+// using the client's report polling settings. Reports which fail to generate or download are requested again up to options.Retries times,
+// with an exponential backoff starting at options.RetryDelaySeconds.
+// The request is validated once per entity type, and entities of a type with an invalid request fail without being requested.
+// Per-entity failures are returned in the results, the error is only set if the batch could not run or ctx was cancelled.
+func (c *Cx1Client) GenerateReportBatch(ctx context.Context, entities []ReportBatchEntity, options ReportBatchOptions) ([]ReportBatchResult, error) {
+	manifest := ReportBatchManifest{
+		Started: time.Now(),
+		Format:  options.Request.Format,
+		Results: make([]ReportBatchResult, len(entities)),
+	}
+	for i, e := range entities {
+		manifest.Results[i].Entity = e
+	}
+
+	if options.NameTemplate == "" {
+		options.NameTemplate = "{{.EntityType}}-{{.Name}}.{{.Format}}"
+	}
+	if options.Concurrency <= 0 {
+		options.Concurrency = 5
+	}
+	if options.Manifest == "" {
+		options.Manifest = "manifest.json"
+	}
+	if options.RetryDelaySeconds <= 0 {
+		options.RetryDelaySeconds = c.config.Polling.ReportPollingDelaySeconds
+	}
+
+	nameTemplate, err := template.New("name").Parse(options.NameTemplate)
+	if err != nil {
+		return manifest.Results, fmt.Errorf("failed to parse report name template: %s", err)
+	}
+	if err = os.MkdirAll(options.OutputDir, 0755); err != nil {
+		return manifest.Results, fmt.Errorf("failed to create report output directory %v: %s", options.OutputDir, err)
+	}
+
+	// the request for each entity type, or the error if it is not valid for that type
+	requests := map[string]ReportRequest{}
+	invalid := map[string]error{}
+	queue := []int{}
+	for i, e := range entities {
+		if _, ok := requests[e.EntityType]; !ok && invalid[e.EntityType] == nil {
+			req := options.Request
+			req.EntityType = e.EntityType
+			if sections, ok := options.Sections[e.EntityType]; ok {
+				req.Sections = sections
+			}
+			req.IDs = []string{e.ID}
+			if err := req.Validate(); err != nil {
+				c.config.Logger.Warnf("Reports for %v entities will not be generated: %s", e.EntityType, err)
+				invalid[e.EntityType] = err
+			} else {
+				requests[e.EntityType] = req
+			}
+		}
+		if err := invalid[e.EntityType]; err != nil {
+			manifest.Results[i].Error = err.Error()
+			continue
+		}
+		queue = append(queue, i)
+	}
+
+	inflight := map[string]reportBatchJob{}
+	retryAt := map[int]time.Time{}
+	fileNames := map[string]bool{}
+
+	// records a failed attempt and queues the entity again, after a backoff, if it has retries left
+	fail := func(index int, err error) {
+		result := &manifest.Results[index]
+		result.Error = err.Error()
+		if result.Attempts <= options.Retries {
+			backoff := time.Duration(options.RetryDelaySeconds) * time.Second << (result.Attempts - 1)
+			c.config.Logger.Debugf("Report for %v %v failed on attempt %d, retrying in %v: %s", result.Entity.EntityType, result.Entity.Name, result.Attempts, backoff, err)
+			retryAt[index] = time.Now().Add(backoff)
+			queue = append(queue, index)
+		} else {
+			c.config.Logger.Warnf("Report for %v %v failed after %d attempts: %s", result.Entity.EntityType, result.Entity.Name, result.Attempts, err)
+		}
+	}
+
+	cancelled := func() ([]ReportBatchResult, error) {
+		manifest.Finished = time.Now()
+		_ = manifest.save(filepath.Join(options.OutputDir, options.Manifest))
+		return manifest.Results, fmt.Errorf("report batch cancelled with %d reports outstanding: %s", len(inflight)+len(queue), ctx.Err())
+	}
+
+	delay := max(time.Duration(c.config.Polling.ReportPollingDelaySeconds)*time.Second, time.Second)
+	maxDuration := time.Duration(c.config.Polling.ReportPollingMaxSeconds) * time.Second
+
+	for len(queue) > 0 || len(inflight) > 0 {
+		// entities waiting for a retry keep their place at the end of the queue until their backoff has passed
+		waiting := []int{}
+		for len(queue) > 0 && len(inflight) < options.Concurrency {
+			index := queue[0]
+			queue = queue[1:]
+			if time.Now().Before(retryAt[index]) {
+				waiting = append(waiting, index)
+				continue
+			}
+			if ctx.Err() != nil {
+				queue = append(append(queue, index), waiting...)
+				return cancelled()
+			}
+
+			result := &manifest.Results[index]
+			result.Attempts++
+			result.Error = ""
+
+			req := requests[result.Entity.EntityType]
+			req.IDs = []string{result.Entity.ID}
+			reportID, err := c.RequestNewReport(req)
+			if err != nil {
+				fail(index, err)
+				continue
+			}
+			result.ReportID = reportID
+			inflight[reportID] = reportBatchJob{index: index, started: time.Now()}
+		}
+		queue = append(queue, waiting...)
+
+		for reportID, job := range inflight {
+			result := &manifest.Results[job.index]
+			status, err := c.GetReportStatusByID(reportID)
+			if err != nil {
+				job.statusErrors++
+				switch {
+				case job.statusErrors >= reportBatchMaxStatusErrors:
+					delete(inflight, reportID)
+					fail(job.index, fmt.Errorf("failed to get status for report %v %d times: %s", reportID, job.statusErrors, err))
+				case maxDuration != 0 && time.Since(job.started) > maxDuration:
+					delete(inflight, reportID)
+					fail(job.index, fmt.Errorf("report %v polling reached %v, aborting: %s", reportID, maxDuration, err))
+				default:
+					c.config.Logger.Debugf("Failed to get status for report %v, will check again: %s", reportID, err)
+					inflight[reportID] = job
+				}
+				continue
+			}
+			job.statusErrors = 0
+			inflight[reportID] = job
+
+			switch status.Status {
+			case "completed":
+				delete(inflight, reportID)
+				if err := c.saveBatchReport(ctx, nameTemplate, options, status.ReportURL, result, fileNames); err != nil {
+					fail(job.index, err)
+				}
+			case "failed":
+				delete(inflight, reportID)
+				fail(job.index, fmt.Errorf("report %v generation failed", reportID))
+			default:
+				if maxDuration != 0 && time.Since(job.started) > maxDuration {
+					delete(inflight, reportID)
+					fail(job.index, fmt.Errorf("report %v polling reached %v, aborting", reportID, maxDuration))
+				}
+			}
+		}
+
+		wait := delay
+		if len(inflight) == 0 {
+			if len(queue) == 0 {
+				continue
+			}
+			// only retries are left, wait for the first of them
+			wait = time.Until(retryAt[queue[0]])
+			for _, index := range queue {
+				wait = min(wait, time.Until(retryAt[index]))
+			}
+			if wait <= 0 {
+				continue
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return cancelled()
+		case <-time.After(wait):
+		}
+	}
+
+	manifest.Finished = time.Now()
+	if err = manifest.save(filepath.Join(options.OutputDir, options.Manifest)); err != nil {
+		return manifest.Results, fmt.Errorf("failed to write report batch manifest: %s", err)
+	}
+	return manifest.Results, nil
+}
+
+// Convenience function to create a batch entity list for applications
+func NewReportBatchApplicationEntities(applications []Application) []ReportBatchEntity {
+	entities := make([]ReportBatchEntity, len(applications))
+	for i, a := range applications {
+		entities[i] = ReportBatchEntity{EntityType: ReportEntityTypes.Application, ID: a.ApplicationID, Name: a.Name}
+	}
+	return entities
+}
+
+// Convenience function to create a batch entity list for projects
+func NewReportBatchProjectEntities(projects []Project) []ReportBatchEntity {
+	entities := make([]ReportBatchEntity, len(projects))
+	for i, p := range projects {
+		entities[i] = ReportBatchEntity{EntityType: ReportEntityTypes.Project, ID: p.ProjectID, Name: p.Name}
+	}
+	return entities
+}
+
+func (c *Cx1Client) saveBatchReport(ctx context.Context, nameTemplate *template.Template, options ReportBatchOptions, reportURL string, result *ReportBatchResult, fileNames map[string]bool) error {
+	var name bytes.Buffer
+	err := nameTemplate.Execute(&name, map[string]string{
+		"EntityType": result.Entity.EntityType,
+		"ID":         result.Entity.ID,
+		"Name":       result.Entity.Name,
+		"Date":       time.Now().Format("2006-01-02"),
+		"Format":     strings.ToLower(options.Request.Format),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to generate file name: %s", err)
+	}
+
+	// entities with the same name would overwrite each other, so later ones get the ID added
+	fileName := reportBatchUnsafeChars.ReplaceAllString(name.String(), "_")
+	if fileNames[fileName] {
+		ext := filepath.Ext(fileName)
+		fileName = fmt.Sprintf("%v-%v%v", strings.TrimSuffix(fileName, ext), ShortenGUID(result.Entity.ID), ext)
+	}
+	path := filepath.Join(options.OutputDir, fileName)
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create %v: %s", path, err)
+	}
+
+	size, err := c.downloadReportTo(ctx, reportURL, file)
+	if cerr := file.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(path)
+		return fmt.Errorf("failed to download report %v: %s", result.ReportID, err)
+	}
+
+	fileNames[fileName] = true
+	result.FileName = fileName
+	result.Size = size
+	result.Error = ""
+	return nil
+}
+
+func (m ReportBatchManifest) save(filename string) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filename, data, 0644)
+}
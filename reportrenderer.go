@@ -0,0 +1,375 @@
+package Cx1ClientGo
+
+import (
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"sort"
+	"strings"
+	texttemplate "text/template"
+	"time"
+)
+
+var ScanReportFormats = struct {
+	HTML     string
+	Markdown string
+}{"html", "markdown"}
+
+// Functions available to scan report templates, in addition to the standard template functions
+var ScanReportTemplateFuncs = map[string]interface{}{
+	"lower": strings.ToLower,
+	"upper": strings.ToUpper,
+	"join":  strings.Join,
+	"short": ShortenGUID,
+	"date": func(t time.Time) string {
+		return t.Format("2006-01-02 15:04 MST")
+	},
+	"percent": func(part, total uint64) string {
+		if total == 0 {
+			return "0%"
+		}
+		return fmt.Sprintf("%.0f%%", float64(part)*100/float64(total))
+	},
+	"first": func(n int, queries []ScanReportQuery) []ScanReportQuery {
+		if n < len(queries) {
+			return queries[:n]
+		}
+		return queries
+	},
+	"md": func(text string) string { // escape text for use in a markdown table cell
+		return strings.NewReplacer("|", "\\|", "\n", " ", "\r", "").Replace(text)
+	},
+}
+
+// Default template used by RenderScanReport for the HTML format, can be used as a starting point for custom templates
+const DefaultScanReportHTMLTemplate = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Scan report: {{ .Scan.ProjectName }} {{ .Scan.Branch }}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; color: #222; }
+table { border-collapse: collapse; margin-bottom: 1.5em; }
+th, td { border: 1px solid #ccc; padding: 4px 10px; text-align: left; }
+th { background: #f0f0f0; }
+td.num { text-align: right; }
+.critical { color: #7a0000; font-weight: bold; }
+.high { color: #c00000; }
+.medium { color: #d07000; }
+.low { color: #b0a000; }
+</style>
+</head>
+<body>
+<h1>Scan report: {{ .Scan.ProjectName }}</h1>
+
+<h2>Executive summary</h2>
+<table>
+<tr><th>Project</th><td>{{ .Scan.ProjectName }}</td></tr>
+<tr><th>Branch</th><td>{{ .Scan.Branch }}</td></tr>
+<tr><th>Scan</th><td>{{ .Scan.ScanID }} ({{ .Scan.Status }})</td></tr>
+<tr><th>Scanned</th><td>{{ date .Scan.CreatedAt }}</td></tr>
+<tr><th>Engines</th><td>{{ join .Scan.Engines ", " }}</td></tr>
+{{- if .Metadata.PresetName }}
+<tr><th>Preset</th><td>{{ .Metadata.PresetName }}</td></tr>
+{{- end }}
+{{- if .Metadata.LOC }}
+<tr><th>Lines of code</th><td>{{ .Metadata.LOC }} in {{ .Metadata.FileCount }} files{{ if .Metadata.IsIncremental }} (incremental){{ end }}</td></tr>
+{{- end }}
+{{- if or .Summary.SASTCounters.FilesScannedCounter .Summary.IACCounters.FilesScannedCounter }}
+<tr><th>Files scanned</th><td>{{ .Summary.SASTCounters.FilesScannedCounter }} SAST, {{ .Summary.IACCounters.FilesScannedCounter }} IaC</td></tr>
+{{- end }}
+<tr><th>Findings</th><td>{{ .Total.Total }} total, {{ .Total.New }} new ({{ percent .Total.New .Total.Total }})</td></tr>
+<tr><th>By severity</th><td><span class="critical">{{ .Total.Critical }} critical</span>, <span class="high">{{ .Total.High }} high</span>, <span class="medium">{{ .Total.Medium }} medium</span>, <span class="low">{{ .Total.Low }} low</span>, {{ .Total.Info }} info</td></tr>
+</table>
+
+<h2>Findings by engine</h2>
+<table>
+<tr><th>Engine</th><th>Total</th><th>New</th><th>Recurrent</th><th>Critical</th><th>High</th><th>Medium</th><th>Low</th><th>Info</th></tr>
+{{- range .Engines }}
+<tr><td>{{ .Name }}</td><td class="num">{{ .Total }}</td><td class="num">{{ .New }}</td><td class="num">{{ .Recurrent }}</td><td class="num critical">{{ .Critical }}</td><td class="num high">{{ .High }}</td><td class="num medium">{{ .Medium }}</td><td class="num low">{{ .Low }}</td><td class="num">{{ .Info }}</td></tr>
+{{- end }}
+</table>
+
+<h2>Top queries</h2>
+<table>
+<tr><th>Engine</th><th>Language</th><th>Query</th><th>Severity</th><th>Findings</th><th>New</th></tr>
+{{- range first 10 .TopQueries }}
+<tr><td>{{ .Engine }}</td><td>{{ .Language }}</td><td>{{ .Name }}</td><td class="{{ lower .Severity }}">{{ .Severity }}</td><td class="num">{{ .Count }}</td><td class="num">{{ .New }}</td></tr>
+{{- end }}
+</table>
+
+<h2>New vs recurrent</h2>
+<table>
+<tr><th>New</th><td class="num">{{ .Total.New }}</td><td class="num">{{ percent .Total.New .Total.Total }}</td></tr>
+<tr><th>Recurrent</th><td class="num">{{ .Total.Recurrent }}</td><td class="num">{{ percent .Total.Recurrent .Total.Total }}</td></tr>
+</table>
+
+{{- if .Metrics.SuccessfullLocPerLanguage }}
+<h2>SAST coverage</h2>
+<table>
+<tr><th>Language</th><th>Scanned LOC</th><th>Failed LOC</th><th>Good files</th><th>Partially good files</th><th>Bad files</th></tr>
+{{- $metrics := .Metrics }}
+{{- range $language, $loc := .Metrics.SuccessfullLocPerLanguage }}
+<tr><td>{{ $language }}</td><td class="num">{{ $loc }}</td><td class="num">{{ index $metrics.FailedLocPerLanguage $language }}</td>{{ with index $metrics.ScannedFilesPerLanguage $language }}<td class="num">{{ .GoodFiles }}</td><td class="num">{{ .PartiallyGoodFiles }}</td><td class="num">{{ .BadFiles }}</td>{{ end }}</tr>
+{{- end }}
+</table>
+{{- end }}
+
+<h2>Triage</h2>
+<table>
+<tr><th>State</th><th>Findings</th><th></th></tr>
+{{- $total := .Total.Total }}
+{{- range .Triage }}
+<tr><td>{{ .Name }}</td><td class="num">{{ .Count }}</td><td class="num">{{ percent .Count $total }}</td></tr>
+{{- end }}
+</table>
+
+<p><small>Generated {{ date .GeneratedAt }}</small></p>
+</body>
+</html>
+`
+
+// Default template used by RenderScanReport for the Markdown format, eg: for pull request comments
+const DefaultScanReportMarkdownTemplate = `# Scan report: {{ md .Scan.ProjectName }}
+
+## Executive summary
+
+| | |
+|---|---|
+| Project | {{ md .Scan.ProjectName }} |
+| Branch | {{ md .Scan.Branch }} |
+| Scan | {{ .Scan.ScanID }} ({{ .Scan.Status }}) |
+| Scanned | {{ date .Scan.CreatedAt }} |
+| Engines | {{ join .Scan.Engines ", " }} |
+{{- if .Metadata.PresetName }}
+| Preset | {{ md .Metadata.PresetName }} |
+{{- end }}
+{{- if .Metadata.LOC }}
+| Lines of code | {{ .Metadata.LOC }} in {{ .Metadata.FileCount }} files{{ if .Metadata.IsIncremental }} (incremental){{ end }} |
+{{- end }}
+{{- if or .Summary.SASTCounters.FilesScannedCounter .Summary.IACCounters.FilesScannedCounter }}
+| Files scanned | {{ .Summary.SASTCounters.FilesScannedCounter }} SAST, {{ .Summary.IACCounters.FilesScannedCounter }} IaC |
+{{- end }}
+| Findings | {{ .Total.Total }} total, {{ .Total.New }} new ({{ percent .Total.New .Total.Total }}) |
+| By severity | {{ .Total.Critical }} critical, {{ .Total.High }} high, {{ .Total.Medium }} medium, {{ .Total.Low }} low, {{ .Total.Info }} info |
+
+## Findings by engine
+
+| Engine | Total | New | Recurrent | Critical | High | Medium | Low | Info |
+|---|--:|--:|--:|--:|--:|--:|--:|--:|
+{{- range .Engines }}
+| {{ .Name }} | {{ .Total }} | {{ .New }} | {{ .Recurrent }} | {{ .Critical }} | {{ .High }} | {{ .Medium }} | {{ .Low }} | {{ .Info }} |
+{{- end }}
+
+## Top queries
+
+| Engine | Language | Query | Severity | Findings | New |
+|---|---|---|---|--:|--:|
+{{- range first 10 .TopQueries }}
+| {{ .Engine }} | {{ md .Language }} | {{ md .Name }} | {{ .Severity }} | {{ .Count }} | {{ .New }} |
+{{- end }}
+
+## New vs recurrent
+
+| Status | Findings | |
+|---|--:|--:|
+| New | {{ .Total.New }} | {{ percent .Total.New .Total.Total }} |
+| Recurrent | {{ .Total.Recurrent }} | {{ percent .Total.Recurrent .Total.Total }} |
+
+{{ if .Metrics.SuccessfullLocPerLanguage -}}
+## SAST coverage
+
+| Language | Scanned LOC | Failed LOC | Good files | Partially good files | Bad files |
+|---|--:|--:|--:|--:|--:|
+{{- $metrics := .Metrics }}
+{{- range $language, $loc := .Metrics.SuccessfullLocPerLanguage }}
+| {{ md $language }} | {{ $loc }} | {{ index $metrics.FailedLocPerLanguage $language }} |{{ with index $metrics.ScannedFilesPerLanguage $language }} {{ .GoodFiles }} | {{ .PartiallyGoodFiles }} | {{ .BadFiles }} |{{ end }}
+{{- end }}
+
+{{ end -}}
+## Triage
+
+| State | Findings | |
+|---|--:|--:|
+{{- $total := .Total.Total }}
+{{- range .Triage }}
+| {{ .Name }} | {{ .Count }} | {{ percent .Count $total }} |
+{{- end }}
+
+_Generated {{ date .GeneratedAt }}_
+`
+
+// Retrieves the scan, summary, metadata, metrics and results needed for a local scan report
+// Metadata and metrics are only available for scans including SAST, failures to retrieve them are logged and ignored
+func (c *Cx1Client) GetScanReportDataByID(scanID string) (ScanReportData, error) {
+	scan, err := c.GetScanByID(scanID)
+	if err != nil {
+		return ScanReportData{}, err
+	}
+
+	summary, err := c.GetScanSummaryByID(scanID)
+	if err != nil {
+		return ScanReportData{}, err
+	}
+
+	results, err := c.GetAllScanResultsByID(scanID)
+	if err != nil {
+		return ScanReportData{}, err
+	}
+
+	metadata, err := c.GetScanMetadataByID(scanID)
+	if err != nil {
+		c.config.Logger.Debugf("Failed to get metadata for scan %v, report will not include it: %s", scanID, err)
+	}
+	metrics, err := c.GetScanMetricsByID(scanID)
+	if err != nil {
+		c.config.Logger.Debugf("Failed to get metrics for scan %v, report will not include it: %s", scanID, err)
+	}
+
+	return NewScanReportData(scan, summary, metadata, metrics, results), nil
+}
+
+// Builds report data from previously-retrieved scan information and calculates the per-engine, per-query and triage breakdowns
+func NewScanReportData(scan Scan, summary ScanSummary, metadata ScanMetadata, metrics ScanMetrics, results ScanResultSet) ScanReportData {
+	data := ScanReportData{
+		Scan:        scan,
+		Summary:     summary,
+		Metadata:    metadata,
+		Metrics:     metrics,
+		Results:     results,
+		GeneratedAt: time.Now(),
+		Total:       ScanReportEngine{Name: "Total"},
+	}
+
+	queries := map[string]*ScanReportQuery{}
+	triage := map[string]uint64{}
+
+	count := func(engine *ScanReportEngine, r ScanResultBase, language, query string) {
+		for _, e := range []*ScanReportEngine{engine, &data.Total} {
+			e.Total++
+			switch strings.ToUpper(r.Status) {
+			case "NEW":
+				e.New++
+			case "RECURRENT":
+				e.Recurrent++
+			}
+			switch strings.ToUpper(r.Severity) {
+			case "CRITICAL":
+				e.Critical++
+			case "HIGH":
+				e.High++
+			case "MEDIUM":
+				e.Medium++
+			case "LOW":
+				e.Low++
+			default:
+				e.Info++
+			}
+		}
+
+		triage[r.State]++
+
+		key := strings.Join([]string{engine.Name, language, query, r.Severity}, "|")
+		q, ok := queries[key]
+		if !ok {
+			q = &ScanReportQuery{Engine: engine.Name, Language: language, Name: query, Severity: r.Severity}
+			queries[key] = q
+		}
+		q.Count++
+		if strings.EqualFold(r.Status, "NEW") {
+			q.New++
+		}
+	}
+
+	if len(results.SAST) > 0 {
+		engine := ScanReportEngine{Name: "SAST"}
+		for _, r := range results.SAST {
+			count(&engine, r.ScanResultBase, r.Data.LanguageName, r.Data.QueryName)
+		}
+		data.Engines = append(data.Engines, engine)
+	}
+	if len(results.IAC) > 0 {
+		engine := ScanReportEngine{Name: "IAC"}
+		for _, r := range results.IAC {
+			count(&engine, r.ScanResultBase, r.Data.Platform, r.Data.QueryName)
+		}
+		data.Engines = append(data.Engines, engine)
+	}
+	if len(results.SCA) > 0 {
+		engine := ScanReportEngine{Name: "SCA"}
+		for _, r := range results.SCA {
+			manager, name, version := r.Data.ParsePackageIdentifier()
+			count(&engine, r.ScanResultBase, manager, fmt.Sprintf("%v %v", name, version))
+		}
+		data.Engines = append(data.Engines, engine)
+	}
+	if len(results.SCAContainer) > 0 {
+		engine := ScanReportEngine{Name: "SCA Containers"}
+		for _, r := range results.SCAContainer {
+			count(&engine, r.ScanResultBase, "", fmt.Sprintf("%v %v", r.Data.PackageName, r.Data.PackageVersion))
+		}
+		data.Engines = append(data.Engines, engine)
+	}
+	if len(results.Containers) > 0 {
+		engine := ScanReportEngine{Name: "Containers"}
+		for _, r := range results.Containers {
+			count(&engine, r.ScanResultBase, r.Data.ImageName, fmt.Sprintf("%v %v", r.Data.PackageName, r.Data.PackageVersion))
+		}
+		data.Engines = append(data.Engines, engine)
+	}
+
+	for _, q := range queries {
+		data.TopQueries = append(data.TopQueries, *q)
+	}
+	sort.Slice(data.TopQueries, func(i, j int) bool {
+		a, b := data.TopQueries[i], data.TopQueries[j]
+		if GetSeverityID(a.Severity) != GetSeverityID(b.Severity) {
+			return GetSeverityID(a.Severity) > GetSeverityID(b.Severity)
+		}
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		return a.Name < b.Name
+	})
+
+	for state, count := range triage {
+		data.Triage = append(data.Triage, ScanReportCount{Name: state, Count: count})
+	}
+	sort.Slice(data.Triage, func(i, j int) bool {
+		if data.Triage[i].Count != data.Triage[j].Count {
+			return data.Triage[i].Count > data.Triage[j].Count
+		}
+		return data.Triage[i].Name < data.Triage[j].Name
+	})
+
+	return data
+}
+
+// Renders the report with the default template for the format (ScanReportFormats.HTML or ScanReportFormats.Markdown)
+func RenderScanReport(w io.Writer, data ScanReportData, format string) error {
+	switch strings.ToLower(format) {
+	case ScanReportFormats.HTML:
+		return RenderScanReportTemplate(w, data, DefaultScanReportHTMLTemplate, format)
+	case ScanReportFormats.Markdown, "md":
+		return RenderScanReportTemplate(w, data, DefaultScanReportMarkdownTemplate, format)
+	}
+	return fmt.Errorf("unknown report format %v", format)
+}
+
+// Renders the report with a user-supplied template, see DefaultScanReportHTMLTemplate and ScanReportTemplateFuncs
+// HTML templates use html/template and are escaped, any other format uses text/template
+func RenderScanReportTemplate(w io.Writer, data ScanReportData, templateText, format string) error {
+	if strings.EqualFold(format, ScanReportFormats.HTML) {
+		tmpl, err := htmltemplate.New("report").Funcs(htmltemplate.FuncMap(ScanReportTemplateFuncs)).Parse(templateText)
+		if err != nil {
+			return fmt.Errorf("failed to parse report template: %s", err)
+		}
+		return tmpl.Execute(w, data)
+	}
+
+	tmpl, err := texttemplate.New("report").Funcs(texttemplate.FuncMap(ScanReportTemplateFuncs)).Parse(templateText)
+	if err != nil {
+		return fmt.Errorf("failed to parse report template: %s", err)
+	}
+	return tmpl.Execute(w, data)
+}
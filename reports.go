@@ -2,13 +2,71 @@ package Cx1ClientGo
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"strings"
 	"time"
+
+	"golang.org/x/exp/slices"
 )
 
+var ReportEntityTypes = struct {
+	Scan        string
+	Project     string
+	Application string
+}{"scan", "project", "application"}
+
+var ReportSections = struct {
+	ScanInformation              string
+	ResultsOverview              string
+	ScanResults                  string
+	Categories                   string
+	ResolvedResults              string
+	VulnerabilityDetails         string
+	ProjectsOverview             string
+	TotalVulnerabilitiesOverview string
+	VulnerabilitiesInsights      string
+}{"scan-information", "results-overview", "scan-results", "categories", "resolved-results", "vulnerability-details", "projects-overview", "total-vulnerabilities-overview", "vulnerabilities-insights"}
+
+var ReportScanners = struct {
+	SAST         string
+	SCA          string
+	IAC          string
+	Containers   string
+	Microengines string
+}{"sast", "sca", "kics", "containers", "microengines"}
+
+var ReportSeverities = struct {
+	Critical string
+	High     string
+	Medium   string
+	Low      string
+	Info     string
+}{"critical", "high", "medium", "low", "info"}
+
+var ReportStates = struct {
+	ToVerify               string
+	NotExploitable         string
+	ProposedNotExploitable string
+	Confirmed              string
+	Urgent                 string
+}{"to-verify", "not-exploitable", "proposed-not-exploitable", "confirmed", "urgent"}
+
+var ReportStatuses = struct {
+	New       string
+	Recurrent string
+	Fixed     string
+}{"new", "recurrent", "fixed"}
+
+var ReportFormats = struct {
+	PDF  string
+	CSV  string
+	JSON string
+}{"pdf", "csv", "json"}
+
 // Reports
 // Added the 'sections' variable, originally: "ScanSummary", "ExecutiveSummary", "ScanResults",
 func (c *Cx1Client) RequestNewReportByID(scanID, projectID, branch, reportType string, engines, sections []string) (string, error) {
@@ -53,69 +111,132 @@ func (c *Cx1Client) RequestNewReportByIDv2(scanID string, scanners []string, for
 }
 
 func (c *Cx1Client) RequestNewReportByScanIDv2(scanID string, scanners, emails, tags []string, format string) (string, error) {
-	severities := []string{"high", "medium"}
-	if flag, _ := c.CheckFlag("CVSS_V3_ENABLED"); flag {
-		severities = append(severities, "critical")
-	}
-	return c.RequestNewReportByIDsv2(
-		"scan",
-		[]string{scanID},
-		[]string{"scan-information", "results-overview", "scan-results", "categories", "resolved-results", "vulnerability-details"},
-		scanners,
-		severities,
-		[]string{"to-verify", "confirmed", "urgent"},
-		[]string{"new", "recurrent"},
-		emails,
-		tags,
-		format)
+	req := c.NewReportRequest(ReportEntityTypes.Scan, []string{scanID}, format)
+	req.Scanners = scanners
+	req.Emails = emails
+	req.Tags = tags
+	return c.RequestNewReport(req)
 }
 
 func (c *Cx1Client) RequestNewReportByProjectIDv2(projectIDs, scanners, emails, tags []string, format string) (string, error) {
-	severities := []string{"high", "medium"}
-	if flag, _ := c.CheckFlag("CVSS_V3_ENABLED"); flag {
-		severities = append(severities, "critical")
-	}
-	return c.RequestNewReportByIDsv2(
-		"project",
-		projectIDs,
-		[]string{"projects-overview", "total-vulnerabilities-overview", "vulnerabilities-insights"},
-		scanners,
-		severities,
-		[]string{"to-verify", "confirmed", "urgent"},
-		[]string{"new", "recurrent"},
-		emails,
-		tags,
-		format)
+	req := c.NewReportRequest(ReportEntityTypes.Project, projectIDs, format)
+	req.Scanners = scanners
+	req.Emails = emails
+	req.Tags = tags
+	return c.RequestNewReport(req)
 }
 
 // function used by RequestNewReportByIDv2
 func (c *Cx1Client) RequestNewReportByIDsv2(entityType string, ids, sections, scanners, severities, states, statuses, emails, tags []string, format string) (string, error) {
+	return c.RequestNewReport(ReportRequest{
+		EntityType: entityType,
+		IDs:        ids,
+		Tags:       tags,
+		Sections:   sections,
+		Scanners:   scanners,
+		Severities: severities,
+		States:     states,
+		Statuses:   statuses,
+		Emails:     emails,
+		Format:     format,
+	})
+}
+
+func (c *Cx1Client) GetReportStatusByID(reportID string) (ReportStatus, error) {
+	var response ReportStatus
+
+	data, err := c.sendRequest(http.MethodGet, fmt.Sprintf("/reports/%v?returnUrl=true", reportID), nil, nil)
+	if err != nil {
+		c.config.Logger.Tracef("Failed to fetch report status for reportID %v: %s", reportID, err)
+		return response, fmt.Errorf("failed to fetch report status for reportID %v: %s", reportID, err)
+	}
+
+	err = json.Unmarshal([]byte(data), &response)
+	return response, err
+}
+
+func (c *Cx1Client) DownloadReport(reportUrl string) ([]byte, error) {
+	data, err := c.sendRequestInternal(http.MethodGet, reportUrl, nil, nil)
+	if err != nil {
+		return []byte{}, fmt.Errorf("failed to download report from url %v: %s", reportUrl, err)
+	}
+	return data, nil
+}
+
+// convenience function, polls and returns the URL to download the report
+func (c *Cx1Client) ReportPollingByID(reportID string) (string, error) {
+	return c.ReportPollingByIDWithTimeout(reportID, c.config.Polling.ReportPollingDelaySeconds, c.config.Polling.ReportPollingMaxSeconds)
+}
+
+func (c *Cx1Client) ReportPollingByIDWithTimeout(reportID string, delaySeconds, maxSeconds int) (string, error) {
+	return c.reportPollingWithContext(context.Background(), reportID, delaySeconds, maxSeconds)
+}
+
+// Creates a v2 report request with the default sections for the entity type and filters matching the Cx1 UI defaults:
+// high & medium (and critical, if enabled) severity, to-verify/confirmed/urgent state, new & recurrent status
+// Scanners should be set before use
+func (c *Cx1Client) NewReportRequest(entityType string, ids []string, format string) ReportRequest {
+	severities := []string{ReportSeverities.High, ReportSeverities.Medium}
+	if flag, _ := c.CheckFlag("CVSS_V3_ENABLED"); flag {
+		severities = append(severities, ReportSeverities.Critical)
+	}
+
+	sections := []string{ReportSections.ScanInformation, ReportSections.ResultsOverview, ReportSections.ScanResults, ReportSections.Categories, ReportSections.ResolvedResults, ReportSections.VulnerabilityDetails}
+	if entityType != ReportEntityTypes.Scan {
+		sections = []string{ReportSections.ProjectsOverview, ReportSections.TotalVulnerabilitiesOverview, ReportSections.VulnerabilitiesInsights}
+	}
+
+	return ReportRequest{
+		EntityType: entityType,
+		IDs:        ids,
+		Tags:       []string{},
+		Sections:   sections,
+		Scanners:   []string{},
+		Severities: severities,
+		States:     []string{ReportStates.ToVerify, ReportStates.Confirmed, ReportStates.Urgent},
+		Statuses:   []string{ReportStatuses.New, ReportStatuses.Recurrent},
+		Emails:     []string{},
+		Format:     format,
+		ReportType: "ui",
+	}
+}
+
+// returns the report ID which can be passed to GetReportStatusByID or ReportPollingByID
+// The request is sent as-is, use ReportRequest.Validate to check it first
+func (c *Cx1Client) RequestNewReport(req ReportRequest) (string, error) {
+	if req.Name == "" {
+		req.Name = fmt.Sprintf("improved-%v-report", req.EntityType)
+	}
+	if req.ReportType == "" {
+		req.ReportType = "ui"
+	}
+
 	jsonData := map[string]interface{}{
-		"reportName": fmt.Sprintf("improved-%v-report", entityType),
-		"sections":   sections,
+		"reportName": req.Name,
+		"sections":   req.Sections,
 		"entities": []map[string]interface{}{
 			{
-				"entity": entityType,
-				"ids":    ids,
-				"tags":   tags,
+				"entity": req.EntityType,
+				"ids":    req.IDs,
+				"tags":   req.Tags,
 			},
 		},
 		"filters": map[string][]string{
-			"scanners":   scanners,
-			"severities": severities,
-			"states":     states,
-			"status":     statuses,
+			"scanners":   req.Scanners,
+			"severities": req.Severities,
+			"states":     req.States,
+			"status":     req.Statuses,
 		},
-		"reportType": "ui",
-		"fileFormat": format,
-		"emails":     emails,
+		"reportType": req.ReportType,
+		"fileFormat": req.Format,
+		"emails":     req.Emails,
 	}
 
 	jsonValue, _ := json.Marshal(jsonData)
 
 	data, err := c.sendRequest(http.MethodPost, "/reports/v2", bytes.NewReader(jsonValue), nil)
 	if err != nil {
-		return "", fmt.Errorf("failed to trigger report v2 generation for %v(s) %v: %s", entityType, strings.Join(ids, ","), err)
+		return "", fmt.Errorf("failed to trigger report v2 generation for %v(s) %v: %s", req.EntityType, strings.Join(req.IDs, ","), err)
 	}
 
 	var reportResponse struct {
@@ -125,33 +246,44 @@ func (c *Cx1Client) RequestNewReportByIDsv2(entityType string, ids, sections, sc
 	return reportResponse.ReportId, err
 }
 
-func (c *Cx1Client) GetReportStatusByID(reportID string) (ReportStatus, error) {
-	var response ReportStatus
+// Requests a report, polls until it is ready (using the client's report polling settings) and writes it to w without holding it in memory
+// Polling and the download stop when ctx is cancelled
+func (c *Cx1Client) GenerateReport(ctx context.Context, req ReportRequest, w io.Writer) error {
+	reportID, err := c.RequestNewReport(req)
+	if err != nil {
+		return err
+	}
+	c.config.Logger.Debugf("Requested %v report %v for %v(s) %v", req.Format, reportID, req.EntityType, strings.Join(req.IDs, ","))
 
-	data, err := c.sendRequest(http.MethodGet, fmt.Sprintf("/reports/%v?returnUrl=true", reportID), nil, nil)
+	reportURL, err := c.reportPollingWithContext(ctx, reportID, c.config.Polling.ReportPollingDelaySeconds, c.config.Polling.ReportPollingMaxSeconds)
 	if err != nil {
-		c.config.Logger.Tracef("Failed to fetch report status for reportID %v: %s", reportID, err)
-		return response, fmt.Errorf("failed to fetch report status for reportID %v: %s", reportID, err)
+		return err
 	}
 
-	err = json.Unmarshal([]byte(data), &response)
-	return response, err
+	if _, err = c.downloadReportTo(ctx, reportURL, w); err != nil {
+		return fmt.Errorf("failed to download report %v: %s", reportID, err)
+	}
+	return nil
 }
 
-func (c *Cx1Client) DownloadReport(reportUrl string) ([]byte, error) {
-	data, err := c.sendRequestInternal(http.MethodGet, reportUrl, nil, nil)
+// streams the report to w, returning the number of bytes written
+func (c *Cx1Client) downloadReportTo(ctx context.Context, reportURL string, w io.Writer) (int64, error) {
+	request, err := c.createRequest(http.MethodGet, reportURL, nil, &http.Header{}, nil)
 	if err != nil {
-		return []byte{}, fmt.Errorf("failed to download report from url %v: %s", reportUrl, err)
+		return 0, err
+	}
+	response, err := c.handleHTTPResponse(request.WithContext(ctx))
+	if response != nil && response.Body != nil {
+		defer response.Body.Close()
+	}
+	if err != nil {
+		return 0, err
 	}
-	return data, nil
-}
 
-// convenience function, polls and returns the URL to download the report
-func (c *Cx1Client) ReportPollingByID(reportID string) (string, error) {
-	return c.ReportPollingByIDWithTimeout(reportID, c.config.Polling.ReportPollingDelaySeconds, c.config.Polling.ReportPollingMaxSeconds)
+	return io.Copy(w, response.Body)
 }
 
-func (c *Cx1Client) ReportPollingByIDWithTimeout(reportID string, delaySeconds, maxSeconds int) (string, error) {
+func (c *Cx1Client) reportPollingWithContext(ctx context.Context, reportID string, delaySeconds, maxSeconds int) (string, error) {
 	pollingCounter := 0
 	for {
 		status, err := c.GetReportStatusByID(reportID)
@@ -170,11 +302,57 @@ func (c *Cx1Client) ReportPollingByIDWithTimeout(reportID string, delaySeconds,
 			return "", fmt.Errorf("report %v polling reached %d seconds, aborting - use cx1client.get/setclientvars to change", ShortenGUID(reportID), pollingCounter)
 		}
 
-		time.Sleep(time.Duration(delaySeconds) * time.Second)
+		select {
+		case <-ctx.Done():
+			return "", fmt.Errorf("report %v polling cancelled: %s", ShortenGUID(reportID), ctx.Err())
+		case <-time.After(time.Duration(delaySeconds) * time.Second):
+		}
 		pollingCounter += delaySeconds
 	}
 }
 
+// Checks that the entity type, sections, scanners, severities, statuses and format are known values and that the request has IDs or tags
+// States are not checked since tenants may define custom states
+func (r ReportRequest) Validate() error {
+	if !slices.Contains([]string{ReportEntityTypes.Scan, ReportEntityTypes.Project, ReportEntityTypes.Application}, strings.ToLower(r.EntityType)) {
+		return fmt.Errorf("invalid report entity type %v", r.EntityType)
+	}
+	if len(r.IDs) == 0 && len(r.Tags) == 0 {
+		return fmt.Errorf("report request for %v requires at least one ID or tag", r.EntityType)
+	}
+	if !slices.Contains([]string{ReportFormats.PDF, ReportFormats.CSV, ReportFormats.JSON}, strings.ToLower(r.Format)) {
+		return fmt.Errorf("invalid report format %v", r.Format)
+	}
+	if len(r.Sections) == 0 {
+		return fmt.Errorf("report request requires at least one section")
+	}
+
+	sections := []string{ReportSections.ScanInformation, ReportSections.ResultsOverview, ReportSections.ScanResults, ReportSections.Categories, ReportSections.ResolvedResults, ReportSections.VulnerabilityDetails}
+	if !strings.EqualFold(r.EntityType, ReportEntityTypes.Scan) {
+		sections = []string{ReportSections.ProjectsOverview, ReportSections.TotalVulnerabilitiesOverview, ReportSections.VulnerabilitiesInsights}
+	}
+
+	checks := []struct {
+		name    string
+		values  []string
+		allowed []string
+	}{
+		{"section", r.Sections, sections},
+		{"scanner", r.Scanners, []string{ReportScanners.SAST, ReportScanners.SCA, ReportScanners.IAC, ReportScanners.Containers, ReportScanners.Microengines}},
+		{"severity", r.Severities, []string{ReportSeverities.Critical, ReportSeverities.High, ReportSeverities.Medium, ReportSeverities.Low, ReportSeverities.Info}},
+		{"status", r.Statuses, []string{ReportStatuses.New, ReportStatuses.Recurrent, ReportStatuses.Fixed}},
+	}
+	for _, check := range checks {
+		for _, v := range check.values {
+			if !slices.ContainsFunc(check.allowed, func(a string) bool { return strings.EqualFold(a, v) }) {
+				return fmt.Errorf("invalid report %v %v for %v report, expected one of: %v", check.name, v, r.EntityType, strings.Join(check.allowed, ", "))
+			}
+		}
+	}
+
+	return nil
+}
+
 // SCA-specific Export for SBOM
 // formats: CycloneDxjson, CycloneDxxml, Spdxjson
 func (c *Cx1Client) RequestNewExportByID(scanId, format string, hidePrivatePackages, hideDevAndTestDependencies, showOnlyEffectiveLicenses bool) (string, error) {
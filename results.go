@@ -5,7 +5,11 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"regexp"
+	"sort"
 	"strings"
+	"time"
+	"unicode"
 
 	"github.com/google/go-querystring/query"
 )
@@ -245,6 +249,99 @@ func (c *Cx1Client) GetIACResultsPredicatesByID(SimilarityID string, ProjectID s
 	return Predicates.PredicateHistoryPerProject[0].Predicates, err
 }
 
+// Returns the triage history of the SCA result's package vulnerability in the project, oldest first.
+// SCA triage is read through the SCA GraphQL API rather than a results-predicates endpoint, and state changes are converted
+// to the result states, eg: NotExploitable to NOT_EXPLOITABLE. Comments without a state change are returned with an empty State.
+func (c *Cx1Client) GetSCAResultsPredicatesByID(result ScanSCAResult, ProjectID, ScanID string) ([]ResultsPredicatesBase, error) {
+	c.config.Logger.Debugf("Fetching SCA results predicates for project %v scan %v similarityId %v", ProjectID, ScanID, result.SimilarityID)
+	manager, name, version := result.Data.ParsePackageIdentifier()
+
+	body := map[string]interface{}{
+		"query": `query ($scanId: UUID!, $projectId: String, $isLatest: Boolean!, $packageName: String, $packageVersion: String, $packageManager: String, $vulnerabilityId: String) {
+			searchPackageVulnerabilityStateAndScoreActions (scanId: $scanId, projectId: $projectId, isLatest: $isLatest, packageName: $packageName, packageVersion: $packageVersion, packageManager: $packageManager, vulnerabilityId: $vulnerabilityId) {
+				actions { isComment, actionType, actionValue, enabled, createdAt, comment { message, createdOn, userName } }
+			}
+		}`,
+		"variables": map[string]interface{}{
+			"scanId":          ScanID,
+			"projectId":       ProjectID,
+			"isLatest":        false,
+			"packageName":     name,
+			"packageVersion":  version,
+			"packageManager":  manager,
+			"vulnerabilityId": result.scaVulnerabilityID(),
+		},
+	}
+	jsonBody, err := json.Marshal(body)
+	if err != nil {
+		return []ResultsPredicatesBase{}, err
+	}
+
+	response, err := c.sendRequest(http.MethodPost, "/sca/graphql/graphql", bytes.NewReader(jsonBody), nil)
+	if err != nil {
+		return []ResultsPredicatesBase{}, err
+	}
+
+	var Actions struct {
+		Data struct {
+			Search struct {
+				Actions []struct {
+					IsComment   bool      `json:"isComment"`
+					ActionType  string    `json:"actionType"`
+					ActionValue string    `json:"actionValue"`
+					Enabled     bool      `json:"enabled"`
+					CreatedAt   time.Time `json:"createdAt"`
+					Comment     *struct {
+						Message  string `json:"message"`
+						UserName string `json:"userName"`
+					} `json:"comment"`
+				} `json:"actions"`
+			} `json:"searchPackageVulnerabilityStateAndScoreActions"`
+		} `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err = json.Unmarshal(response, &Actions); err != nil {
+		return []ResultsPredicatesBase{}, err
+	}
+	if len(Actions.Errors) > 0 {
+		return []ResultsPredicatesBase{}, fmt.Errorf("failed to get SCA triage of %v: %v", result.Data.PackageIdentifier, Actions.Errors[0].Message)
+	}
+
+	predicates := []ResultsPredicatesBase{}
+	for _, a := range Actions.Data.Search.Actions {
+		if !a.Enabled {
+			continue
+		}
+		p := ResultsPredicatesBase{SimilarityID: result.SimilarityID, ProjectID: ProjectID, ScanID: ScanID, CreatedAt: a.CreatedAt}
+		if a.ActionType == "ChangeState" {
+			p.State = scaPredicateState(a.ActionValue)
+		} else if !a.IsComment {
+			continue // eg: score changes
+		}
+		if a.Comment != nil {
+			p.Comment = a.Comment.Message
+			p.CreatedBy = a.Comment.UserName
+		}
+		predicates = append(predicates, p)
+	}
+	sort.SliceStable(predicates, func(i, j int) bool { return predicates[i].CreatedAt.Before(predicates[j].CreatedAt) })
+	return predicates, nil
+}
+
+// converts the SCA state names (eg: NotExploitable) to the result states (eg: NOT_EXPLOITABLE)
+func scaPredicateState(state string) string {
+	var b strings.Builder
+	for i, r := range state {
+		if i > 0 && unicode.IsUpper(r) {
+			b.WriteRune('_')
+		}
+		b.WriteRune(unicode.ToUpper(r))
+	}
+	return b.String()
+}
+
 // convenience function
 func (p *ResultsPredicatesBase) Update(state, severity, comment string) {
 	if state != "" && state != p.State {
@@ -283,6 +380,31 @@ func (r ScanSCAResultData) GetType(packageDataType string) ScanSCAResultPackageD
 	return ScanSCAResultPackageData{}
 }
 
+var packageVersionRegex = regexp.MustCompile(`^\d+(\.\d+)*([-+.].*)?$`)
+
+// Splits the PackageIdentifier (eg: Npm-lodash-4.17.20, Maven-org.apache.logging.log4j:log4j-core-2.14.1)
+// into the package manager, package name, and version. The version starts after the last '-' which is followed by something like a version,
+// so names with digits like Npm-utf-8-validate-5.0.2 and pre-release versions like Npm-foo-1.0.0-beta are kept whole.
+// If there is no such '-', the version starts after the first '-' which is followed by a digit.
+func (r ScanSCAResultData) ParsePackageIdentifier() (manager, name, version string) {
+	id := r.PackageIdentifier
+	if idx := strings.Index(id, "-"); idx > 0 {
+		manager = id[:idx]
+		id = id[idx+1:]
+	}
+	for idx := len(id) - 2; idx > 0; idx-- {
+		if id[idx] == '-' && packageVersionRegex.MatchString(id[idx+1:]) {
+			return manager, id[:idx], id[idx+1:]
+		}
+	}
+	for idx := 1; idx < len(id)-1; idx++ {
+		if id[idx] == '-' && id[idx+1] >= '0' && id[idx+1] <= '9' {
+			return manager, id[:idx], id[idx+1:]
+		}
+	}
+	return manager, id, ""
+}
+
 func addResultStatus(summary *ScanResultStatusSummary, result *ScanSASTResult) {
 	switch result.State {
 	case "CONFIRMED":
@@ -0,0 +1,35 @@
+package Cx1ClientGo
+
+import "testing"
+
+func TestParsePackageIdentifier(t *testing.T) {
+	tests := []struct {
+		id      string
+		manager string
+		name    string
+		version string
+	}{
+		{"Npm-lodash-4.17.20", "Npm", "lodash", "4.17.20"},
+		{"Npm-utf-8-validate-5.0.2", "Npm", "utf-8-validate", "5.0.2"},
+		{"Npm-@babel/core-7.22.5", "Npm", "@babel/core", "7.22.5"},
+		{"Npm-@types/node-20.1.0", "Npm", "@types/node", "20.1.0"},
+		{"Npm-foo-1.0.0-beta", "Npm", "foo", "1.0.0-beta"},
+		{"Npm-foo-2.0.0-rc.1", "Npm", "foo", "2.0.0-rc.1"},
+		{"Npm-base64-js-1.5.1", "Npm", "base64-js", "1.5.1"},
+		{"Maven-org.apache.logging.log4j:log4j-core-2.14.1", "Maven", "org.apache.logging.log4j:log4j-core", "2.14.1"},
+		{"Maven-com.google.guava:guava-31.1-jre", "Maven", "com.google.guava:guava", "31.1-jre"},
+		{"Python-pywin32-306", "Python", "pywin32", "306"},
+		{"Nuget-Newtonsoft.Json-13.0.1+build", "Nuget", "Newtonsoft.Json", "13.0.1+build"},
+		{"Npm-foo-1a", "Npm", "foo", "1a"},
+		{"Npm-noversion", "Npm", "noversion", ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.id, func(t *testing.T) {
+			manager, name, version := ScanSCAResultData{PackageIdentifier: test.id}.ParsePackageIdentifier()
+			if manager != test.manager || name != test.name || version != test.version {
+				t.Errorf("expected %v, %v, %v, got %v, %v, %v", test.manager, test.name, test.version, manager, name, version)
+			}
+		})
+	}
+}
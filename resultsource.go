@@ -0,0 +1,277 @@
+package Cx1ClientGo
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// Downloads the source code used by a scan (via GetScanSourcesByID) and opens it as an in-memory archive
+// The archive can be used to enrich results from the same scan through ScanResultSet.AddSourceSnippets
+func (c *Cx1Client) GetScanSourceArchiveByID(scanID string) (*ScanSourceArchive, error) {
+	data, err := c.GetScanSourcesByID(scanID)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, fmt.Errorf("failed to download sources for scan %v", scanID)
+	}
+
+	return NewScanSourceArchive(data)
+}
+
+// Downloads the sources for a scan once and attaches snippets with contextLines of surrounding code to each result location
+// Results which can not be matched to a file in the archive are left without a snippet
+func (c *Cx1Client) AddScanResultSourceSnippets(scanID string, results *ScanResultSet, contextLines uint64) error {
+	c.config.Logger.Debugf("Adding source snippets with %d context lines to results for scan %v", contextLines, scanID)
+	archive, err := c.GetScanSourceArchiveByID(scanID)
+	if err != nil {
+		return err
+	}
+	defer archive.Close()
+
+	missing := results.AddSourceSnippets(archive, contextLines)
+	if missing > 0 {
+		c.config.Logger.Debugf("Unable to find source for %d result locations in scan %v", missing, scanID)
+	}
+	return nil
+}
+
+// Opens a source archive from a zip held in memory, eg: the output of GetScanSourcesByID
+func NewScanSourceArchive(data []byte) (*ScanSourceArchive, error) {
+	reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open source archive: %s", err)
+	}
+	return newScanSourceArchive(reader, nil), nil
+}
+
+// Opens a source archive from a zip file on disk, eg: a previously-saved output of GetScanSourcesByID
+// The archive should be closed when no longer needed
+func OpenScanSourceArchive(filename string) (*ScanSourceArchive, error) {
+	reader, err := zip.OpenReader(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open source archive %v: %s", filename, err)
+	}
+	return newScanSourceArchive(&reader.Reader, reader), nil
+}
+
+func newScanSourceArchive(reader *zip.Reader, closer *zip.ReadCloser) *ScanSourceArchive {
+	archive := ScanSourceArchive{
+		reader: reader,
+		files:  make(map[string]*zip.File),
+		lines:  make(map[string][]string),
+	}
+	if closer != nil {
+		archive.closer = closer
+	}
+	for _, f := range reader.File {
+		if !f.FileInfo().IsDir() {
+			archive.files[normalizeSourcePath(f.Name)] = f
+		}
+	}
+	return &archive
+}
+
+func (a *ScanSourceArchive) Close() error {
+	if a.closer != nil {
+		return a.closer.Close()
+	}
+	return nil
+}
+
+// Returns the list of files in the archive
+func (a ScanSourceArchive) GetFiles() []string {
+	files := []string{}
+	for name := range a.files {
+		files = append(files, name)
+	}
+	return files
+}
+
+// Result file names start with a / and the archive may include a top-level folder,
+// so this will first try an exact match and then the shortest archive path ending in the requested path
+func (a *ScanSourceArchive) findFile(path string) (string, *zip.File) {
+	path = normalizeSourcePath(path)
+	if path == "" {
+		return "", nil
+	}
+	if f, ok := a.files[path]; ok {
+		return path, f
+	}
+
+	var match string
+	for name := range a.files {
+		if strings.HasSuffix(name, "/"+path) && (match == "" || len(name) < len(match)) {
+			match = name
+		}
+	}
+	if match == "" {
+		return "", nil
+	}
+	return match, a.files[match]
+}
+
+// Returns the lines of a file in the archive, the first line of the file is at index 0
+func (a *ScanSourceArchive) GetFileLines(path string) ([]string, error) {
+	name, file := a.findFile(path)
+	if file == nil {
+		return []string{}, fmt.Errorf("file %v not found in source archive", path)
+	}
+	if lines, ok := a.lines[name]; ok {
+		return lines, nil
+	}
+
+	reader, err := file.Open()
+	if err != nil {
+		return []string{}, fmt.Errorf("failed to read %v from source archive: %s", name, err)
+	}
+	defer reader.Close()
+
+	lines := []string{}
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		lines = append(lines, strings.TrimRight(scanner.Text(), "\r"))
+	}
+	if err = scanner.Err(); err != nil {
+		return []string{}, fmt.Errorf("failed to read %v from source archive: %s", name, err)
+	}
+
+	a.lines[name] = lines
+	return lines, nil
+}
+
+// Returns the source at line (1-based) of the file with contextLines before and after it
+func (a *ScanSourceArchive) GetSnippet(path string, line, contextLines uint64) (*ResultSourceSnippet, error) {
+	lines, err := a.GetFileLines(path)
+	if err != nil {
+		return nil, err
+	}
+	if line == 0 || line > uint64(len(lines)) {
+		return nil, fmt.Errorf("line %d is outside of file %v with %d lines", line, path, len(lines))
+	}
+
+	snippet := ResultSourceSnippet{
+		FileName:  path,
+		Line:      line,
+		StartLine: 1,
+		EndLine:   line + contextLines,
+	}
+	if line > contextLines {
+		snippet.StartLine = line - contextLines
+	}
+	if snippet.EndLine > uint64(len(lines)) {
+		snippet.EndLine = uint64(len(lines))
+	}
+
+	for i := snippet.StartLine; i <= snippet.EndLine; i++ {
+		snippet.Lines = append(snippet.Lines, ResultSourceLine{Number: i, Text: lines[i-1]})
+	}
+
+	return &snippet, nil
+}
+
+// Returns the first line (1-based) of the file containing all of the terms, or 0 if there is none
+// Used to locate results like SCA packages and container images which do not include a line number
+func (a *ScanSourceArchive) FindLine(path string, terms ...string) uint64 {
+	lines, err := a.GetFileLines(path)
+	if err != nil {
+		return 0
+	}
+
+	for i, line := range lines {
+		found := true
+		for _, term := range terms {
+			if !strings.Contains(line, term) {
+				found = false
+				break
+			}
+		}
+		if found {
+			return uint64(i + 1)
+		}
+	}
+	return 0
+}
+
+// Attaches source snippets to every SAST node, IAC finding, SCA manifest and container image file location in the result set.
+// Sources should come from the same scan as the results, see GetScanSourceArchiveByID or AddScanResultSourceSnippets.
+// Returns the number of locations for which the source could not be found.
+func (s *ScanResultSet) AddSourceSnippets(archive *ScanSourceArchive, contextLines uint64) uint64 {
+	var missing uint64
+
+	for i := range s.SAST {
+		for n := range s.SAST[i].Data.Nodes {
+			node := &s.SAST[i].Data.Nodes[n]
+			snippet, err := archive.GetSnippet(node.FileName, node.Line, contextLines)
+			if err != nil {
+				missing++
+				continue
+			}
+			node.Snippet = snippet
+		}
+	}
+
+	for i := range s.IAC {
+		data := &s.IAC[i].Data
+		snippet, err := archive.GetSnippet(data.FileName, uint64(data.Line), contextLines)
+		if err != nil {
+			missing++
+			continue
+		}
+		data.Snippet = snippet
+	}
+
+	for i := range s.SCA {
+		if s.SCA[i].SourceFileName == "" {
+			continue
+		}
+		_, name, _ := s.SCA[i].Data.ParsePackageIdentifier()
+		line := archive.FindLine(s.SCA[i].SourceFileName, name)
+		if idx := strings.LastIndex(name, ":"); line == 0 && idx >= 0 {
+			// maven manifests list the group and artifact on separate lines
+			line = archive.FindLine(s.SCA[i].SourceFileName, name[idx+1:])
+		}
+		snippet, err := archive.GetSnippet(s.SCA[i].SourceFileName, line, contextLines)
+		if err != nil {
+			missing++
+			continue
+		}
+		s.SCA[i].Data.Snippet = snippet
+	}
+
+	for i := range s.Containers {
+		data := &s.Containers[i].Data
+		if data.ImageFilePath == "" {
+			continue
+		}
+		snippet, err := archive.GetSnippet(data.ImageFilePath, archive.FindLine(data.ImageFilePath, data.ImageName), contextLines)
+		if err != nil {
+			missing++
+			continue
+		}
+		data.Snippet = snippet
+	}
+
+	return missing
+}
+
+func (s ResultSourceSnippet) String() string {
+	var b strings.Builder
+	for _, l := range s.Lines {
+		marker := " "
+		if l.Number == s.Line {
+			marker = ">"
+		}
+		fmt.Fprintf(&b, "%v%6d | %v\n", marker, l.Number, l.Text)
+	}
+	return b.String()
+}
+
+func normalizeSourcePath(path string) string {
+	path = strings.ReplaceAll(path, "\\", "/")
+	return strings.TrimLeft(path, "/")
+}
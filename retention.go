@@ -0,0 +1,258 @@
+package Cx1ClientGo
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/exp/slices"
+)
+
+// Decides which scans to keep or delete under the policy without deleting anything (a dry run).
+// Queued and running scans are always kept. Scans are enumerated most-recent first per project and branch.
+// Triaged scans are found from the triage history of the results in the latest completed scan on each branch,
+// and are only looked up for branches which have scans to delete. Results which have since been fixed are not in that scan,
+// so a scan in which only those were triaged may be deleted. Scans are kept if the triage of their branch can not be read.
+func (c *Cx1Client) PlanScanRetention(policy ScanRetentionPolicy) (ScanRetentionReport, error) {
+	report := ScanRetentionReport{
+		DryRun:    true,
+		Decisions: []ScanRetentionDecision{},
+		Deleted:   []string{},
+		Errors:    []string{},
+	}
+
+	scans := []Scan{}
+	filter := ScanFilter{
+		BaseFilter: BaseFilter{Limit: c.config.Pagination.Scans},
+		Sort:       []string{ScanSortCreatedDescending},
+	}
+	if len(policy.ProjectIDs) == 0 {
+		_, ss, err := c.GetAllScansFiltered(filter)
+		if err != nil {
+			return report, fmt.Errorf("failed to get scans: %s", err)
+		}
+		scans = ss
+	} else {
+		for _, projectID := range policy.ProjectIDs {
+			filter.ProjectID = projectID
+			_, ss, err := c.GetAllScansFiltered(filter)
+			if err != nil {
+				return report, fmt.Errorf("failed to get scans of project %v: %s", ShortenGUID(projectID), err)
+			}
+			scans = append(scans, ss...)
+		}
+	}
+
+	// the API sorts within a page of results, the order across pages is ensured here
+	sort.SliceStable(scans, func(i, j int) bool { return scans[i].CreatedAt.After(scans[j].CreatedAt) })
+
+	violations := map[string]bool{}
+	if policy.KeepPolicyViolations {
+		list, err := c.GetAllPolicyViolations()
+		if err != nil {
+			return report, fmt.Errorf("failed to get policy violations: %s", err)
+		}
+		for _, v := range list {
+			violations[v.ScanID] = true
+		}
+	}
+
+	now := time.Now()
+	completedPerBranch := map[string]int{}
+	latestCompleted := map[string]string{} // project ID/branch -> scan ID
+	for _, scan := range scans {
+		decision := ScanRetentionDecision{
+			ScanID:      scan.ScanID,
+			ProjectID:   scan.ProjectID,
+			ProjectName: scan.ProjectName,
+			Branch:      scan.Branch,
+			Status:      scan.Status,
+			CreatedAt:   scan.CreatedAt,
+		}
+		age := now.Sub(scan.CreatedAt)
+
+		switch scan.Status {
+		case ScanStatus.Completed, ScanStatus.Partial:
+			key := scan.ProjectID + "/" + scan.Branch
+			if _, ok := latestCompleted[key]; !ok {
+				latestCompleted[key] = scan.ScanID
+			}
+			completedPerBranch[key]++
+			if policy.KeepLastCompleted <= 0 {
+				decision.Reason = "completed scans are not deleted"
+			} else if completedPerBranch[key] <= policy.KeepLastCompleted {
+				decision.Reason = fmt.Sprintf("one of the last %d completed scans on the branch", policy.KeepLastCompleted)
+			} else if policy.DeleteCompletedAfterDays > 0 && age < time.Duration(policy.DeleteCompletedAfterDays)*24*time.Hour {
+				decision.Reason = fmt.Sprintf("completed less than %d days ago", policy.DeleteCompletedAfterDays)
+			} else {
+				decision.Delete = true
+				decision.Reason = fmt.Sprintf("beyond the last %d completed scans on the branch", policy.KeepLastCompleted)
+			}
+		case ScanStatus.Failed, ScanStatus.Canceled:
+			if policy.DeleteFailedAfterDays > 0 && age >= time.Duration(policy.DeleteFailedAfterDays)*24*time.Hour {
+				decision.Delete = true
+				decision.Reason = fmt.Sprintf("%v more than %d days ago", strings.ToLower(scan.Status), policy.DeleteFailedAfterDays)
+			} else {
+				decision.Reason = fmt.Sprintf("%v scans are not deleted yet", strings.ToLower(scan.Status))
+			}
+		default:
+			decision.Reason = "scan is in progress"
+		}
+
+		if decision.Delete {
+			if violations[scan.ScanID] {
+				decision.Delete = false
+				decision.Reason = "referenced by a policy violation"
+			} else if key, ok := scanHasAnyTag(scan, policy.KeepTags); ok {
+				decision.Delete = false
+				decision.Reason = fmt.Sprintf("tagged %v", key)
+			}
+		}
+		report.Decisions = append(report.Decisions, decision)
+	}
+
+	if policy.KeepTriaged {
+		triaged := map[string]map[string]bool{} // project ID/branch -> scan IDs, nil if the triage could not be read
+		for i := range report.Decisions {
+			d := &report.Decisions[i]
+			if !d.Delete {
+				continue
+			}
+			key := d.ProjectID + "/" + d.Branch
+			if _, ok := triaged[key]; !ok {
+				scanIDs, err := c.getTriagedScanIDs(d.ProjectID, latestCompleted[key])
+				if err != nil {
+					c.config.Logger.Warnf("Keeping scans of project %v on branch %v: %s", d.ProjectName, d.Branch, err)
+					scanIDs = nil
+				}
+				triaged[key] = scanIDs
+			}
+			if triaged[key] == nil {
+				d.Delete = false
+				d.Reason = "the triage on the branch could not be read"
+			} else if triaged[key][d.ScanID] {
+				d.Delete = false
+				d.Reason = "results were triaged in this scan"
+			}
+		}
+	}
+
+	return report, nil
+}
+
+func scanHasAnyTag(scan Scan, tags map[string]string) (string, bool) {
+	for k, v := range tags {
+		if value, ok := scan.Tags[k]; ok && (v == "" || value == v) {
+			return k, true
+		}
+	}
+	return "", false
+}
+
+// returns the IDs of the scans in which results of the given scan were triaged
+func (c *Cx1Client) getTriagedScanIDs(projectID, scanID string) (map[string]bool, error) {
+	scanIDs := map[string]bool{}
+	if scanID == "" {
+		return scanIDs, nil
+	}
+
+	results, err := c.GetAllScanResultsByID(scanID)
+	if err != nil {
+		return scanIDs, fmt.Errorf("failed to get results of scan %v: %s", ShortenGUID(scanID), err)
+	}
+	for _, r := range results.SAST {
+		predicates, err := c.GetSASTResultsPredicatesByID(r.SimilarityID, projectID, scanID)
+		if err != nil {
+			return scanIDs, fmt.Errorf("failed to get triage of result %v: %s", r.SimilarityID, err)
+		}
+		for _, p := range predicates {
+			scanIDs[p.ScanID] = true
+		}
+	}
+	for _, r := range results.IAC {
+		predicates, err := c.GetIACResultsPredicatesByID(r.SimilarityID, projectID)
+		if err != nil {
+			return scanIDs, fmt.Errorf("failed to get triage of result %v: %s", r.SimilarityID, err)
+		}
+		for _, p := range predicates {
+			scanIDs[p.ScanID] = true
+		}
+	}
+	return scanIDs, nil
+}
+
+// Deletes the scans marked for deletion in the report using up to workers concurrent requests (default 4).
+// The returned report lists the deleted scans and any failures.
+func (c *Cx1Client) ExecuteScanRetention(report ScanRetentionReport, workers int) (ScanRetentionReport, error) {
+	report.DryRun = false
+	report.Deleted = []string{}
+	report.Errors = []string{}
+
+	toDelete := []ScanRetentionDecision{}
+	for _, d := range report.Decisions {
+		if d.Delete {
+			toDelete = append(toDelete, d)
+		}
+	}
+
+	errs := c.runConcurrently(len(toDelete), workers, func(i int) error {
+		return c.DeleteScanByID(toDelete[i].ScanID)
+	})
+	for i, err := range errs {
+		if err != nil {
+			c.config.Logger.Warnf("Failed to delete scan %v of project %v: %s", toDelete[i].ScanID, toDelete[i].ProjectName, err)
+			report.Errors = append(report.Errors, fmt.Sprintf("scan %v: %s", toDelete[i].ScanID, err))
+		} else {
+			report.Deleted = append(report.Deleted, toDelete[i].ScanID)
+		}
+	}
+
+	c.config.Logger.Debugf("Deleted %d of %d scans marked for deletion", len(report.Deleted), len(toDelete))
+	if len(report.Errors) > 0 {
+		return report, fmt.Errorf("failed to delete %d scans", len(report.Errors))
+	}
+	return report, nil
+}
+
+// Plans and, unless dryRun is set, executes the retention policy, see PlanScanRetention and ExecuteScanRetention
+func (c *Cx1Client) ApplyScanRetentionPolicy(policy ScanRetentionPolicy, dryRun bool, workers int) (ScanRetentionReport, error) {
+	report, err := c.PlanScanRetention(policy)
+	if err != nil || dryRun {
+		return report, err
+	}
+	return c.ExecuteScanRetention(report, workers)
+}
+
+// Returns the decisions to delete scans, or only those which were deleted if the report has been executed
+func (r ScanRetentionReport) Reclaimed() []ScanRetentionDecision {
+	reclaimed := []ScanRetentionDecision{}
+	for _, d := range r.Decisions {
+		if d.Delete && (r.DryRun || slices.Contains(r.Deleted, d.ScanID)) {
+			reclaimed = append(reclaimed, d)
+		}
+	}
+	return reclaimed
+}
+
+// Returns the report in a human-readable form, listing the reclaimed scans
+func (r ScanRetentionReport) String() string {
+	lines := []string{}
+	for _, d := range r.Reclaimed() {
+		lines = append(lines, d.String())
+	}
+	verb := "deleted"
+	if r.DryRun {
+		verb = "would be deleted"
+	}
+	lines = append(lines, fmt.Sprintf("Retention: %d of %d scans %v, %d errors", len(r.Reclaimed()), len(r.Decisions), verb, len(r.Errors)))
+	return strings.Join(lines, "\n")
+}
+
+func (d ScanRetentionDecision) String() string {
+	action := "keep"
+	if d.Delete {
+		action = "delete"
+	}
+	return fmt.Sprintf("%v scan %v of %v (%v) on %v, %v: %v", action, ShortenGUID(d.ScanID), d.ProjectName, d.Branch, d.CreatedAt.Format(time.DateOnly), d.Status, d.Reason)
+}
@@ -0,0 +1,329 @@
+package Cx1ClientGo
+
+import (
+	"crypto/rand"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CycloneDX analysis justifications, one of these can be included in a Not Exploitable predicate comment
+// to be used as the VEX justification, eg: "code_not_reachable: the vulnerable function is never called"
+var VEXJustifications = []string{
+	"code_not_present",
+	"code_not_reachable",
+	"requires_configuration",
+	"requires_dependency",
+	"requires_environment",
+	"protected_by_compiler",
+	"protected_at_runtime",
+	"protected_at_perimeter",
+	"protected_by_mitigating_control",
+}
+
+// maps the package manager prefix of the SCA PackageIdentifier to the purl type and OSV ecosystem
+var scaPackageManagers = map[string]struct {
+	PURL      string
+	Ecosystem string
+}{
+	"npm":       {"npm", "npm"},
+	"maven":     {"maven", "Maven"},
+	"nuget":     {"nuget", "NuGet"},
+	"python":    {"pypi", "PyPI"},
+	"pip":       {"pypi", "PyPI"},
+	"go":        {"golang", "Go"},
+	"golang":    {"golang", "Go"},
+	"ruby":      {"gem", "RubyGems"},
+	"rubygems":  {"gem", "RubyGems"},
+	"php":       {"composer", "Packagist"},
+	"composer":  {"composer", "Packagist"},
+	"cocoapods": {"cocoapods", "CocoaPods"},
+	"cargo":     {"cargo", "crates.io"},
+	"rust":      {"cargo", "crates.io"},
+	"swift":     {"swift", "SwiftURL"},
+	"dart":      {"pub", "Pub"},
+	"pub":       {"pub", "Pub"},
+	"hex":       {"hex", "Hex"},
+}
+
+// Convenience function: retrieves all results for a scan and generates a VEX document from the SCA results
+// The triage state and comment of each result are taken from its SCA predicates, see GetSCAResultsPredicatesByID
+func (c *Cx1Client) GetScanSCAVEXByID(scanID string) (CycloneDXVEX, error) {
+	scan, err := c.GetScanByID(scanID)
+	if err != nil {
+		return CycloneDXVEX{}, err
+	}
+	results, err := c.GetAllScanResultsByID(scanID)
+	if err != nil {
+		return CycloneDXVEX{}, err
+	}
+
+	predicates := map[string]ResultsPredicatesBase{}
+	for _, r := range results.SCA {
+		history, err := c.GetSCAResultsPredicatesByID(r, scan.ProjectID, scanID)
+		if err != nil {
+			return CycloneDXVEX{}, fmt.Errorf("failed to get triage of SCA result %v: %s", r.String(), err)
+		}
+		if p, ok := latestSCAPredicate(history); ok {
+			predicates[r.SimilarityID] = p
+		}
+	}
+	return NewSCAVEXDocument(results.SCA, predicates), nil
+}
+
+// returns the latest state change, with the latest comment if the state change had none
+func latestSCAPredicate(history []ResultsPredicatesBase) (ResultsPredicatesBase, bool) {
+	var latest ResultsPredicatesBase
+	found := false
+	comment := ""
+	for _, p := range history {
+		if p.Comment != "" {
+			comment = p.Comment
+		}
+		if p.State != "" {
+			latest, found = p, true
+		}
+	}
+	if found && latest.Comment == "" {
+		latest.Comment = comment
+	}
+	return latest, found
+}
+
+// Generates a CycloneDX 1.5 VEX document reflecting the triage state of the SCA results
+// predicates are optional, keyed by SimilarityID, and override the state of the matching result.
+// Results in Not Exploitable state are reported as not_affected, with the justification and detail taken from the predicate comment
+func NewSCAVEXDocument(results []ScanSCAResult, predicates map[string]ResultsPredicatesBase) CycloneDXVEX {
+	vex := CycloneDXVEX{
+		BOMFormat:       "CycloneDX",
+		SpecVersion:     "1.5",
+		SerialNumber:    "urn:uuid:" + newUUID(),
+		Version:         1,
+		Components:      []CycloneDXComponent{},
+		Vulnerabilities: []CycloneDXVulnerability{},
+	}
+	vex.Metadata.Timestamp = time.Now().UTC()
+	vex.Metadata.Tools.Components = []CycloneDXComponent{{Type: "library", Name: "Cx1ClientGo"}}
+
+	components := map[string]bool{}
+
+	for _, r := range results {
+		_, name, version := r.Data.ParsePackageIdentifier()
+		purl := r.PackageURL()
+		if !components[purl] {
+			components[purl] = true
+			vex.Components = append(vex.Components, CycloneDXComponent{
+				BOMRef:  purl,
+				Type:    "library",
+				Name:    name,
+				Version: version,
+				PURL:    purl,
+			})
+		}
+
+		state, comment := scaResultTriage(r, predicates)
+
+		vuln := CycloneDXVulnerability{
+			BOMRef:         fmt.Sprintf("%v-%v", r.scaVulnerabilityID(), r.SimilarityID),
+			ID:             r.scaVulnerabilityID(),
+			Description:    r.Description,
+			Recommendation: r.Data.Recommendation,
+			Analysis:       scaVEXAnalysis(state, r.Status, comment),
+			Affects:        []CycloneDXAffect{{Ref: purl}},
+		}
+		if r.Data.RecommendedVersion != "" && vuln.Recommendation == "" {
+			vuln.Recommendation = fmt.Sprintf("Upgrade %v to version %v", name, r.Data.RecommendedVersion)
+		}
+		if advisory := r.Data.GetType("Advisory").URL; advisory != "" {
+			vuln.Source = &CycloneDXSource{Name: "Checkmarx", URL: advisory}
+		}
+		if !r.Data.PublishedAt.IsZero() {
+			published := r.Data.PublishedAt
+			vuln.Published = &published
+		}
+
+		rating := CycloneDXRating{
+			Score:    r.VulnerabilityDetails.CVSSScore,
+			Severity: strings.ToLower(r.Severity),
+		}
+		if r.VulnerabilityDetails.Cvss.Version != 0 {
+			rating.Method = fmt.Sprintf("CVSSv%d", r.VulnerabilityDetails.Cvss.Version)
+		}
+		if rating.Severity == "" {
+			rating.Severity = "unknown"
+		}
+		vuln.Ratings = []CycloneDXRating{rating}
+
+		if cwe, err := strconv.Atoi(strings.TrimPrefix(strings.ToUpper(r.VulnerabilityDetails.CweId), "CWE-")); err == nil {
+			vuln.CWEs = []int{cwe}
+		}
+
+		vex.Vulnerabilities = append(vex.Vulnerabilities, vuln)
+	}
+
+	return vex
+}
+
+// Generates OSV-format vulnerabilities for the SCA results, one per result
+// predicates are optional, keyed by SimilarityID, and override the state of the matching result.
+// The triage state and comment are included in the database_specific section
+func NewSCAOSVVulnerabilities(results []ScanSCAResult, predicates map[string]ResultsPredicatesBase) []OSVVulnerability {
+	vulns := []OSVVulnerability{}
+	now := time.Now().UTC()
+
+	for _, r := range results {
+		manager, name, version := r.Data.ParsePackageIdentifier()
+		state, comment := scaResultTriage(r, predicates)
+
+		vuln := OSVVulnerability{
+			SchemaVersion: "1.6.0",
+			ID:            r.scaVulnerabilityID(),
+			Modified:      now,
+			Summary:       fmt.Sprintf("%v in %v %v", r.scaVulnerabilityID(), name, version),
+			Details:       r.Description,
+			DatabaseSpecific: map[string]interface{}{
+				"severity":     r.Severity,
+				"cvss_score":   r.VulnerabilityDetails.CVSSScore,
+				"cwe":          r.VulnerabilityDetails.CweId,
+				"state":        state,
+				"status":       r.Status,
+				"similarityId": r.SimilarityID,
+				"projectId":    r.ProjectID,
+				"scanId":       r.ScanID,
+			},
+		}
+		if comment != "" {
+			vuln.DatabaseSpecific["comment"] = comment
+		}
+		if r.VulnerabilityDetails.CveName != "" && r.VulnerabilityDetails.CveName != vuln.ID {
+			vuln.Aliases = []string{r.VulnerabilityDetails.CveName}
+		}
+		if !r.Data.PublishedAt.IsZero() {
+			published := r.Data.PublishedAt
+			vuln.Published = &published
+		}
+
+		affected := OSVAffected{
+			Package: OSVPackage{
+				Ecosystem: manager,
+				Name:      name,
+				PURL:      r.PackageURL(),
+			},
+			Versions: []string{version},
+		}
+		if pm, ok := scaPackageManagers[strings.ToLower(manager)]; ok {
+			affected.Package.Ecosystem = pm.Ecosystem
+		}
+		if r.Data.RecommendedVersion != "" {
+			affected.Ranges = []OSVRange{{
+				Type: "ECOSYSTEM",
+				Events: []map[string]string{
+					{"introduced": "0"},
+					{"fixed": r.Data.RecommendedVersion},
+				},
+			}}
+		}
+		vuln.Affected = []OSVAffected{affected}
+
+		for _, pd := range r.Data.PackageData {
+			if pd.URL == "" {
+				continue
+			}
+			reftype := "WEB"
+			switch strings.ToLower(pd.Type) {
+			case "advisory":
+				reftype = "ADVISORY"
+			case "issue":
+				reftype = "REPORT"
+			case "commit":
+				reftype = "FIX"
+			}
+			vuln.References = append(vuln.References, OSVReference{Type: reftype, URL: pd.URL})
+		}
+
+		vulns = append(vulns, vuln)
+	}
+
+	return vulns
+}
+
+// Returns the package URL (purl) for the package affected by this result, eg: pkg:npm/lodash@4.17.20
+func (r ScanSCAResult) PackageURL() string {
+	manager, name, version := r.Data.ParsePackageIdentifier()
+	purltype := strings.ToLower(manager)
+	if pm, ok := scaPackageManagers[purltype]; ok {
+		purltype = pm.PURL
+	}
+
+	if purltype == "maven" {
+		name = strings.Replace(name, ":", "/", 1)
+	}
+	if version == "" {
+		return fmt.Sprintf("pkg:%v/%v", purltype, name)
+	}
+	return fmt.Sprintf("pkg:%v/%v@%v", purltype, name, version)
+}
+
+func (r ScanSCAResult) scaVulnerabilityID() string {
+	if r.VulnerabilityDetails.CveName != "" {
+		return r.VulnerabilityDetails.CveName
+	}
+	if r.ResultID != "" {
+		return r.ResultID
+	}
+	return r.SimilarityID
+}
+
+func scaResultTriage(r ScanSCAResult, predicates map[string]ResultsPredicatesBase) (state, comment string) {
+	state = r.State
+	if p, ok := predicates[r.SimilarityID]; ok {
+		if p.State != "" {
+			state = p.State
+		}
+		comment = p.Comment
+	}
+	return
+}
+
+// maps the Cx1 state & status to a CycloneDX VEX analysis
+func scaVEXAnalysis(state, status, comment string) CycloneDXAnalysis {
+	analysis := CycloneDXAnalysis{Detail: comment}
+
+	switch strings.ToUpper(strings.TrimSpace(state)) {
+	case "NOT_EXPLOITABLE":
+		analysis.State = "not_affected"
+		analysis.Justification = vexJustificationFromComment(comment)
+	case "CONFIRMED", "URGENT":
+		analysis.State = "exploitable"
+	default: // TO_VERIFY, PROPOSED_NOT_EXPLOITABLE and custom states
+		analysis.State = "in_triage"
+	}
+
+	if strings.EqualFold(status, "FIXED") {
+		analysis.State = "resolved"
+		analysis.Justification = ""
+	}
+
+	return analysis
+}
+
+// finds the first known VEX justification in the comment, allowing for spaces instead of underscores
+func vexJustificationFromComment(comment string) string {
+	normalized := strings.ReplaceAll(strings.ToLower(comment), " ", "_")
+	for _, j := range VEXJustifications {
+		if strings.Contains(normalized, j) {
+			return j
+		}
+	}
+	return ""
+}
+
+// random (version 4) UUID
+func newUUID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
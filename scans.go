@@ -75,6 +75,24 @@ func (c *Cx1Client) CancelScanByID(scanID string) error {
 	return nil
 }
 
+// Replace the tags of a scan
+func (c *Cx1Client) UpdateScanTagsByID(scanID string, tags map[string]string) error {
+	var body struct {
+		Tags map[string]string `json:"tags"`
+	}
+	body.Tags = tags
+	jsonBody, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	_, err = c.sendRequest(http.MethodPut, fmt.Sprintf("/scans/%v/tags", scanID), bytes.NewReader(jsonBody), nil)
+	if err != nil {
+		return fmt.Errorf("failed to update tags of scan with ID %v: %s", scanID, err)
+	}
+
+	return nil
+}
+
 // Return a list of all scans
 func (c *Cx1Client) GetAllScans() ([]Scan, error) {
 	_, scans, err := c.GetAllScansFiltered(ScanFilter{
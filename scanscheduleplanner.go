@@ -0,0 +1,527 @@
+package Cx1ClientGo
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+)
+
+// The types of conflicts reported by DetectScanScheduleConflicts
+var ScanScheduleConflictTypes = struct {
+	Conflict string
+	Overlap  string
+	Peak     string
+}{
+	Conflict: "conflict",
+	Overlap:  "overlap",
+	Peak:     "peak",
+}
+
+// the days of a weekly schedule, in the order used for the weekly load
+var scheduleDays = []string{"monday", "tuesday", "wednesday", "thursday", "friday", "saturday", "sunday"}
+
+// Translates a cron-like expression (minute hour day-of-month month day-of-week) to a daily or weekly schedule.
+// Only expressions which run once a day, every day or on specific days of the week, are supported, eg: "30 2 * * *" or "0 22 * * mon-fri".
+// Days of the week may be numbers (0 or 7 is sunday) or names, in lists or ranges. The macros @daily, @midnight and @weekly are also accepted.
+// The returned schedule is active; the project, engines and branch still need to be set.
+func ScanScheduleFromExpression(expression string) (ProjectScanSchedule, error) {
+	schedule := ProjectScanSchedule{Active: true, Tags: map[string]string{}}
+	fields := strings.Fields(strings.ToLower(expression))
+	if len(fields) == 1 {
+		switch fields[0] {
+		case "@daily", "@midnight":
+			fields = []string{"0", "0", "*", "*", "*"}
+		case "@weekly":
+			fields = []string{"0", "0", "*", "*", "0"}
+		}
+	}
+	if len(fields) != 5 {
+		return schedule, fmt.Errorf("unsupported schedule expression %v: expected minute, hour, day of month, month and day of week", expression)
+	}
+
+	minute, err := strconv.Atoi(fields[0])
+	if err != nil || minute < 0 || minute > 59 {
+		return schedule, fmt.Errorf("unsupported schedule expression %v: the minute must be a single value from 0 to 59", expression)
+	}
+	hour, err := strconv.Atoi(fields[1])
+	if err != nil || hour < 0 || hour > 23 {
+		return schedule, fmt.Errorf("unsupported schedule expression %v: the hour must be a single value from 0 to 23", expression)
+	}
+	if (fields[2] != "*" && fields[2] != "?") || fields[3] != "*" {
+		return schedule, fmt.Errorf("unsupported schedule expression %v: scans can only be scheduled daily or on days of the week", expression)
+	}
+	days, err := parseScheduleDays(fields[4])
+	if err != nil {
+		return schedule, fmt.Errorf("unsupported schedule expression %v: %s", expression, err)
+	}
+
+	schedule.StartTime = formatScheduleTime(hour*60 + minute)
+	if len(days) == len(scheduleDays) {
+		schedule.Frequency = "daily"
+	} else {
+		schedule.Frequency = "weekly"
+		schedule.Days = days
+	}
+	return schedule, nil
+}
+
+// returns the selected days in week order
+func parseScheduleDays(field string) ([]string, error) {
+	days := []string{}
+	if field == "*" || field == "?" {
+		return append(days, scheduleDays...), nil
+	}
+
+	selected := make([]bool, len(scheduleDays))
+	for _, part := range strings.Split(field, ",") {
+		if strings.Contains(part, "/") {
+			return days, fmt.Errorf("steps in the day of week are not supported")
+		}
+		bounds := strings.SplitN(part, "-", 2)
+		first, err := parseScheduleDay(bounds[0])
+		if err != nil {
+			return days, err
+		}
+		last := first
+		if len(bounds) == 2 {
+			if last, err = parseScheduleDay(bounds[1]); err != nil {
+				return days, err
+			}
+		}
+		for d := first; ; d = (d + 1) % len(scheduleDays) {
+			selected[d] = true
+			if d == last {
+				break
+			}
+		}
+	}
+
+	for i, day := range scheduleDays {
+		if selected[i] {
+			days = append(days, day)
+		}
+	}
+	return days, nil
+}
+
+// returns the index of the day in scheduleDays, from a cron day number (sunday is 0 or 7) or name
+func parseScheduleDay(value string) (int, error) {
+	if number, err := strconv.Atoi(value); err == nil {
+		if number < 0 || number > 7 {
+			return 0, fmt.Errorf("invalid day of week %v", value)
+		}
+		return (number + 6) % 7, nil
+	}
+	for i, day := range scheduleDays {
+		if len(value) >= 3 && strings.HasPrefix(day, value) {
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid day of week %v", value)
+}
+
+// Returns the schedule as a cron-like expression, see ScanScheduleFromExpression
+func (s ProjectScanSchedule) Expression() string {
+	start := scheduleStartMinutes(s)
+	days := "*"
+	if s.Frequency == "weekly" {
+		numbers := []string{}
+		for _, i := range scheduleDayIndexes(s) {
+			numbers = append(numbers, strconv.Itoa((i+1)%7))
+		}
+		days = strings.Join(numbers, ",")
+	}
+	return fmt.Sprintf("%d %d * * %v", start%60, start/60, days)
+}
+
+// parses HH:MM to minutes since midnight
+func parseScheduleTime(value string) (int, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %v, expected HH:MM", value)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+func formatScheduleTime(minutes int) string {
+	return fmt.Sprintf("%02d:%02d", minutes/60, minutes%60)
+}
+
+// minutes since midnight at which the schedule starts, from StartTime or else NextStartTime
+func scheduleStartMinutes(s ProjectScanSchedule) int {
+	if minutes, err := parseScheduleTime(s.StartTime); err == nil {
+		return minutes
+	}
+	return s.NextStartTime.Hour()*60 + s.NextStartTime.Minute()
+}
+
+// indexes into scheduleDays of the days on which the schedule runs
+func scheduleDayIndexes(s ProjectScanSchedule) []int {
+	indexes := []int{}
+	for i, day := range scheduleDays {
+		if s.Frequency != "weekly" || slices.ContainsFunc(s.Days, func(d string) bool { return strings.EqualFold(d, day) }) {
+			indexes = append(indexes, i)
+		}
+	}
+	return indexes
+}
+
+// Returns the expected scan duration per project: the average time from creation to last update of its most recent completed scans (up to samples, default 5).
+// Projects without completed scans are not included.
+func (c *Cx1Client) GetHistoricScanDurations(projectIDs []string, samples int) (map[string]time.Duration, error) {
+	durations := map[string]time.Duration{}
+	if samples <= 0 {
+		samples = 5
+	}
+
+	for _, projectID := range projectIDs {
+		_, scans, err := c.GetScansFiltered(ScanFilter{
+			BaseFilter: BaseFilter{Limit: uint64(samples)},
+			ProjectID:  projectID,
+			Statuses:   []string{ScanStatus.Completed},
+			Sort:       []string{ScanSortCreatedDescending},
+		})
+		if err != nil {
+			return durations, fmt.Errorf("failed to get scans of project %v: %s", ShortenGUID(projectID), err)
+		}
+
+		var total time.Duration
+		count := 0
+		for _, scan := range scans {
+			if d := scan.UpdatedAt.Sub(scan.CreatedAt); d > 0 {
+				total += d
+				count++
+			}
+		}
+		if count > 0 {
+			durations[projectID] = total / time.Duration(count)
+		}
+	}
+	return durations, nil
+}
+
+func (w ScanScheduleWindow) withDefaults() ScanScheduleWindow {
+	if w.Interval < time.Minute {
+		w.Interval = 15 * time.Minute
+	}
+	if w.DefaultDuration <= 0 {
+		w.DefaultDuration = 30 * time.Minute
+	}
+	return w
+}
+
+func (w ScanScheduleWindow) duration(durations map[string]time.Duration, projectID string) time.Duration {
+	if d, ok := durations[projectID]; ok && d > 0 {
+		return d
+	}
+	return w.DefaultDuration
+}
+
+// the expected number of running scans in each interval of the week, starting monday 00:00
+type scheduleLoad struct {
+	interval int // minutes
+	perDay   int
+	slots    []int
+}
+
+func newScheduleLoad(interval time.Duration) *scheduleLoad {
+	minutes := int(interval.Minutes())
+	perDay := (24*60 + minutes - 1) / minutes
+	return &scheduleLoad{interval: minutes, perDay: perDay, slots: make([]int, perDay*len(scheduleDays))}
+}
+
+// the slots in which a scan of the schedule is expected to run, wrapping around the end of the week
+func (l *scheduleLoad) runs(s ProjectScanSchedule, duration time.Duration) []int {
+	start := scheduleStartMinutes(s) / l.interval
+	count := min(max(1, int((duration+time.Duration(l.interval)*time.Minute-1)/(time.Duration(l.interval)*time.Minute))), len(l.slots))
+	slots := []int{}
+	for _, day := range scheduleDayIndexes(s) {
+		for i := 0; i < count; i++ {
+			slots = append(slots, (day*l.perDay+start+i)%len(l.slots))
+		}
+	}
+	return slots
+}
+
+func (l *scheduleLoad) add(s ProjectScanSchedule, duration time.Duration) {
+	for _, slot := range l.runs(s, duration) {
+		l.slots[slot]++
+	}
+}
+
+// the highest and total load during the slots in which the schedule would run
+func (l *scheduleLoad) cost(s ProjectScanSchedule, duration time.Duration) (int, int) {
+	peak, total := 0, 0
+	for _, slot := range l.runs(s, duration) {
+		peak = max(peak, l.slots[slot])
+		total += l.slots[slot]
+	}
+	return peak, total
+}
+
+// Assigns each schedule the start time within the window at which the fewest other scans are expected to be running, longest scans first.
+// The fixed schedules keep their start times but count towards the load. Durations are by project ID (see GetHistoricScanDurations),
+// with the window's DefaultDuration for other projects. Only the start times are changed; scans may run past the end of the window.
+func SpreadScanSchedules(schedules, fixed []ProjectScanSchedule, durations map[string]time.Duration, window ScanScheduleWindow) ([]ProjectScanSchedule, error) {
+	spread := append([]ProjectScanSchedule{}, schedules...)
+	window = window.withDefaults()
+	start, err := parseScheduleTime(window.Start)
+	if err != nil {
+		return spread, fmt.Errorf("invalid window start: %s", err)
+	}
+	end, err := parseScheduleTime(window.End)
+	if err != nil {
+		return spread, fmt.Errorf("invalid window end: %s", err)
+	}
+	length := end - start
+	if length <= 0 {
+		length += 24 * 60
+	}
+
+	load := newScheduleLoad(window.Interval)
+	for _, s := range fixed {
+		load.add(s, window.duration(durations, s.ProjectID))
+	}
+
+	order := make([]int, len(spread))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return window.duration(durations, spread[order[i]].ProjectID) > window.duration(durations, spread[order[j]].ProjectID)
+	})
+
+	for _, i := range order {
+		s := &spread[i]
+		duration := window.duration(durations, s.ProjectID)
+		best, bestPeak, bestTotal := -1, 0, 0
+		for offset := 0; offset < length; offset += load.interval {
+			s.StartTime = formatScheduleTime((start + offset) % (24 * 60))
+			peak, total := load.cost(*s, duration)
+			if best < 0 || peak < bestPeak || (peak == bestPeak && total < bestTotal) {
+				best, bestPeak, bestTotal = offset, peak, total
+			}
+		}
+		s.StartTime = formatScheduleTime((start + best) % (24 * 60))
+		load.add(*s, duration)
+	}
+	return spread, nil
+}
+
+// Returns the conflicts between the schedules, using the expected durations by project ID:
+//   - Conflict: the same project branch is scheduled more than once on the same day
+//   - Overlap: scans of different branches of the same project are expected to run at the same time
+//   - Peak: more than window.MaxConcurrent scans are expected to run at the same time, only if MaxConcurrent is set
+func DetectScanScheduleConflicts(schedules []ProjectScanSchedule, durations map[string]time.Duration, window ScanScheduleWindow) []ScanScheduleConflict {
+	conflicts := []ScanScheduleConflict{}
+	window = window.withDefaults()
+	load := newScheduleLoad(window.Interval)
+	slotTime := func(slot int) (string, string) {
+		return scheduleDays[slot/load.perDay], formatScheduleTime((slot % load.perDay) * load.interval)
+	}
+
+	for i := range schedules {
+		for j := i + 1; j < len(schedules); j++ {
+			a, b := schedules[i], schedules[j]
+			if a.ProjectID != b.ProjectID || !a.Active || !b.Active {
+				continue
+			}
+			if a.Branch == b.Branch {
+				shared := []string{}
+				for _, day := range scheduleDayIndexes(b) {
+					if slices.Contains(scheduleDayIndexes(a), day) {
+						shared = append(shared, scheduleDays[day])
+					}
+				}
+				if len(shared) > 0 {
+					conflicts = append(conflicts, ScanScheduleConflict{
+						Type:      ScanScheduleConflictTypes.Conflict,
+						Day:       shared[0],
+						StartTime: a.StartTime,
+						Schedules: []ProjectScanSchedule{a, b},
+						Reason:    fmt.Sprintf("branch %v of project %v is scheduled more than once on %v", a.Branch, ShortenGUID(a.ProjectID), strings.Join(shared, ", ")),
+					})
+				}
+				continue
+			}
+
+			runsA := load.runs(a, window.duration(durations, a.ProjectID))
+			for _, slot := range load.runs(b, window.duration(durations, b.ProjectID)) {
+				if slices.Contains(runsA, slot) {
+					day, start := slotTime(slot)
+					conflicts = append(conflicts, ScanScheduleConflict{
+						Type:      ScanScheduleConflictTypes.Overlap,
+						Day:       day,
+						StartTime: start,
+						Schedules: []ProjectScanSchedule{a, b},
+						Reason:    fmt.Sprintf("scans of branches %v and %v of project %v are expected to run at the same time", a.Branch, b.Branch, ShortenGUID(a.ProjectID)),
+					})
+					break
+				}
+			}
+		}
+	}
+
+	if window.MaxConcurrent > 0 {
+		running := make([][]int, len(load.slots))
+		for i, s := range schedules {
+			if !s.Active {
+				continue
+			}
+			for _, slot := range load.runs(s, window.duration(durations, s.ProjectID)) {
+				running[slot] = append(running[slot], i)
+			}
+		}
+
+		// start from a slot within the limit so that a peak spanning the end of the week is reported once
+		first := max(0, slices.IndexFunc(running, func(indexes []int) bool { return len(indexes) <= window.MaxConcurrent }))
+		var peak *ScanScheduleConflict
+		for i := range running {
+			slot := (first + i) % len(running)
+			indexes := running[slot]
+			if len(indexes) <= window.MaxConcurrent {
+				if peak != nil {
+					conflicts = append(conflicts, *peak)
+					peak = nil
+				}
+				continue
+			}
+			if peak == nil {
+				day, start := slotTime(slot)
+				peak = &ScanScheduleConflict{Type: ScanScheduleConflictTypes.Peak, Day: day, StartTime: start}
+			}
+			if len(indexes) > len(peak.Schedules) {
+				peak.Schedules = []ProjectScanSchedule{}
+				for _, index := range indexes {
+					peak.Schedules = append(peak.Schedules, schedules[index])
+				}
+				peak.Reason = fmt.Sprintf("up to %d scans are expected to run at the same time, more than %d", len(indexes), window.MaxConcurrent)
+			}
+		}
+		if peak != nil {
+			conflicts = append(conflicts, *peak)
+		}
+	}
+
+	return conflicts
+}
+
+// Plans the scan schedules without changing anything (a dry run). If schedules is empty, the tenant's existing schedules are planned.
+// If the window has a start and end, the start times are spread across it with SpreadScanSchedules, counting the load of the other existing schedules.
+// Expected durations come from the recent completed scans of each project. The plan contains the schedules which are new or changed,
+// and the conflicts expected once it is applied. The plan can be applied with ApplyScanSchedulePlan.
+func (c *Cx1Client) PlanScanSchedules(schedules []ProjectScanSchedule, window ScanScheduleWindow) (ScanSchedulePlan, error) {
+	window = window.withDefaults()
+	plan := ScanSchedulePlan{Window: window, Changes: []ScanScheduleChange{}, Conflicts: []ScanScheduleConflict{}}
+
+	existing, err := c.GetAllScanSchedules()
+	if err != nil {
+		return plan, fmt.Errorf("failed to get scan schedules: %s", err)
+	}
+	if len(schedules) == 0 {
+		schedules = existing
+	}
+
+	planned := map[string]bool{}
+	for _, s := range schedules {
+		planned[s.ProjectID] = true
+	}
+	fixed := []ProjectScanSchedule{}
+	projectIDs := map[string]bool{}
+	for _, s := range existing {
+		projectIDs[s.ProjectID] = true
+		if !planned[s.ProjectID] {
+			fixed = append(fixed, s)
+		}
+	}
+	maps.Copy(projectIDs, planned)
+
+	durations, err := c.GetHistoricScanDurations(sortedKeys(projectIDs), 0)
+	if err != nil {
+		return plan, err
+	}
+
+	if window.Start != "" && window.End != "" {
+		if schedules, err = SpreadScanSchedules(schedules, fixed, durations, window); err != nil {
+			return plan, err
+		}
+	}
+
+	for _, s := range schedules {
+		change := ScanScheduleChange{ProjectID: s.ProjectID, New: s, Duration: window.duration(durations, s.ProjectID)}
+		if index := slices.IndexFunc(existing, func(e ProjectScanSchedule) bool { return e.ProjectID == s.ProjectID }); index >= 0 {
+			if !scanScheduleChanged(existing[index], s) {
+				continue
+			}
+			change.Old = &existing[index]
+		}
+		plan.Changes = append(plan.Changes, change)
+	}
+
+	plan.Conflicts = DetectScanScheduleConflicts(append(fixed, schedules...), durations, window)
+	c.config.Logger.Debugf("Planned %d scan schedule changes with %d conflicts", len(plan.Changes), len(plan.Conflicts))
+	return plan, nil
+}
+
+func scanScheduleChanged(old, updated ProjectScanSchedule) bool {
+	return scheduleStartMinutes(old) != scheduleStartMinutes(updated) ||
+		old.Frequency != updated.Frequency ||
+		!slices.Equal(scheduleDayIndexes(old), scheduleDayIndexes(updated)) ||
+		old.Active != updated.Active ||
+		old.Branch != updated.Branch ||
+		!slices.Equal(old.Engines, updated.Engines) ||
+		!maps.Equal(old.Tags, updated.Tags)
+}
+
+// Creates or updates the planned schedules using up to workers concurrent requests (default 4).
+// All changes are attempted, and the error lists those which failed.
+func (c *Cx1Client) ApplyScanSchedulePlan(plan ScanSchedulePlan, workers int) error {
+	failures := []string{}
+	errs := c.runConcurrently(len(plan.Changes), workers, func(i int) error {
+		change := plan.Changes[i]
+		if change.Old == nil {
+			return c.CreateScanScheduleByID(change.ProjectID, change.New)
+		}
+		return c.UpdateScanScheduleByID(change.ProjectID, change.New)
+	})
+	for i, err := range errs {
+		if err != nil {
+			c.config.Logger.Warnf("Failed to update scan schedule of project %v: %s", plan.Changes[i].ProjectID, err)
+			failures = append(failures, fmt.Sprintf("project %v: %s", ShortenGUID(plan.Changes[i].ProjectID), err))
+		}
+	}
+
+	if len(failures) > 0 {
+		return fmt.Errorf("failed to apply %d scan schedule changes: %v", len(failures), strings.Join(failures, "; "))
+	}
+	return nil
+}
+
+// Returns the plan in a human-readable form, one schedule or conflict per line
+func (p ScanSchedulePlan) String() string {
+	lines := []string{}
+	for _, c := range p.Changes {
+		lines = append(lines, c.String())
+	}
+	for _, c := range p.Conflicts {
+		lines = append(lines, c.String())
+	}
+	lines = append(lines, fmt.Sprintf("Plan: %d scan schedule changes, %d conflicts", len(p.Changes), len(p.Conflicts)))
+	return strings.Join(lines, "\n")
+}
+
+func (c ScanScheduleChange) String() string {
+	if c.Old == nil {
+		return fmt.Sprintf("+ %v (expected %v)", c.New.String(), c.Duration.Round(time.Minute))
+	}
+	return fmt.Sprintf("~ %v -> %v (expected %v)", c.Old.Expression(), c.New.String(), c.Duration.Round(time.Minute))
+}
+
+func (c ScanScheduleConflict) String() string {
+	return fmt.Sprintf("! %v on %v at %v: %v", c.Type, c.Day, c.StartTime, c.Reason)
+}
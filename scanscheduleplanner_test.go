@@ -0,0 +1,84 @@
+package Cx1ClientGo
+
+import (
+	"testing"
+
+	"golang.org/x/exp/slices"
+)
+
+func TestScanScheduleFromExpression(t *testing.T) {
+	tests := []struct {
+		expression string
+		frequency  string
+		days       []string
+		startTime  string
+		err        bool
+	}{
+		{"0 2 * * *", "daily", nil, "02:00", false},
+		{"0 2 * * 0-6", "daily", nil, "02:00", false},
+		{"30 22 * * mon-fri", "weekly", []string{"monday", "tuesday", "wednesday", "thursday", "friday"}, "22:30", false},
+		{"15 1 * * fri-mon", "weekly", []string{"monday", "friday", "saturday", "sunday"}, "01:15", false},
+		{"0 3 * * 7", "weekly", []string{"sunday"}, "03:00", false},
+		{"0 3 * * 1,3,sat", "weekly", []string{"monday", "wednesday", "saturday"}, "03:00", false},
+		{"@weekly", "weekly", []string{"sunday"}, "00:00", false},
+		{"@daily", "daily", nil, "00:00", false},
+		{"0 0 * * */2", "", nil, "", true},
+		{"0 0 */2 * *", "", nil, "", true},
+		{"0 0 * * 8", "", nil, "", true},
+		{"0 24 * * *", "", nil, "", true},
+		{"0 0 * *", "", nil, "", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.expression, func(t *testing.T) {
+			schedule, err := ScanScheduleFromExpression(test.expression)
+			if test.err {
+				if err == nil {
+					t.Fatalf("expected an error, got %v %v at %v", schedule.Frequency, schedule.Days, schedule.StartTime)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if schedule.Frequency != test.frequency || !slices.Equal(schedule.Days, test.days) || schedule.StartTime != test.startTime {
+				t.Errorf("got %v %v at %v, expected %v %v at %v", schedule.Frequency, schedule.Days, schedule.StartTime, test.frequency, test.days, test.startTime)
+			}
+		})
+	}
+}
+
+func TestScanScheduleExpressionRoundTrip(t *testing.T) {
+	tests := []struct {
+		expression string
+		expected   string
+	}{
+		{"0 2 * * *", "0 2 * * *"},
+		{"0 2 * * 0-6", "0 2 * * *"},
+		{"30 22 * * mon-fri", "30 22 * * 1,2,3,4,5"},
+		{"15 1 * * fri-mon", "15 1 * * 1,5,6,0"},
+		{"0 3 * * 7", "0 3 * * 0"},
+		{"@weekly", "0 0 * * 0"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.expression, func(t *testing.T) {
+			schedule, err := ScanScheduleFromExpression(test.expression)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			expression := schedule.Expression()
+			if expression != test.expected {
+				t.Errorf("got expression %v, expected %v", expression, test.expected)
+			}
+
+			parsed, err := ScanScheduleFromExpression(expression)
+			if err != nil {
+				t.Fatalf("failed to parse %v: %s", expression, err)
+			}
+			if parsed.Frequency != schedule.Frequency || !slices.Equal(parsed.Days, schedule.Days) || parsed.StartTime != schedule.StartTime {
+				t.Errorf("%v parsed as %v %v at %v, expected %v %v at %v", expression, parsed.Frequency, parsed.Days, parsed.StartTime, schedule.Frequency, schedule.Days, schedule.StartTime)
+			}
+		})
+	}
+}
@@ -0,0 +1,204 @@
+package Cx1ClientGo
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Returns all scan schedules of the tenant keyed by project name, which can be saved and later imported into this or another tenant.
+// Only one schedule is kept per project.
+func (c *Cx1Client) ExportScanSchedules() (ScanScheduleExport, error) {
+	export := ScanScheduleExport{Exported: time.Now(), Schedules: map[string]TenantScheduleState{}}
+
+	projects, schedules, err := c.getProjectScanSchedules()
+	if err != nil {
+		return export, err
+	}
+	for projectID, s := range schedules {
+		project, ok := projects[projectID]
+		if !ok {
+			c.config.Logger.Warnf("Scan schedule for unknown project %v is not exported", ShortenGUID(projectID))
+			continue
+		}
+		export.Schedules[project.Name] = *newTenantScheduleState(s)
+	}
+	return export, nil
+}
+
+// returns the projects by ID, and the current schedules by project ID
+func (c *Cx1Client) getProjectScanSchedules() (map[string]Project, map[string]ProjectScanSchedule, error) {
+	projects := map[string]Project{}
+	schedules := map[string]ProjectScanSchedule{}
+
+	list, err := c.GetAllProjects()
+	if err != nil {
+		return projects, schedules, fmt.Errorf("failed to get projects: %s", err)
+	}
+	for _, p := range list {
+		projects[p.ProjectID] = p
+	}
+
+	current, err := c.GetAllScanSchedules()
+	if err != nil {
+		return projects, schedules, fmt.Errorf("failed to get scan schedules: %s", err)
+	}
+	for _, s := range current {
+		if _, ok := schedules[s.ProjectID]; ok {
+			c.config.Logger.Warnf("Project %v has more than one scan schedule, only the first is used", ShortenGUID(s.ProjectID))
+			continue
+		}
+		schedules[s.ProjectID] = s
+	}
+	return projects, schedules, nil
+}
+
+// Loads scan schedules saved with ScanScheduleExport.Save, as JSON (.json) or YAML, eg:
+//
+//	schedules:
+//	  webshop-frontend: {startTime: "02:00", frequency: daily, active: true, engines: [sast, sca], branch: main}
+//	  webshop-backend: {startTime: "03:30", frequency: weekly, days: [saturday], active: true, engines: [sast], branch: develop}
+func LoadScanScheduleExport(filename string) (ScanScheduleExport, error) {
+	var export ScanScheduleExport
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return export, fmt.Errorf("failed to read scan schedules %v: %s", filename, err)
+	}
+
+	if strings.EqualFold(filepath.Ext(filename), ".json") {
+		err = json.Unmarshal(data, &export)
+	} else {
+		err = yaml.Unmarshal(data, &export)
+	}
+	if err != nil {
+		return export, fmt.Errorf("failed to parse scan schedules %v: %s", filename, err)
+	}
+	return export, nil
+}
+
+// Saves the scan schedules as JSON (.json) or YAML
+func (e ScanScheduleExport) Save(filename string) error {
+	var data []byte
+	var err error
+	if strings.EqualFold(filepath.Ext(filename), ".json") {
+		data, err = json.MarshalIndent(e, "", "  ")
+	} else {
+		data, err = yaml.Marshal(e)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to marshal scan schedules: %s", err)
+	}
+	if err := os.WriteFile(filename, data, 0644); err != nil {
+		return fmt.Errorf("failed to write scan schedules %v: %s", filename, err)
+	}
+	return nil
+}
+
+// Compares the desired scan schedules against the live tenant, resolving project names to IDs, without changing anything.
+// Projects with a desired schedule but none in the tenant are creates, projects with a schedule in the tenant but not in the
+// desired schedules are deletes, and schedules with a different start time, frequency, days, active flag, engines, branch or tags are updates.
+func (c *Cx1Client) GetScanScheduleDrift(desired ScanScheduleExport) (ScanScheduleDrift, error) {
+	drift := ScanScheduleDrift{Changes: []TenantChange{}, UnknownProjects: []string{}}
+
+	projects, schedules, err := c.getProjectScanSchedules()
+	if err != nil {
+		return drift, err
+	}
+	projectIDs := map[string]string{}
+	for _, p := range projects {
+		projectIDs[p.Name] = p.ProjectID
+	}
+
+	for _, name := range sortedKeys(desired.Schedules) {
+		projectID, ok := projectIDs[name]
+		if !ok {
+			drift.UnknownProjects = append(drift.UnknownProjects, name)
+			continue
+		}
+		want := desired.Schedules[name]
+		current, ok := schedules[projectID]
+		if !ok {
+			drift.add(TenantChangeActions.Create, name, projectID, diffSchedule(&TenantScheduleState{}, &want))
+		} else if fields := diffSchedule(newTenantScheduleState(current), &want); len(fields) > 0 {
+			drift.add(TenantChangeActions.Update, name, projectID, fields)
+		}
+	}
+
+	for _, projectID := range sortedKeys(schedules) {
+		name := projects[projectID].Name
+		if name == "" {
+			name = projectID // schedule of a project which is not visible
+		}
+		if _, ok := desired.Schedules[name]; !ok {
+			drift.add(TenantChangeActions.Delete, name, projectID, nil)
+		}
+	}
+
+	return drift, nil
+}
+
+func (d *ScanScheduleDrift) add(action, name, projectID string, fields []TenantFieldChange) {
+	d.Changes = append(d.Changes, TenantChange{
+		Action:       action,
+		ResourceType: TenantResourceTypes.ScanSchedule,
+		Name:         name,
+		ID:           projectID,
+		Fields:       fields,
+	})
+}
+
+// Brings the tenant's scan schedules in line with the desired schedules, see GetScanScheduleDrift.
+// Schedules for projects which do not exist in the tenant are skipped, and schedules not in the desired schedules are only deleted if prune is set.
+// Returns the drift which was corrected.
+func (c *Cx1Client) ImportScanSchedules(desired ScanScheduleExport, prune bool) (ScanScheduleDrift, error) {
+	drift, err := c.GetScanScheduleDrift(desired)
+	if err != nil {
+		return drift, err
+	}
+	for _, name := range drift.UnknownProjects {
+		c.config.Logger.Warnf("Scan schedule for project %v is not imported: the project does not exist", name)
+	}
+
+	for _, change := range drift.Changes {
+		schedule := desired.Schedules[change.Name].toScanSchedule()
+		switch change.Action {
+		case TenantChangeActions.Create:
+			err = c.CreateScanScheduleByID(change.ID, schedule)
+		case TenantChangeActions.Update:
+			err = c.UpdateScanScheduleByID(change.ID, schedule)
+		case TenantChangeActions.Delete:
+			if !prune {
+				continue
+			}
+			err = c.DeleteScanSchedulesByID(change.ID)
+		}
+		if err != nil {
+			return drift, fmt.Errorf("failed to %v scan schedule of project %v: %s", change.Action, change.Name, err)
+		}
+		c.config.Logger.Debugf("%v scan schedule of project %v", change.Action, change.Name)
+	}
+	return drift, nil
+}
+
+// Returns the drift in a human-readable form, one project per line followed by its field changes
+func (d ScanScheduleDrift) String() string {
+	var b strings.Builder
+	for _, c := range d.Changes {
+		b.WriteString(c.String())
+		b.WriteString("\n")
+		for _, f := range c.Fields {
+			fmt.Fprintf(&b, "    %v: %q -> %q\n", f.Field, f.Old, f.New)
+		}
+	}
+	for _, name := range d.UnknownProjects {
+		fmt.Fprintf(&b, "! project %v does not exist\n", name)
+	}
+	missing, changed, extra := TenantPlan{Changes: d.Changes}.Count()
+	fmt.Fprintf(&b, "Drift: %d missing, %d changed, %d extra, %d unknown projects", missing, changed, extra, len(d.UnknownProjects))
+	return b.String()
+}
@@ -0,0 +1,331 @@
+package Cx1ClientGo
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/exp/slices"
+)
+
+// Returns the packages identified by SCA in a scan, including direct/transitive and development/test scope information
+// and the dependency paths leading to each package
+func (c *Cx1Client) GetScanSCAPackagesByID(scanID string) ([]SCAPackage, error) {
+	c.config.Logger.Debugf("Get SCA packages for scan %v", scanID)
+	var packages []SCAPackage
+
+	response, err := c.sendRequest(http.MethodGet, fmt.Sprintf("/sca/risk-management/risk-reports/%v/packages", scanID), nil, nil)
+	if err != nil {
+		c.config.Logger.Tracef("Failed to fetch SCA packages for scan %v: %s", scanID, err)
+		return packages, fmt.Errorf("failed to fetch SCA packages for scan %v: %s", scanID, err)
+	}
+
+	err = json.Unmarshal(response, &packages)
+	return packages, err
+}
+
+// Returns the dependency graph for a scan, combining the SCA packages with the scan's SCA results
+func (c *Cx1Client) GetScanSCADependencyGraphByID(scanID string) (SCADependencyGraph, error) {
+	packages, err := c.GetScanSCAPackagesByID(scanID)
+	if err != nil {
+		return SCADependencyGraph{}, err
+	}
+
+	results, err := c.GetAllScanResultsByID(scanID)
+	if err != nil {
+		return SCADependencyGraph{}, err
+	}
+
+	return NewSCADependencyGraph(packages, results.SCA), nil
+}
+
+// Builds a dependency graph from SCA packages and results, eg: to work with previously-retrieved data
+func NewSCADependencyGraph(packages []SCAPackage, results []ScanSCAResult) SCADependencyGraph {
+	g := SCADependencyGraph{
+		Packages:     packages,
+		Results:      results,
+		packages:     make(map[string]int),
+		results:      make(map[string][]ScanSCAResult),
+		parents:      make(map[string][]string),
+		dependencies: make(map[string]SCAPackageDependency),
+	}
+
+	for id, p := range packages {
+		g.packages[p.PackageID] = id
+		for _, path := range p.DependencyPaths {
+			for i, d := range path {
+				if _, ok := g.dependencies[d.PackageID]; !ok {
+					g.dependencies[d.PackageID] = d
+				}
+				if i > 0 && !slices.Contains(g.parents[d.PackageID], path[i-1].PackageID) {
+					g.parents[d.PackageID] = append(g.parents[d.PackageID], path[i-1].PackageID)
+				}
+			}
+		}
+	}
+	for _, r := range results {
+		g.results[r.Data.PackageIdentifier] = append(g.results[r.Data.PackageIdentifier], r)
+	}
+	return g
+}
+
+func (g SCADependencyGraph) GetPackageByID(packageID string) *SCAPackage {
+	if id, ok := g.packages[packageID]; ok {
+		return &g.Packages[id]
+	}
+	return nil
+}
+
+// Returns all packages matching the name, across versions
+func (g SCADependencyGraph) GetPackagesByName(name string) []SCAPackage {
+	packages := []SCAPackage{}
+	for _, p := range g.Packages {
+		if strings.EqualFold(p.Name, name) {
+			packages = append(packages, p)
+		}
+	}
+	return packages
+}
+
+func (g SCADependencyGraph) GetDirectDependencies() []SCAPackage {
+	packages := []SCAPackage{}
+	for _, p := range g.Packages {
+		if p.IsDirectDependency {
+			packages = append(packages, p)
+		}
+	}
+	return packages
+}
+
+// Returns the SCA results (vulnerabilities) affecting a specific package
+func (g SCADependencyGraph) GetPackageResults(packageID string) []ScanSCAResult {
+	return g.results[packageID]
+}
+
+// Returns the packages with at least one vulnerability of the listed severities, or any severity if none are listed
+func (g SCADependencyGraph) GetVulnerablePackages(severities ...string) []SCAPackage {
+	packages := []SCAPackage{}
+	for _, p := range g.Packages {
+		for _, r := range g.results[p.PackageID] {
+			if len(severities) == 0 || slices.ContainsFunc(severities, func(s string) bool { return strings.EqualFold(s, r.Severity) }) {
+				packages = append(packages, p)
+				break
+			}
+		}
+	}
+	return packages
+}
+
+// Returns the dependency paths from direct dependencies to the package.
+// Paths reported for the package are used as-is, otherwise they are derived from the paths of the other packages in the graph.
+func (g SCADependencyGraph) GetDependencyPaths(packageID string) [][]SCAPackageDependency {
+	if p := g.GetPackageByID(packageID); p != nil && len(p.DependencyPaths) > 0 {
+		return p.DependencyPaths
+	}
+	return g.derivePaths(packageID, map[string]bool{})
+}
+
+// limits the number of paths derived for a package, as packages deep in large trees can be reached in very many ways
+const scaMaxDerivedPaths = 100
+
+func (g SCADependencyGraph) derivePaths(packageID string, visiting map[string]bool) [][]SCAPackageDependency {
+	paths := [][]SCAPackageDependency{}
+	self, ok := g.dependencies[packageID]
+	if p := g.GetPackageByID(packageID); p != nil {
+		self = SCAPackageDependency{PackageID: p.PackageID, Name: p.Name, Version: p.Version, IsResolved: true, IsDevelopment: p.IsDevelopmentDependency, Locations: p.Locations}
+		if p.IsDirectDependency {
+			paths = append(paths, []SCAPackageDependency{self})
+		}
+	} else if !ok {
+		return paths
+	}
+
+	visiting[packageID] = true
+	defer delete(visiting, packageID)
+	for _, parentID := range g.parents[packageID] {
+		if visiting[parentID] {
+			continue // dependency cycle
+		}
+		for _, path := range g.derivePaths(parentID, visiting) {
+			if len(paths) >= scaMaxDerivedPaths {
+				return paths
+			}
+			paths = append(paths, append(slices.Clone(path), self))
+		}
+	}
+	return paths
+}
+
+// Returns the IDs of the direct dependencies which pull in the package. A direct dependency returns itself.
+func (g SCADependencyGraph) GetDirectDependenciesPulling(packageID string) []string {
+	direct := []string{}
+	if p := g.GetPackageByID(packageID); p != nil && p.IsDirectDependency {
+		direct = append(direct, p.PackageID)
+	}
+
+	for _, path := range g.GetDependencyPaths(packageID) {
+		if len(path) > 0 && !slices.Contains(direct, path[0].PackageID) {
+			direct = append(direct, path[0].PackageID)
+		}
+	}
+	return direct
+}
+
+// Returns the dependency paths leading to each package with vulnerabilities of the listed severities (or any severity if none are listed), by package ID
+func (g SCADependencyGraph) GetVulnerableDependencyPaths(severities ...string) map[string][][]SCAPackageDependency {
+	paths := map[string][][]SCAPackageDependency{}
+	for _, p := range g.GetVulnerablePackages(severities...) {
+		paths[p.PackageID] = g.GetDependencyPaths(p.PackageID)
+	}
+	return paths
+}
+
+// Returns true if the package is only used for development or tests
+func (g SCADependencyGraph) IsDevOrTest(packageID string) bool {
+	if p := g.GetPackageByID(packageID); p != nil {
+		return p.IsDevelopmentDependency || p.IsTestDependency
+	}
+	return false
+}
+
+// Returns the package upgrades which fix the vulnerabilities of the listed severities (eg: "HIGH", "CRITICAL"), one per vulnerable package.
+// Each vulnerable package is upgraded to the highest recommended version across its vulnerabilities.
+// This is not a minimal set: the results do not show whether upgrading a direct dependency would remove a vulnerable transitive package,
+// so transitive packages are listed with the direct dependencies pulling them in (Via), which may need to be upgraded instead.
+// Vulnerabilities without a recommended version are listed as Unfixed, and those in Not Exploitable state are ignored.
+func (g SCADependencyGraph) GetPackageUpgrades(severities ...string) []SCAPackageUpgrade {
+	upgrades := map[string]*SCAPackageUpgrade{}
+
+	for _, r := range g.Results {
+		if len(severities) > 0 && !slices.ContainsFunc(severities, func(s string) bool { return strings.EqualFold(s, r.Severity) }) {
+			continue
+		}
+		if strings.EqualFold(r.State, "NOT_EXPLOITABLE") {
+			continue
+		}
+
+		packageID := r.Data.PackageIdentifier
+		upgrade, ok := upgrades[packageID]
+		if !ok {
+			_, name, version := r.Data.ParsePackageIdentifier()
+			upgrade = &SCAPackageUpgrade{
+				PackageID:      packageID,
+				Name:           name,
+				CurrentVersion: version,
+				IsDirect:       true,
+			}
+			if p := g.GetPackageByID(packageID); p != nil {
+				upgrade.Name = p.Name
+				upgrade.CurrentVersion = p.Version
+				upgrade.IsDirect = p.IsDirectDependency
+				if !p.IsDirectDependency {
+					upgrade.Via = g.GetDirectDependenciesPulling(packageID)
+				}
+			}
+			upgrades[packageID] = upgrade
+		}
+
+		vulnID := r.scaVulnerabilityID()
+		if r.Data.RecommendedVersion == "" {
+			if !slices.Contains(upgrade.Unfixed, vulnID) {
+				upgrade.Unfixed = append(upgrade.Unfixed, vulnID)
+			}
+			continue
+		}
+		if !slices.Contains(upgrade.Fixes, vulnID) {
+			upgrade.Fixes = append(upgrade.Fixes, vulnID)
+		}
+		if CompareVersions(r.Data.RecommendedVersion, upgrade.TargetVersion) > 0 {
+			upgrade.TargetVersion = r.Data.RecommendedVersion
+		}
+	}
+
+	set := []SCAPackageUpgrade{}
+	for _, u := range upgrades {
+		set = append(set, *u)
+	}
+	sort.Slice(set, func(i, j int) bool {
+		return set[i].PackageID < set[j].PackageID
+	})
+	return set
+}
+
+// Compares two version strings segment by segment, numerically where possible
+// returns -1 (a lower), 0 (equal), 1 (a greater). An empty version is lower than any other version.
+// Pre-releases sort before the release, eg: 1.0.0-beta is lower than 1.0.0
+func CompareVersions(a, b string) int {
+	if a == "" || b == "" {
+		return strings.Compare(a, b)
+	}
+	split := func(v string) []string {
+		return strings.FieldsFunc(strings.TrimPrefix(strings.ToLower(v), "v"), func(r rune) bool {
+			return r == '.' || r == '-' || r == '+' || r == '_'
+		})
+	}
+	pa, pb := split(a), split(b)
+	isNumeric := func(segment string) bool {
+		_, err := strconv.ParseUint(segment, 10, 64)
+		return err == nil
+	}
+
+	for i := 0; i < len(pa) || i < len(pb); i++ {
+		if i >= len(pa) { // a is a release if b continues with a pre-release label
+			if isNumeric(pb[i]) {
+				return -1
+			}
+			return 1
+		}
+		if i >= len(pb) {
+			if isNumeric(pa[i]) {
+				return 1
+			}
+			return -1
+		}
+
+		na, erra := strconv.ParseUint(pa[i], 10, 64)
+		nb, errb := strconv.ParseUint(pb[i], 10, 64)
+		switch {
+		case erra == nil && errb == nil:
+			if na != nb {
+				if na < nb {
+					return -1
+				}
+				return 1
+			}
+		case erra == nil: // numeric segments sort after pre-release labels like "beta"
+			return 1
+		case errb == nil:
+			return -1
+		default:
+			if c := strings.Compare(pa[i], pb[i]); c != 0 {
+				return c
+			}
+		}
+	}
+	return 0
+}
+
+func (p SCAPackage) String() string {
+	scope := "transitive"
+	if p.IsDirectDependency {
+		scope = "direct"
+	}
+	if p.IsDevelopmentDependency || p.IsTestDependency {
+		scope += " dev/test"
+	}
+	return fmt.Sprintf("%v %v (%v) - %d high, %d medium, %d low", p.Name, p.Version, scope, p.HighVulnerabilityCount, p.MediumVulnerabilityCount, p.LowVulnerabilityCount)
+}
+
+func (u SCAPackageUpgrade) String() string {
+	target := u.TargetVersion
+	if target == "" {
+		target = "[no fix available]"
+	}
+	if u.IsDirect {
+		return fmt.Sprintf("%v %v -> %v fixes %v", u.Name, u.CurrentVersion, target, strings.Join(u.Fixes, ", "))
+	}
+	return fmt.Sprintf("%v %v -> %v (via %v) fixes %v", u.Name, u.CurrentVersion, target, strings.Join(u.Via, ", "), strings.Join(u.Fixes, ", "))
+}
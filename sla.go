@@ -0,0 +1,308 @@
+package Cx1ClientGo
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/exp/slices"
+)
+
+var SLAStatuses = struct {
+	WithinSLA string
+	DueSoon   string
+	Overdue   string
+}{"within-sla", "due-soon", "overdue"}
+
+// How SLAReport.GroupBy groups findings
+var SLAGroupings = struct {
+	Project     string
+	Application string
+	Group       string
+}{"project", "application", "group"}
+
+// Creates a policy with the deadlines in days by severity (eg: {"Critical": 7, "High": 30, "Medium": 90}),
+// a 7-day warning period, and Not Exploitable and Proposed Not Exploitable results excluded
+func NewSLAPolicy(days map[string]int) SLAPolicy {
+	return SLAPolicy{
+		Days:               days,
+		CriticalityFactors: map[uint]float64{},
+		ExcludedStates:     []string{"NOT_EXPLOITABLE", "PROPOSED_NOT_EXPLOITABLE"},
+		WarningDays:        7,
+	}
+}
+
+// Loads a policy from a JSON file, eg: {"days": {"Critical": 7, "High": 30}, "criticalityFactors": {"5": 0.5}, "warningDays": 7}
+func LoadSLAPolicy(filename string) (SLAPolicy, error) {
+	var policy SLAPolicy
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return policy, fmt.Errorf("failed to read SLA policy %v: %s", filename, err)
+	}
+	if err = json.Unmarshal(data, &policy); err != nil {
+		return policy, fmt.Errorf("failed to parse SLA policy %v: %s", filename, err)
+	}
+	return policy, nil
+}
+
+// Returns the time allowed to fix a result of the severity in a project with the criticality, and false if the severity has no deadline
+func (p SLAPolicy) Deadline(severity string, criticality uint) (time.Duration, bool) {
+	for s, days := range p.Days {
+		if strings.EqualFold(s, severity) {
+			factor := 1.0
+			if f, ok := p.CriticalityFactors[criticality]; ok && f > 0 {
+				factor = f
+			}
+			return time.Duration(float64(days) * factor * float64(24*time.Hour)), true
+		}
+	}
+	return 0, false
+}
+
+// Evaluates the results of the project's last completed scan on its main branch (or the branch specified) for each project
+// Application and group IDs in the findings are replaced with application names and group paths.
+func (c *Cx1Client) GetSLAReport(policy SLAPolicy, projects []Project, branch string) (SLAReport, error) {
+	report := SLAReport{
+		Generated: time.Now(),
+		Policy:    policy,
+		Findings:  []SLAFinding{},
+	}
+
+	applications, err := c.GetAllApplications()
+	if err != nil {
+		return report, err
+	}
+	applicationNames := make(map[string]string, len(applications))
+	for _, a := range applications {
+		applicationNames[a.ApplicationID] = a.Name
+	}
+	groupPaths := map[string]string{} // by group ID
+
+	for _, p := range projects {
+		filter := ScanFilter{
+			BaseFilter: BaseFilter{Limit: 1},
+			ProjectID:  p.ProjectID,
+			Statuses:   []string{ScanStatus.Completed, ScanStatus.Partial},
+			Sort:       []string{ScanSortCreatedDescending},
+		}
+		if branch != "" {
+			filter.Branches = []string{branch}
+		} else if p.MainBranch != "" {
+			filter.Branches = []string{p.MainBranch}
+		}
+
+		_, scans, err := c.GetScansFiltered(filter)
+		if err != nil {
+			return report, err
+		}
+		if len(scans) == 0 {
+			c.config.Logger.Debugf("Project %v has no completed scans, skipping SLA evaluation", p.String())
+			continue
+		}
+
+		results, err := c.GetAllScanResultsByID(scans[0].ScanID)
+		if err != nil {
+			return report, err
+		}
+
+		groups := make([]string, 0, len(p.Groups))
+		for _, groupID := range p.Groups {
+			path, ok := groupPaths[groupID]
+			if !ok {
+				group, err := c.GetGroupByID(groupID)
+				if err != nil {
+					return report, fmt.Errorf("failed to get group %v of project %v: %s", ShortenGUID(groupID), p.String(), err)
+				}
+				path = group.Path
+				groupPaths[groupID] = path
+			}
+			groups = append(groups, path)
+		}
+
+		findings := policy.EvaluateResults(p, results, report.Generated)
+		for i := range findings {
+			for j, id := range findings[i].Applications {
+				if name, ok := applicationNames[id]; ok {
+					findings[i].Applications[j] = name
+				}
+			}
+			findings[i].Groups = slices.Clone(groups)
+		}
+		report.Findings = append(report.Findings, findings...)
+	}
+
+	report.Sort()
+	return report, nil
+}
+
+// Evaluates open results against the policy at the time now, using the result FirstFoundAt and the project criticality
+// Fixed results, results in excluded states and results with severities not in the policy are skipped
+func (p SLAPolicy) EvaluateResults(project Project, results ScanResultSet, now time.Time) []SLAFinding {
+	findings := []SLAFinding{}
+
+	evaluate := func(r ScanResultBase, engine, title string) {
+		if strings.EqualFold(r.Status, "FIXED") {
+			return
+		}
+		if slices.ContainsFunc(p.ExcludedStates, func(s string) bool { return strings.EqualFold(s, r.State) }) {
+			return
+		}
+		deadline, ok := p.Deadline(r.Severity, project.Criticality)
+		if !ok {
+			return
+		}
+
+		found := r.FirstFoundAt
+		if found.IsZero() {
+			found = r.CreatedAt
+		}
+		f := SLAFinding{
+			ProjectID:    project.ProjectID,
+			ProjectName:  project.Name,
+			Applications: []string{},
+			Groups:       project.Groups,
+			ScanID:       r.ScanID,
+			Engine:       engine,
+			SimilarityID: r.SimilarityID,
+			Title:        title,
+			Severity:     r.Severity,
+			State:        r.State,
+			FirstFoundAt: found,
+			DueDate:      found.Add(deadline),
+		}
+		if project.Applications != nil {
+			f.Applications = append(f.Applications, *project.Applications...)
+		}
+
+		f.DaysRemaining = int(math.Floor(f.DueDate.Sub(now).Hours() / 24))
+		switch {
+		case now.After(f.DueDate):
+			f.SLAStatus = SLAStatuses.Overdue
+		case f.DaysRemaining < p.WarningDays:
+			f.SLAStatus = SLAStatuses.DueSoon
+		default:
+			f.SLAStatus = SLAStatuses.WithinSLA
+		}
+		findings = append(findings, f)
+	}
+
+	for _, r := range results.SAST {
+		title := r.Data.QueryName
+		if len(r.Data.Nodes) > 0 {
+			title = fmt.Sprintf("%v in %v", r.Data.QueryName, r.Data.Nodes[0].FileName)
+		}
+		evaluate(r.ScanResultBase, "sast", title)
+	}
+	for _, r := range results.IAC {
+		evaluate(r.ScanResultBase, "iac", fmt.Sprintf("%v in %v", r.Data.QueryName, r.Data.FileName))
+	}
+	for _, r := range results.SCA {
+		evaluate(r.ScanResultBase, "sca", fmt.Sprintf("%v in %v", r.scaVulnerabilityID(), r.Data.PackageIdentifier))
+	}
+	for _, r := range results.SCAContainer {
+		evaluate(r.ScanResultBase, "sca-container", fmt.Sprintf("%v in %v %v", r.VulnerabilityDetails.CveName, r.Data.PackageName, r.Data.PackageVersion))
+	}
+	for _, r := range results.Containers {
+		evaluate(r.ScanResultBase, "containers", fmt.Sprintf("%v in %v %v (%v)", r.VulnerabilityDetails.CveName, r.Data.PackageName, r.Data.PackageVersion, r.Data.ImageName))
+	}
+
+	return findings
+}
+
+// Sorts findings by due date, earliest first
+func (r *SLAReport) Sort() {
+	sort.SliceStable(r.Findings, func(i, j int) bool {
+		return r.Findings[i].DueDate.Before(r.Findings[j].DueDate)
+	})
+}
+
+// Returns the findings which are past their due date
+func (r SLAReport) Overdue() []SLAFinding {
+	return r.filter(func(f SLAFinding) bool { return f.SLAStatus == SLAStatuses.Overdue })
+}
+
+// Returns the findings which are due within the policy's warning period
+func (r SLAReport) DueSoon() []SLAFinding {
+	return r.filter(func(f SLAFinding) bool { return f.SLAStatus == SLAStatuses.DueSoon })
+}
+
+// Returns the findings which are not overdue yet, but will be within the number of days
+func (r SLAReport) BreachingWithin(days int) []SLAFinding {
+	return r.filter(func(f SLAFinding) bool { return f.SLAStatus != SLAStatuses.Overdue && f.DaysRemaining < days })
+}
+
+func (r SLAReport) filter(match func(SLAFinding) bool) []SLAFinding {
+	findings := []SLAFinding{}
+	for _, f := range r.Findings {
+		if match(f) {
+			findings = append(findings, f)
+		}
+	}
+	return findings
+}
+
+// Groups the findings by project name, application name or group path (group ID for findings from EvaluateResults), see SLAGroupings
+// A finding is included in each of its applications or groups, and under an empty key if it has none
+func (r SLAReport) GroupBy(grouping string) map[string][]SLAFinding {
+	groups := map[string][]SLAFinding{}
+	for _, f := range r.Findings {
+		var keys []string
+		switch grouping {
+		case SLAGroupings.Application:
+			keys = f.Applications
+		case SLAGroupings.Group:
+			keys = f.Groups
+		default:
+			keys = []string{f.ProjectName}
+		}
+		if len(keys) == 0 {
+			keys = []string{""}
+		}
+		for _, k := range keys {
+			groups[k] = append(groups[k], f)
+		}
+	}
+	return groups
+}
+
+func (r SLAReport) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
+
+// Writes the findings as CSV with a header row, applications and groups are separated by ';'
+func (r SLAReport) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	_ = writer.Write([]string{"Project", "Project ID", "Applications", "Groups", "Engine", "Title", "Severity", "State", "Similarity ID", "First Found", "Due Date", "Days Remaining", "SLA Status"})
+	for _, f := range r.Findings {
+		_ = writer.Write([]string{
+			f.ProjectName,
+			f.ProjectID,
+			strings.Join(f.Applications, ";"),
+			strings.Join(f.Groups, ";"),
+			f.Engine,
+			f.Title,
+			f.Severity,
+			f.State,
+			f.SimilarityID,
+			f.FirstFoundAt.Format(time.RFC3339),
+			f.DueDate.Format(time.RFC3339),
+			strconv.Itoa(f.DaysRemaining),
+			f.SLAStatus,
+		})
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+func (f SLAFinding) String() string {
+	return fmt.Sprintf("[%v] %v - %v (%v) due %v (%d days)", f.SLAStatus, f.ProjectName, f.Title, f.Severity, f.DueDate.Format("2006-01-02"), f.DaysRemaining)
+}
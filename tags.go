@@ -0,0 +1,354 @@
+package Cx1ClientGo
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+)
+
+// The tag operations supported by PlanTagOperations
+var TagActions = struct {
+	Rename    string
+	Retype    string
+	Add       string
+	Remove    string
+	Normalize string
+}{
+	Rename:    "rename",
+	Retype:    "retype",
+	Add:       "add",
+	Remove:    "remove",
+	Normalize: "normalize",
+}
+
+// Applies the operations in order to a copy of the tags and returns the result:
+//   - Rename moves the value of Key to NewKey, keeping the existing value if NewKey is already set
+//   - Retype replaces the values of Key (or of every key if Key is empty) using the Values mapping
+//   - Add sets Key to Value
+//   - Remove deletes Key, or only if it has Value when Value is set
+//   - Normalize trims whitespace from keys and values, and changes the case of keys (Case) and values (ValueCase); where keys collide, the first in sorted order is kept
+func ApplyTagOperations(tags map[string]string, operations []TagOperation) (map[string]string, error) {
+	result := map[string]string{}
+	for k, v := range tags {
+		result[k] = v
+	}
+
+	for _, op := range operations {
+		switch op.Action {
+		case TagActions.Rename:
+			if value, ok := result[op.Key]; ok {
+				delete(result, op.Key)
+				if _, exists := result[op.NewKey]; !exists {
+					result[op.NewKey] = value
+				}
+			}
+		case TagActions.Retype:
+			for k, v := range result {
+				if op.Key != "" && k != op.Key {
+					continue
+				}
+				if newValue, ok := op.Values[v]; ok {
+					result[k] = newValue
+				}
+			}
+		case TagActions.Add:
+			result[op.Key] = op.Value
+		case TagActions.Remove:
+			if value, ok := result[op.Key]; ok && (op.Value == "" || value == op.Value) {
+				delete(result, op.Key)
+			}
+		case TagActions.Normalize:
+			normalized := map[string]string{}
+			for _, k := range sortedKeys(result) {
+				key, err := normalizeTagCase(strings.TrimSpace(k), op.Case)
+				if err != nil {
+					return result, err
+				}
+				value, err := normalizeTagCase(strings.TrimSpace(result[k]), op.ValueCase)
+				if err != nil {
+					return result, err
+				}
+				if _, exists := normalized[key]; !exists {
+					normalized[key] = value
+				}
+			}
+			result = normalized
+		default:
+			return result, fmt.Errorf("unknown tag action %v", op.Action)
+		}
+	}
+	return result, nil
+}
+
+func normalizeTagCase(s, c string) (string, error) {
+	switch c {
+	case "lower":
+		return strings.ToLower(s), nil
+	case "upper":
+		return strings.ToUpper(s), nil
+	case "":
+		return s, nil
+	}
+	return s, fmt.Errorf("unknown case %v for tag normalization", c)
+}
+
+// Plans the tag operations for every resource matched by the selector, without changing anything (a dry run).
+// The plan can be applied with ApplyTagPlan.
+func (c *Cx1Client) PlanTagOperations(selector TagSelector, operations []TagOperation) (TagPlan, error) {
+	plan := TagPlan{Operations: operations, Changes: []TagChange{}}
+
+	var nameRegex *regexp.Regexp
+	if selector.NameRegex != "" {
+		var err error
+		if nameRegex, err = regexp.Compile(selector.NameRegex); err != nil {
+			return plan, fmt.Errorf("invalid name regex %v: %s", selector.NameRegex, err)
+		}
+	}
+	resourceTypes := selector.ResourceTypes
+	if len(resourceTypes) == 0 {
+		resourceTypes = []string{TenantResourceTypes.Project, TenantResourceTypes.Application}
+	}
+
+	// projects are needed for projects, scans and schedules
+	projectsByID := map[string]Project{}
+	needsProjects := slices.ContainsFunc(resourceTypes, func(t string) bool { return t != TenantResourceTypes.Application })
+	if needsProjects {
+		projects, err := c.GetAllProjects()
+		if err != nil {
+			return plan, fmt.Errorf("failed to get projects: %s", err)
+		}
+		for _, p := range projects {
+			projectsByID[p.ProjectID] = p
+		}
+	}
+
+	projectSelected := func(projectID string) bool {
+		p, ok := projectsByID[projectID]
+		if !ok {
+			return false
+		}
+		if nameRegex != nil && !nameRegex.MatchString(p.Name) {
+			return false
+		}
+		if len(selector.Groups) > 0 && !slices.ContainsFunc(p.Groups, func(g string) bool { return slices.Contains(selector.Groups, g) }) {
+			return false
+		}
+		if len(selector.Applications) > 0 && (p.Applications == nil || !slices.ContainsFunc(*p.Applications, func(a string) bool { return slices.Contains(selector.Applications, a) })) {
+			return false
+		}
+		return true
+	}
+
+	addChange := func(resourceType, id, name string, tags map[string]string, schedule *ProjectScanSchedule) error {
+		if !tagsMatch(tags, selector.Tags) {
+			return nil
+		}
+		newTags, err := ApplyTagOperations(tags, operations)
+		if err != nil {
+			return err
+		}
+		if maps.Equal(newTags, tags) {
+			return nil
+		}
+		if tags == nil {
+			tags = map[string]string{}
+		}
+		plan.Changes = append(plan.Changes, TagChange{ResourceType: resourceType, ID: id, Name: name, Old: tags, New: newTags, Schedule: schedule})
+		return nil
+	}
+
+	for _, resourceType := range resourceTypes {
+		switch resourceType {
+		case TenantResourceTypes.Project:
+			for _, id := range sortedKeys(projectsByID) {
+				p := projectsByID[id]
+				if projectSelected(id) {
+					if err := addChange(resourceType, p.ProjectID, p.Name, p.Tags, nil); err != nil {
+						return plan, err
+					}
+				}
+			}
+		case TenantResourceTypes.Application:
+			if len(selector.Groups) > 0 {
+				continue // applications are not in groups
+			}
+			applications, err := c.GetAllApplications()
+			if err != nil {
+				return plan, fmt.Errorf("failed to get applications: %s", err)
+			}
+			for _, a := range applications {
+				if nameRegex != nil && !nameRegex.MatchString(a.Name) {
+					continue
+				}
+				if len(selector.Applications) > 0 && !slices.Contains(selector.Applications, a.ApplicationID) {
+					continue
+				}
+				if err := addChange(resourceType, a.ApplicationID, a.Name, a.Tags, nil); err != nil {
+					return plan, err
+				}
+			}
+		case TenantResourceTypes.Scan:
+			_, scans, err := c.GetAllScansFiltered(ScanFilter{
+				BaseFilter: BaseFilter{Limit: c.config.Pagination.Scans},
+				FromDate:   selector.ScansFrom,
+			})
+			if err != nil {
+				return plan, fmt.Errorf("failed to get scans: %s", err)
+			}
+			for _, s := range scans {
+				if projectSelected(s.ProjectID) {
+					if err := addChange(resourceType, s.ScanID, fmt.Sprintf("%v scan %v", s.ProjectName, ShortenGUID(s.ScanID)), s.Tags, nil); err != nil {
+						return plan, err
+					}
+				}
+			}
+		case TenantResourceTypes.ScanSchedule:
+			schedules, err := c.GetAllScanSchedules()
+			if err != nil {
+				return plan, fmt.Errorf("failed to get scan schedules: %s", err)
+			}
+			for i := range schedules {
+				s := schedules[i]
+				if projectSelected(s.ProjectID) {
+					if err := addChange(resourceType, s.ProjectID, projectsByID[s.ProjectID].Name+" schedule", s.Tags, &s); err != nil {
+						return plan, err
+					}
+				}
+			}
+		default:
+			return plan, fmt.Errorf("tags can not be changed on resource type %v", resourceType)
+		}
+	}
+
+	c.config.Logger.Debugf("Planned tag changes for %d resources", len(plan.Changes))
+	return plan, nil
+}
+
+func tagsMatch(tags, required map[string]string) bool {
+	for k, v := range required {
+		value, ok := tags[k]
+		if !ok || (v != "" && value != v) {
+			return false
+		}
+	}
+	return true
+}
+
+// Applies the planned tag changes using up to workers concurrent requests (default 4), and returns the undo plan.
+// The undo plan contains only the changes which were applied, sorted by resource type, name and ID, and can itself be applied with ApplyTagPlan to revert them.
+// All changes are attempted, and the error lists those which failed.
+func (c *Cx1Client) ApplyTagPlan(plan TagPlan, workers int) (TagPlan, error) {
+	undo := TagPlan{Operations: []TagOperation{}, Changes: []TagChange{}}
+	failures := []string{}
+	errs := c.runConcurrently(len(plan.Changes), workers, func(i int) error {
+		return c.applyTagChange(plan.Changes[i])
+	})
+	for i, err := range errs {
+		change := plan.Changes[i]
+		if err != nil {
+			c.config.Logger.Warnf("Failed to update tags of %v %v: %s", change.ResourceType, change.Name, err)
+			failures = append(failures, fmt.Sprintf("%v %v: %s", change.ResourceType, change.Name, err))
+		} else {
+			undo.Changes = append(undo.Changes, change.Undo())
+		}
+	}
+
+	sort.SliceStable(undo.Changes, func(i, j int) bool {
+		a, b := undo.Changes[i], undo.Changes[j]
+		if a.ResourceType != b.ResourceType {
+			return a.ResourceType < b.ResourceType
+		}
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.ID < b.ID
+	})
+
+	if len(failures) > 0 {
+		return undo, fmt.Errorf("failed to apply %d tag changes: %v", len(failures), strings.Join(failures, "; "))
+	}
+	return undo, nil
+}
+
+func (c *Cx1Client) applyTagChange(change TagChange) error {
+	tags := change.New
+	switch change.ResourceType {
+	case TenantResourceTypes.Project:
+		return c.PatchProjectByID(change.ID, ProjectPatch{Tags: &tags})
+	case TenantResourceTypes.Application:
+		return c.PatchApplicationByID(change.ID, ApplicationPatch{Tags: &tags})
+	case TenantResourceTypes.Scan:
+		return c.UpdateScanTagsByID(change.ID, tags)
+	case TenantResourceTypes.ScanSchedule:
+		if change.Schedule == nil {
+			return fmt.Errorf("missing scan schedule")
+		}
+		schedule := *change.Schedule
+		if schedule.StartTime == "" {
+			schedule.StartTime = schedule.NextStartTime.Format("15:04")
+		}
+		schedule.Tags = tags
+		return c.UpdateScanScheduleByID(change.ID, schedule)
+	}
+	return fmt.Errorf("tags can not be changed on resource type %v", change.ResourceType)
+}
+
+// Returns the change which reverts this change
+func (t TagChange) Undo() TagChange {
+	t.Old, t.New = t.New, t.Old
+	return t
+}
+
+// Returns the plan which reverts this plan, with the changes in reverse order
+func (p TagPlan) Undo() TagPlan {
+	undo := TagPlan{Operations: []TagOperation{}, Changes: []TagChange{}}
+	for i := len(p.Changes) - 1; i >= 0; i-- {
+		undo.Changes = append(undo.Changes, p.Changes[i].Undo())
+	}
+	return undo
+}
+
+// Saves the plan as JSON, eg: to keep the undo log returned by ApplyTagPlan
+func (p TagPlan) Save(filename string) error {
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal tag plan: %s", err)
+	}
+	if err := os.WriteFile(filename, data, 0644); err != nil {
+		return fmt.Errorf("failed to write tag plan %v: %s", filename, err)
+	}
+	return nil
+}
+
+// Loads a plan or undo log saved with TagPlan.Save
+func LoadTagPlan(filename string) (TagPlan, error) {
+	var plan TagPlan
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return plan, fmt.Errorf("failed to read tag plan %v: %s", filename, err)
+	}
+	if err := json.Unmarshal(data, &plan); err != nil {
+		return plan, fmt.Errorf("failed to parse tag plan %v: %s", filename, err)
+	}
+	return plan, nil
+}
+
+// Returns the plan in a human-readable form, one resource per line
+func (p TagPlan) String() string {
+	lines := []string{}
+	for _, c := range p.Changes {
+		lines = append(lines, c.String())
+	}
+	lines = append(lines, fmt.Sprintf("Plan: %d tag changes", len(p.Changes)))
+	return strings.Join(lines, "\n")
+}
+
+func (t TagChange) String() string {
+	return fmt.Sprintf("~ %v %v: [%v] -> [%v]", t.ResourceType, t.Name, tagString(t.Old), tagString(t.New))
+}
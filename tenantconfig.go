@@ -0,0 +1,871 @@
+package Cx1ClientGo
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/exp/slices"
+	"gopkg.in/yaml.v3"
+)
+
+var TenantChangeActions = struct {
+	Create string
+	Update string
+	Delete string
+}{"create", "update", "delete"}
+
+var TenantResourceTypes = struct {
+	Group                string
+	Preset               string
+	Application          string
+	Project              string
+	ProjectConfiguration string
+	ScanSchedule         string
+	Scan                 string
+}{"group", "preset", "application", "project", "project-configuration", "scan-schedule", "scan"}
+
+// the current tenant state, indexed for comparison against a TenantState
+type tenantCurrentState struct {
+	groups           map[string]Group // by path
+	groupPaths       map[string]string
+	presets          map[string]Preset // by name
+	applications     map[string]Application
+	applicationNames map[string]string
+	projects         map[string]Project
+	schedules        map[string]ProjectScanSchedule // by project ID
+}
+
+// Loads a desired state from a JSON (.json) or YAML file, eg:
+//
+//	groups:
+//	  - path: /appsec
+//	applications:
+//	  - name: webshop
+//	    criticality: 4
+//	projects:
+//	  - name: webshop-frontend
+//	    groups: [/appsec]
+//	    applications: [webshop]
+//	    configuration:
+//	      scan.config.sast.presetName: ASA Premium
+//	    schedule: {startTime: "02:00", frequency: daily, active: true, engines: [sast], branch: main}
+func LoadTenantState(filename string) (TenantState, error) {
+	var state TenantState
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return state, fmt.Errorf("failed to read tenant state %v: %s", filename, err)
+	}
+
+	if strings.EqualFold(filepath.Ext(filename), ".json") {
+		err = json.Unmarshal(data, &state)
+	} else {
+		err = yaml.Unmarshal(data, &state)
+	}
+	if err != nil {
+		return state, fmt.Errorf("failed to parse tenant state %v: %s", filename, err)
+	}
+	return state, nil
+}
+
+// Saves the state as JSON (.json) or YAML
+func (s TenantState) Save(filename string) error {
+	var data []byte
+	var err error
+	if strings.EqualFold(filepath.Ext(filename), ".json") {
+		data, err = json.MarshalIndent(s, "", "  ")
+	} else {
+		data, err = yaml.Marshal(s)
+	}
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filename, data, 0644)
+}
+
+// Returns the current tenant configuration in the desired-state format, which can be saved as a starting point
+// Only custom presets and project-level configuration settings are included. Scan schedules require v3.44+
+func (c *Cx1Client) GetTenantState() (TenantState, error) {
+	state := TenantState{
+		Groups:       []TenantGroupState{},
+		Presets:      []TenantPresetState{},
+		Applications: []TenantApplicationState{},
+		Projects:     []TenantProjectState{},
+	}
+
+	current, err := c.getTenantCurrentState(true, true)
+	if err != nil {
+		return state, err
+	}
+
+	for _, path := range sortedKeys(current.groups) {
+		state.Groups = append(state.Groups, TenantGroupState{Path: path})
+	}
+
+	for _, name := range sortedKeys(current.presets) {
+		preset := current.presets[name]
+		if !preset.Custom {
+			continue
+		}
+		if err := c.GetPresetContents(&preset); err != nil {
+			return state, fmt.Errorf("failed to get contents of preset %v: %s", name, err)
+		}
+		state.Presets = append(state.Presets, TenantPresetState{
+			Name:        preset.Name,
+			Description: preset.Description,
+			QueryIDs:    presetQueryIDs(preset),
+		})
+	}
+
+	for _, name := range sortedKeys(current.applications) {
+		app := current.applications[name]
+		state.Applications = append(state.Applications, TenantApplicationState{
+			Name:        app.Name,
+			Description: app.Description,
+			Criticality: &app.Criticality,
+			Tags:        app.Tags,
+		})
+	}
+
+	for _, name := range sortedKeys(current.projects) {
+		project := current.projects[name]
+		p := TenantProjectState{
+			Name:          project.Name,
+			Groups:        current.projectGroupPaths(project),
+			Applications:  current.projectApplicationNames(project),
+			Tags:          project.Tags,
+			RepoUrl:       project.RepoUrl,
+			MainBranch:    project.MainBranch,
+			Criticality:   &project.Criticality,
+			Configuration: map[string]string{},
+		}
+
+		settings, err := c.GetProjectConfigurationByID(project.ProjectID)
+		if err != nil {
+			return state, fmt.Errorf("failed to get configuration of project %v: %s", project.String(), err)
+		}
+		for _, s := range settings {
+			if s.OriginLevel == "Project" {
+				p.Configuration[s.Key] = s.Value
+			}
+		}
+
+		if schedule, ok := current.schedules[project.ProjectID]; ok {
+			p.Schedule = newTenantScheduleState(schedule)
+		}
+		state.Projects = append(state.Projects, p)
+	}
+
+	return state, nil
+}
+
+// Compares the desired state against the current tenant configuration and returns the changes required, in the order they will be applied.
+// Resources in the tenant which are not in the desired state are deleted only if prune is set, and only for resource types listed in the desired state.
+// Pruning presets only deletes custom presets, and pruning projects also deletes scan schedules of listed projects which have none in the desired state.
+func (c *Cx1Client) PlanTenantState(desired TenantState, prune bool) (TenantPlan, error) {
+	plan := TenantPlan{
+		Desired: desired,
+		Prune:   prune,
+		Changes: []TenantChange{},
+	}
+
+	loadSchedules := false
+	for _, p := range desired.Projects {
+		if p.Schedule != nil || prune {
+			loadSchedules = true
+		}
+	}
+
+	current, err := c.getTenantCurrentState(desired.Presets != nil, loadSchedules)
+	if err != nil {
+		return plan, err
+	}
+
+	// groups, including the parents of listed groups and groups assigned to projects
+	desiredGroups := desiredGroupPaths(desired)
+	for _, path := range desiredGroups {
+		if _, ok := current.groups[path]; !ok {
+			plan.add(TenantChangeActions.Create, TenantResourceTypes.Group, path, "", nil)
+		}
+	}
+
+	for _, p := range desired.Presets {
+		preset, ok := current.presets[p.Name]
+		fields := []TenantFieldChange{}
+		if !ok {
+			fields = diffField(fields, "description", "", p.Description)
+			if len(p.QueryIDs) > 0 {
+				fields = diffField(fields, "queries", "", fmt.Sprintf("%d queries", len(p.QueryIDs)))
+			}
+			plan.add(TenantChangeActions.Create, TenantResourceTypes.Preset, p.Name, "", fields)
+			continue
+		}
+
+		fields = diffField(fields, "description", preset.Description, p.Description)
+		if len(p.QueryIDs) > 0 {
+			if err := c.GetPresetContents(&preset); err != nil {
+				return plan, fmt.Errorf("failed to get contents of preset %v: %s", p.Name, err)
+			}
+			fields = diffQueryIDs(fields, presetQueryIDs(preset), p.QueryIDs)
+		}
+		plan.add(TenantChangeActions.Update, TenantResourceTypes.Preset, p.Name, preset.PresetID, fields)
+	}
+
+	for _, a := range desired.Applications {
+		app, ok := current.applications[a.Name]
+		fields := []TenantFieldChange{}
+		if a.Description != "" {
+			fields = diffField(fields, "description", app.Description, a.Description)
+		}
+		if a.Criticality != nil {
+			fields = diffField(fields, "criticality", uintString(ok, app.Criticality), strconv.FormatUint(uint64(*a.Criticality), 10))
+		}
+		if a.Tags != nil {
+			fields = diffField(fields, "tags", tagString(app.Tags), tagString(a.Tags))
+		}
+
+		if ok {
+			plan.add(TenantChangeActions.Update, TenantResourceTypes.Application, a.Name, app.ApplicationID, fields)
+		} else {
+			plan.add(TenantChangeActions.Create, TenantResourceTypes.Application, a.Name, "", fields)
+		}
+	}
+
+	for _, p := range desired.Projects {
+		project, ok := current.projects[p.Name]
+		fields := []TenantFieldChange{}
+		if p.Groups != nil {
+			groups := make([]string, len(p.Groups))
+			for i, g := range p.Groups {
+				groups[i] = normalizeGroupPath(g)
+			}
+			fields = diffField(fields, "groups", listString(current.projectGroupPaths(project)), listString(groups))
+		}
+		if p.Applications != nil {
+			fields = diffField(fields, "applications", listString(current.projectApplicationNames(project)), listString(p.Applications))
+		}
+		if p.Tags != nil {
+			fields = diffField(fields, "tags", tagString(project.Tags), tagString(p.Tags))
+		}
+		if p.RepoUrl != "" {
+			fields = diffField(fields, "repoUrl", project.RepoUrl, p.RepoUrl)
+		}
+		if p.MainBranch != "" {
+			fields = diffField(fields, "mainBranch", project.MainBranch, p.MainBranch)
+		}
+		if p.Criticality != nil {
+			fields = diffField(fields, "criticality", uintString(ok, project.Criticality), strconv.FormatUint(uint64(*p.Criticality), 10))
+		}
+
+		if ok {
+			plan.add(TenantChangeActions.Update, TenantResourceTypes.Project, p.Name, project.ProjectID, fields)
+		} else {
+			plan.add(TenantChangeActions.Create, TenantResourceTypes.Project, p.Name, "", fields)
+		}
+
+		if len(p.Configuration) > 0 {
+			currentSettings := map[string]string{}
+			if ok {
+				settings, err := c.GetProjectConfigurationByID(project.ProjectID)
+				if err != nil {
+					return plan, fmt.Errorf("failed to get configuration of project %v: %s", project.String(), err)
+				}
+				for _, s := range settings {
+					currentSettings[s.Key] = s.Value
+				}
+			}
+			fields := []TenantFieldChange{}
+			for _, key := range sortedKeys(p.Configuration) {
+				fields = diffField(fields, key, currentSettings[key], p.Configuration[key])
+			}
+			plan.add(TenantChangeActions.Update, TenantResourceTypes.ProjectConfiguration, p.Name, project.ProjectID, fields)
+		}
+
+		schedule, hasSchedule := current.schedules[project.ProjectID]
+		hasSchedule = hasSchedule && ok
+		switch {
+		case p.Schedule != nil && hasSchedule:
+			plan.add(TenantChangeActions.Update, TenantResourceTypes.ScanSchedule, p.Name, project.ProjectID, diffSchedule(newTenantScheduleState(schedule), p.Schedule))
+		case p.Schedule != nil:
+			plan.add(TenantChangeActions.Create, TenantResourceTypes.ScanSchedule, p.Name, project.ProjectID, diffSchedule(&TenantScheduleState{}, p.Schedule))
+		case hasSchedule && prune:
+			plan.add(TenantChangeActions.Delete, TenantResourceTypes.ScanSchedule, p.Name, project.ProjectID, nil)
+		}
+	}
+
+	if !prune {
+		return plan, nil
+	}
+
+	// deletes run in reverse dependency order
+	if desired.Projects != nil {
+		for _, name := range sortedKeys(current.projects) {
+			if !slices.ContainsFunc(desired.Projects, func(p TenantProjectState) bool { return p.Name == name }) {
+				plan.add(TenantChangeActions.Delete, TenantResourceTypes.Project, name, current.projects[name].ProjectID, nil)
+			}
+		}
+	}
+	if desired.Applications != nil {
+		for _, name := range sortedKeys(current.applications) {
+			if !slices.ContainsFunc(desired.Applications, func(a TenantApplicationState) bool { return a.Name == name }) {
+				plan.add(TenantChangeActions.Delete, TenantResourceTypes.Application, name, current.applications[name].ApplicationID, nil)
+			}
+		}
+	}
+	if desired.Presets != nil {
+		for _, name := range sortedKeys(current.presets) {
+			preset := current.presets[name]
+			if preset.Custom && !slices.ContainsFunc(desired.Presets, func(p TenantPresetState) bool { return p.Name == name }) {
+				plan.add(TenantChangeActions.Delete, TenantResourceTypes.Preset, name, preset.PresetID, nil)
+			}
+		}
+	}
+	if desired.Groups != nil {
+		deleted := []string{}
+		for _, path := range sortedKeys(current.groups) {
+			if slices.Contains(desiredGroups, path) {
+				continue
+			}
+			// subgroups are removed together with their parent
+			if slices.ContainsFunc(deleted, func(d string) bool { return strings.HasPrefix(path, d+"/") }) {
+				continue
+			}
+			deleted = append(deleted, path)
+			plan.add(TenantChangeActions.Delete, TenantResourceTypes.Group, path, current.groups[path].GroupID, nil)
+		}
+	}
+
+	return plan, nil
+}
+
+// Applies the changes in the plan in order, stopping at the first error
+// Changes are applied as planned, so the plan should be applied soon after PlanTenantState
+func (c *Cx1Client) ApplyTenantPlan(plan TenantPlan) error {
+	current, err := c.getTenantCurrentState(false, false)
+	if err != nil {
+		return err
+	}
+
+	var queries *SASTQueryCollection
+	presetCollection := func(ids []string) (SASTQueryCollection, error) {
+		if queries == nil {
+			qc, err := c.GetSASTQueryCollection()
+			if err != nil {
+				return qc, fmt.Errorf("failed to get query collection: %s", err)
+			}
+			queries = &qc
+		}
+		var collection SASTQueryCollection
+		for _, q := range queries.GetQueries() {
+			if slices.Contains(ids, strconv.FormatUint(q.QueryID, 10)) {
+				collection.AddQuery(q)
+			}
+		}
+		return collection, nil
+	}
+
+	projectIDs := map[string]string{}
+	for name, p := range current.projects {
+		projectIDs[name] = p.ProjectID
+	}
+
+	for _, change := range plan.Changes {
+		c.config.Logger.Debugf("Applying change: %v", change.String())
+		var err error
+
+		switch change.ResourceType {
+		case TenantResourceTypes.Group:
+			err = c.applyGroupChange(change, &current)
+		case TenantResourceTypes.Preset:
+			desired := plan.Desired.preset(change.Name)
+			switch change.Action {
+			case TenantChangeActions.Create:
+				var collection SASTQueryCollection
+				if collection, err = presetCollection(desired.QueryIDs); err == nil {
+					_, err = c.CreateSASTPreset(desired.Name, desired.Description, collection)
+				}
+			case TenantChangeActions.Update:
+				preset := Preset{PresetID: change.ID, Engine: "sast"}
+				if err = c.GetPresetContents(&preset); err != nil {
+					break
+				}
+				preset.Name = desired.Name
+				preset.Description = desired.Description
+				if len(desired.QueryIDs) > 0 {
+					var collection SASTQueryCollection
+					if collection, err = presetCollection(desired.QueryIDs); err != nil {
+						break
+					}
+					preset.QueryFamilies = collection.GetQueryFamilies(true)
+				}
+				err = c.UpdateSASTPreset(preset)
+			case TenantChangeActions.Delete:
+				err = c.DeletePreset(Preset{PresetID: change.ID, Name: change.Name, Engine: "sast"})
+			}
+		case TenantResourceTypes.Application:
+			err = c.applyApplicationChange(change, plan.Desired, &current)
+		case TenantResourceTypes.Project:
+			err = c.applyProjectChange(change, plan.Desired, &current, projectIDs)
+		case TenantResourceTypes.ProjectConfiguration:
+			desired := plan.Desired.project(change.Name)
+			settings := []ConfigurationSetting{}
+			for _, f := range change.Fields {
+				settings = append(settings, ConfigurationSetting{Key: f.Field, Value: desired.Configuration[f.Field]})
+			}
+			err = c.UpdateProjectConfigurationByID(projectIDs[change.Name], settings)
+		case TenantResourceTypes.ScanSchedule:
+			projectID := projectIDs[change.Name]
+			switch change.Action {
+			case TenantChangeActions.Create:
+				err = c.CreateScanScheduleByID(projectID, plan.Desired.project(change.Name).Schedule.toScanSchedule())
+			case TenantChangeActions.Update:
+				err = c.UpdateScanScheduleByID(projectID, plan.Desired.project(change.Name).Schedule.toScanSchedule())
+			case TenantChangeActions.Delete:
+				err = c.DeleteScanSchedulesByID(projectID)
+			}
+		default:
+			err = fmt.Errorf("unknown resource type %v", change.ResourceType)
+		}
+
+		if err != nil {
+			return fmt.Errorf("failed to apply change %v: %s", change.String(), err)
+		}
+	}
+
+	return nil
+}
+
+func (c *Cx1Client) applyGroupChange(change TenantChange, current *tenantCurrentState) error {
+	switch change.Action {
+	case TenantChangeActions.Create:
+		var group Group
+		var err error
+		parentPath := change.Name[:strings.LastIndex(change.Name, "/")]
+		name := change.Name[len(parentPath)+1:]
+		if parentPath == "" {
+			group, err = c.CreateGroup(name)
+		} else {
+			parent, ok := current.groups[parentPath]
+			if !ok {
+				return fmt.Errorf("parent group %v does not exist", parentPath)
+			}
+			group, err = c.CreateChildGroup(&parent, name)
+		}
+		if err != nil {
+			return err
+		}
+		current.groups[change.Name] = group
+		current.groupPaths[group.GroupID] = change.Name
+	case TenantChangeActions.Delete:
+		return c.DeleteGroup(&Group{GroupID: change.ID, Name: change.Name})
+	}
+	return nil
+}
+
+func (c *Cx1Client) applyApplicationChange(change TenantChange, desiredState TenantState, current *tenantCurrentState) error {
+	if change.Action == TenantChangeActions.Delete {
+		return c.DeleteApplicationByID(change.ID)
+	}
+
+	desired := desiredState.application(change.Name)
+	applicationID := change.ID
+	if change.Action == TenantChangeActions.Create {
+		app, err := c.CreateApplication(desired.Name)
+		if err != nil {
+			return err
+		}
+		applicationID = app.ApplicationID
+		current.applications[app.Name] = app
+		current.applicationNames[app.ApplicationID] = app.Name
+	}
+
+	patch := ApplicationPatch{Criticality: desired.Criticality}
+	if desired.Description != "" {
+		patch.Description = &desired.Description
+	}
+	if desired.Tags != nil {
+		patch.Tags = &desired.Tags
+	}
+	return c.PatchApplicationByID(applicationID, patch)
+}
+
+func (c *Cx1Client) applyProjectChange(change TenantChange, desiredState TenantState, current *tenantCurrentState, projectIDs map[string]string) error {
+	if change.Action == TenantChangeActions.Delete {
+		return c.DeleteProject(&Project{ProjectID: change.ID, Name: change.Name})
+	}
+
+	desired := desiredState.project(change.Name)
+	var groupIDs []string
+	if desired.Groups != nil {
+		groupIDs = []string{}
+		for _, path := range desired.Groups {
+			group, ok := current.groups[normalizeGroupPath(path)]
+			if !ok {
+				return fmt.Errorf("group %v does not exist", path)
+			}
+			groupIDs = append(groupIDs, group.GroupID)
+		}
+	}
+
+	projectID := change.ID
+	if change.Action == TenantChangeActions.Create {
+		project, err := c.CreateProject(desired.Name, groupIDs, desired.Tags)
+		if err != nil {
+			return err
+		}
+		projectID = project.ProjectID
+		projectIDs[desired.Name] = projectID
+	}
+
+	patch := ProjectPatch{Criticality: desired.Criticality}
+	if desired.RepoUrl != "" {
+		patch.RepoUrl = &desired.RepoUrl
+	}
+	if desired.MainBranch != "" {
+		patch.MainBranch = &desired.MainBranch
+	}
+	if desired.Tags != nil {
+		patch.Tags = &desired.Tags
+	}
+	if groupIDs != nil {
+		patch.Groups = &groupIDs
+	}
+	if err := c.PatchProjectByID(projectID, patch); err != nil {
+		return err
+	}
+
+	if !slices.ContainsFunc(change.Fields, func(f TenantFieldChange) bool { return f.Field == "applications" }) {
+		return nil
+	}
+
+	applicationIDs := []string{}
+	for _, name := range desired.Applications {
+		app, ok := current.applications[name]
+		if !ok {
+			return fmt.Errorf("application %v does not exist", name)
+		}
+		applicationIDs = append(applicationIDs, app.ApplicationID)
+	}
+
+	project, err := c.GetProjectByID(projectID)
+	if err != nil {
+		return err
+	}
+	project.Applications = &applicationIDs
+	return c.UpdateProject(&project)
+}
+
+func (c *Cx1Client) getTenantCurrentState(loadPresets, loadSchedules bool) (tenantCurrentState, error) {
+	current := tenantCurrentState{
+		groups:           map[string]Group{},
+		groupPaths:       map[string]string{},
+		presets:          map[string]Preset{},
+		applications:     map[string]Application{},
+		applicationNames: map[string]string{},
+		projects:         map[string]Project{},
+		schedules:        map[string]ProjectScanSchedule{},
+	}
+
+	groups, err := c.GetAllGroups()
+	if err != nil {
+		return current, fmt.Errorf("failed to get groups: %s", err)
+	}
+	for _, g := range flattenGroups(groups, "") {
+		current.groups[g.Path] = g
+		current.groupPaths[g.GroupID] = g.Path
+	}
+
+	applications, err := c.GetAllApplications()
+	if err != nil {
+		return current, fmt.Errorf("failed to get applications: %s", err)
+	}
+	for _, a := range applications {
+		current.applications[a.Name] = a
+		current.applicationNames[a.ApplicationID] = a.Name
+	}
+
+	projects, err := c.GetAllProjects()
+	if err != nil {
+		return current, fmt.Errorf("failed to get projects: %s", err)
+	}
+	for _, p := range projects {
+		current.projects[p.Name] = p
+	}
+
+	if loadPresets {
+		presets, err := c.GetAllSASTPresets()
+		if err != nil {
+			return current, fmt.Errorf("failed to get presets: %s", err)
+		}
+		for _, p := range presets {
+			current.presets[p.Name] = p
+		}
+	}
+
+	if loadSchedules {
+		schedules, err := c.GetAllScanSchedules()
+		if err != nil {
+			return current, fmt.Errorf("failed to get scan schedules: %s", err)
+		}
+		for _, s := range schedules {
+			current.schedules[s.ProjectID] = s
+		}
+	}
+
+	return current, nil
+}
+
+func (s tenantCurrentState) projectGroupPaths(p Project) []string {
+	paths := []string{}
+	for _, id := range p.Groups {
+		if path, ok := s.groupPaths[id]; ok {
+			paths = append(paths, path)
+		} else {
+			paths = append(paths, id)
+		}
+	}
+	return paths
+}
+
+func (s tenantCurrentState) projectApplicationNames(p Project) []string {
+	names := []string{}
+	if p.Applications == nil {
+		return names
+	}
+	for _, id := range *p.Applications {
+		if name, ok := s.applicationNames[id]; ok {
+			names = append(names, name)
+		} else {
+			names = append(names, id)
+		}
+	}
+	return names
+}
+
+func (s TenantState) preset(name string) TenantPresetState {
+	for _, p := range s.Presets {
+		if p.Name == name {
+			return p
+		}
+	}
+	return TenantPresetState{Name: name}
+}
+
+func (s TenantState) application(name string) TenantApplicationState {
+	for _, a := range s.Applications {
+		if a.Name == name {
+			return a
+		}
+	}
+	return TenantApplicationState{Name: name}
+}
+
+func (s TenantState) project(name string) TenantProjectState {
+	for _, p := range s.Projects {
+		if p.Name == name {
+			return p
+		}
+	}
+	return TenantProjectState{Name: name}
+}
+
+// returns the sorted paths of all desired groups and their parents, including groups referenced by projects
+func desiredGroupPaths(desired TenantState) []string {
+	paths := []string{}
+	add := func(path string) {
+		parts := strings.Split(normalizeGroupPath(path), "/")
+		for i := 2; i <= len(parts); i++ {
+			if p := strings.Join(parts[:i], "/"); !slices.Contains(paths, p) {
+				paths = append(paths, p)
+			}
+		}
+	}
+	for _, g := range desired.Groups {
+		add(g.Path)
+	}
+	for _, p := range desired.Projects {
+		for _, g := range p.Groups {
+			add(g)
+		}
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+func normalizeGroupPath(path string) string {
+	path = strings.TrimSuffix(path, "/")
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	return path
+}
+
+func presetQueryIDs(p Preset) []string {
+	ids := []string{}
+	for _, f := range p.QueryFamilies {
+		ids = append(ids, f.QueryIDs...)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func newTenantScheduleState(s ProjectScanSchedule) *TenantScheduleState {
+	return &TenantScheduleState{
+		StartTime: s.StartTime,
+		Frequency: s.Frequency,
+		Days:      s.Days,
+		Active:    s.Active,
+		Engines:   s.Engines,
+		Branch:    s.Branch,
+		Tags:      s.Tags,
+	}
+}
+
+func (s TenantScheduleState) toScanSchedule() ProjectScanSchedule {
+	return ProjectScanSchedule{
+		StartTime: s.StartTime,
+		Frequency: s.Frequency,
+		Days:      s.Days,
+		Active:    s.Active,
+		Engines:   s.Engines,
+		Branch:    s.Branch,
+		Tags:      s.Tags,
+	}
+}
+
+func diffSchedule(old, new *TenantScheduleState) []TenantFieldChange {
+	fields := []TenantFieldChange{}
+	fields = diffField(fields, "startTime", old.StartTime, new.StartTime)
+	fields = diffField(fields, "frequency", old.Frequency, new.Frequency)
+	fields = diffField(fields, "days", listString(old.Days), listString(new.Days))
+	fields = diffField(fields, "active", strconv.FormatBool(old.Active), strconv.FormatBool(new.Active))
+	fields = diffField(fields, "engines", listString(old.Engines), listString(new.Engines))
+	fields = diffField(fields, "branch", old.Branch, new.Branch)
+	fields = diffField(fields, "tags", tagString(old.Tags), tagString(new.Tags))
+	return fields
+}
+
+func diffQueryIDs(fields []TenantFieldChange, old, new []string) []TenantFieldChange {
+	added, removed := 0, 0
+	for _, id := range new {
+		if !slices.Contains(old, id) {
+			added++
+		}
+	}
+	for _, id := range old {
+		if !slices.Contains(new, id) {
+			removed++
+		}
+	}
+	if added == 0 && removed == 0 {
+		return fields
+	}
+	return append(fields, TenantFieldChange{
+		Field: "queries",
+		Old:   fmt.Sprintf("%d queries", len(old)),
+		New:   fmt.Sprintf("%d queries (%d added, %d removed)", len(new), added, removed),
+	})
+}
+
+func diffField(fields []TenantFieldChange, field, old, new string) []TenantFieldChange {
+	if old == new {
+		return fields
+	}
+	return append(fields, TenantFieldChange{Field: field, Old: old, New: new})
+}
+
+func listString(list []string) string {
+	sorted := slices.Clone(list)
+	sort.Strings(sorted)
+	return strings.Join(sorted, ", ")
+}
+
+func tagString(tags map[string]string) string {
+	list := []string{}
+	for k, v := range tags {
+		if v == "" {
+			list = append(list, k)
+		} else {
+			list = append(list, k+"="+v)
+		}
+	}
+	return listString(list)
+}
+
+func uintString(set bool, value uint) string {
+	if !set {
+		return ""
+	}
+	return strconv.FormatUint(uint64(value), 10)
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// adds a change to the plan, updates without any field changes are skipped
+func (p *TenantPlan) add(action, resourceType, name, id string, fields []TenantFieldChange) {
+	if action == TenantChangeActions.Update && len(fields) == 0 {
+		return
+	}
+	p.Changes = append(p.Changes, TenantChange{
+		Action:       action,
+		ResourceType: resourceType,
+		Name:         name,
+		ID:           id,
+		Fields:       fields,
+	})
+}
+
+// Returns the number of creates, updates and deletes in the plan
+func (p TenantPlan) Count() (creates, updates, deletes int) {
+	for _, c := range p.Changes {
+		switch c.Action {
+		case TenantChangeActions.Create:
+			creates++
+		case TenantChangeActions.Update:
+			updates++
+		case TenantChangeActions.Delete:
+			deletes++
+		}
+	}
+	return
+}
+
+// Returns the plan in a human-readable form, one change per line followed by its field changes
+func (p TenantPlan) String() string {
+	var b strings.Builder
+	for _, c := range p.Changes {
+		b.WriteString(c.String())
+		b.WriteString("\n")
+		for _, f := range c.Fields {
+			fmt.Fprintf(&b, "    %v: %q -> %q\n", f.Field, f.Old, f.New)
+		}
+	}
+	creates, updates, deletes := p.Count()
+	fmt.Fprintf(&b, "Plan: %d to create, %d to update, %d to delete", creates, updates, deletes)
+	return b.String()
+}
+
+func (c TenantChange) String() string {
+	return fmt.Sprintf("%v %v %v", tenantChangeSymbol(c.Action), c.ResourceType, c.Name)
+}
+
+func tenantChangeSymbol(action string) string {
+	switch action {
+	case TenantChangeActions.Create:
+		return "+"
+	case TenantChangeActions.Delete:
+		return "-"
+	}
+	return "~"
+}
@@ -0,0 +1,519 @@
+package Cx1ClientGo
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/exp/slices"
+)
+
+// Creates a sync of custom queries, presets and roles from the source tenant to the target tenant
+func NewTenantSync(source, target *Cx1Client) TenantSync {
+	return TenantSync{
+		Source:             source,
+		Target:             target,
+		ApplicationMapping: map[string]string{},
+	}
+}
+
+// Compares the source and target tenants and returns the changes required to bring the target in line with the source:
+// tenant-level and application-level custom SAST queries, custom presets by name and content, and custom roles with their sub-roles.
+// Application-level queries are matched by application name, using ApplicationMapping where the names differ between tenants.
+func (s TenantSync) Compare() (TenantSyncChangeSet, error) {
+	changes := TenantSyncChangeSet{
+		Queries: []TenantSyncQueryChange{},
+		Presets: []TenantSyncPresetChange{},
+		Roles:   []TenantSyncRoleChange{},
+	}
+
+	if !s.SkipRoles {
+		roles, err := s.compareRoles()
+		if err != nil {
+			return changes, err
+		}
+		changes.Roles = roles
+	}
+
+	if !s.SkipQueries {
+		queries, err := s.compareQueries()
+		if err != nil {
+			return changes, err
+		}
+		changes.Queries = queries
+	}
+
+	if !s.SkipPresets {
+		presets, err := s.comparePresets()
+		if err != nil {
+			return changes, err
+		}
+		changes.Presets = presets
+	}
+
+	return changes, nil
+}
+
+// Applies the changes to the target tenant: roles first, then queries, then presets (which may include the new queries), and finally query deletions.
+// Tenant-level queries are changed through tenant-level audit sessions. Application-level queries require an audit session
+// on a project in the target application with a completed SAST scan, and fail if there is none.
+// All changes are attempted, and the error lists those which failed.
+func (s TenantSync) Apply(changes TenantSyncChangeSet) error {
+	c := s.Target
+	failures := []string{}
+	fail := func(change string, err error) {
+		c.config.Logger.Warnf("Failed to apply %v: %s", change, err)
+		failures = append(failures, fmt.Sprintf("%v: %s", change, err))
+	}
+
+	if len(changes.Roles) > 0 {
+		s.applyRoles(changes.Roles, fail)
+	}
+
+	deletes := []TenantSyncQueryChange{}
+	updates := []TenantSyncQueryChange{}
+	for _, q := range changes.Queries {
+		if q.Action == TenantChangeActions.Delete {
+			deletes = append(deletes, q)
+		} else {
+			updates = append(updates, q)
+		}
+	}
+	s.applyQueries(updates, fail)
+
+	for _, p := range changes.Presets {
+		var err error
+		switch p.Action {
+		case TenantChangeActions.Create:
+			_, err = c.createPresetWithFamilies(p.Engine, p.Name, p.Description, p.QueryFamilies)
+		case TenantChangeActions.Update:
+			preset := Preset{PresetID: p.TargetID, Name: p.Name, Description: p.Description, Engine: p.Engine, QueryFamilies: p.QueryFamilies}
+			if p.Engine == "iac" {
+				err = c.UpdateIACPreset(preset)
+			} else {
+				err = c.UpdateSASTPreset(preset)
+			}
+		case TenantChangeActions.Delete:
+			err = c.DeletePreset(Preset{PresetID: p.TargetID, Name: p.Name, Engine: p.Engine})
+		}
+		if err != nil {
+			fail(p.String(), err)
+		}
+	}
+
+	s.applyQueries(deletes, fail)
+
+	if len(failures) > 0 {
+		return fmt.Errorf("failed to apply %d changes: %v", len(failures), strings.Join(failures, "; "))
+	}
+	return nil
+}
+
+func (s TenantSync) compareRoles() ([]TenantSyncRoleChange, error) {
+	changes := []TenantSyncRoleChange{}
+	sourceRoles, err := s.Source.getCustomRoles()
+	if err != nil {
+		return changes, fmt.Errorf("failed to get source roles: %s", err)
+	}
+	targetRoles, err := s.Target.GetAppRoles()
+	if err != nil {
+		return changes, fmt.Errorf("failed to get target roles: %s", err)
+	}
+
+	for _, sr := range sourceRoles {
+		index := slices.IndexFunc(targetRoles, func(r Role) bool { return r.Name == sr.Name })
+		if index < 0 {
+			changes = append(changes, TenantSyncRoleChange{Action: TenantChangeActions.Create, Name: sr.Name, Creator: sr.Creator, AddSubRoles: sr.SubRoles})
+			continue
+		}
+
+		tr := targetRoles[index]
+		composites, err := s.Target.GetRoleComposites(&tr)
+		if err != nil {
+			return changes, fmt.Errorf("failed to get sub-roles of target role %v: %s", tr.String(), err)
+		}
+		change := TenantSyncRoleChange{Action: TenantChangeActions.Update, Name: sr.Name, TargetID: tr.RoleID}
+		for _, name := range sr.SubRoles {
+			if !slices.ContainsFunc(composites, func(r Role) bool { return r.Name == name }) {
+				change.AddSubRoles = append(change.AddSubRoles, name)
+			}
+		}
+		for _, r := range composites {
+			if !slices.Contains(sr.SubRoles, r.Name) {
+				change.RemoveSubRoles = append(change.RemoveSubRoles, r.Name)
+			}
+		}
+		if len(change.AddSubRoles) > 0 || len(change.RemoveSubRoles) > 0 {
+			changes = append(changes, change)
+		}
+	}
+
+	if s.Prune {
+		for _, tr := range targetRoles {
+			if isCustomRole(tr) && !slices.ContainsFunc(sourceRoles, func(r TenantBackupRole) bool { return r.Name == tr.Name }) {
+				changes = append(changes, TenantSyncRoleChange{Action: TenantChangeActions.Delete, Name: tr.Name, TargetID: tr.RoleID})
+			}
+		}
+	}
+
+	return changes, nil
+}
+
+func (s TenantSync) applyRoles(changes []TenantSyncRoleChange, fail func(string, error)) {
+	c := s.Target
+	roles, err := c.GetRoles()
+	if err != nil {
+		fail("roles", err)
+		return
+	}
+
+	// new roles are created first as they may be sub-roles of other roles
+	for i, change := range changes {
+		if change.Action != TenantChangeActions.Create {
+			continue
+		}
+		role, err := c.CreateAppRole(change.Name, change.Creator)
+		if err != nil {
+			fail(change.String(), err)
+			continue
+		}
+		changes[i].TargetID = role.RoleID
+		roles = append(roles, role)
+	}
+
+	for _, change := range changes {
+		if change.TargetID == "" {
+			continue
+		}
+		role := Role{RoleID: change.TargetID, Name: change.Name}
+		if change.Action == TenantChangeActions.Delete {
+			if err := c.DeleteRoleByID(change.TargetID); err != nil {
+				fail(change.String(), err)
+			}
+			continue
+		}
+
+		missing := func(name string) { fail(change.String(), fmt.Errorf("sub-role %v not found", name)) }
+		if add := findRoles(roles, change.AddSubRoles, missing); len(add) > 0 {
+			if err := c.AddRoleComposites(&role, &add); err != nil {
+				fail(change.String(), err)
+			}
+		}
+		if remove := findRoles(roles, change.RemoveSubRoles, missing); len(remove) > 0 {
+			if err := c.RemoveRoleComposites(&role, &remove); err != nil {
+				fail(change.String(), err)
+			}
+		}
+	}
+}
+
+func (s TenantSync) compareQueries() ([]TenantSyncQueryChange, error) {
+	changes := []TenantSyncQueryChange{}
+
+	sourceApps, err := s.Source.GetAllApplications()
+	if err != nil {
+		return changes, fmt.Errorf("failed to get source applications: %s", err)
+	}
+	allTargetApps, err := s.Target.GetAllApplications()
+	if err != nil {
+		return changes, fmt.Errorf("failed to get target applications: %s", err)
+	}
+	// only the target applications matching a source application are read, each needs an audit session
+	targetApps := []Application{}
+	for _, ta := range allTargetApps {
+		if slices.ContainsFunc(sourceApps, func(sa Application) bool { return s.targetApplicationName(sa.Name) == ta.Name }) {
+			targetApps = append(targetApps, ta)
+		}
+	}
+
+	// queries are read through audit sessions as they are written, project-level queries are not synchronized
+	sourceQueries, err := s.Source.getTenantBackupQueries(sourceApps, nil)
+	if err != nil {
+		return changes, fmt.Errorf("failed to get source queries: %s", err)
+	}
+	targetQueries, err := s.Target.getTenantBackupQueries(targetApps, nil)
+	if err != nil {
+		return changes, fmt.Errorf("failed to get target queries: %s", err)
+	}
+	changes = append(changes, s.diffQueries(levelQueries(sourceQueries, AUDIT_QUERY.TENANT, AUDIT_QUERY.TENANT), levelQueries(targetQueries, AUDIT_QUERY.TENANT, AUDIT_QUERY.TENANT), "")...)
+
+	for _, sa := range sourceApps {
+		if sa.ProjectIds == nil || len(*sa.ProjectIds) == 0 {
+			s.Source.config.Logger.Warnf("Application %v has no projects, its application-level queries can not be read and are not compared", sa.Name)
+			continue
+		}
+		targetName := s.targetApplicationName(sa.Name)
+		source := levelQueries(sourceQueries, AUDIT_QUERY.APPLICATION, sa.ApplicationID)
+
+		index := slices.IndexFunc(targetApps, func(a Application) bool { return a.Name == targetName })
+		if index < 0 {
+			if len(source) > 0 {
+				s.Target.config.Logger.Warnf("Application %v has application-level queries but there is no application %v in the target tenant", sa.Name, targetName)
+			}
+			continue
+		}
+		target := levelQueries(targetQueries, AUDIT_QUERY.APPLICATION, targetApps[index].ApplicationID)
+		changes = append(changes, s.diffQueries(source, target, targetName)...)
+	}
+
+	return changes, nil
+}
+
+// compares two lists of queries at the same level by language, group and name
+func (s TenantSync) diffQueries(source, target []TenantBackupQuery, application string) []TenantSyncQueryChange {
+	changes := []TenantSyncQueryChange{}
+	key := func(q TenantBackupQuery) string {
+		return strings.ToLower(strings.Join([]string{q.Language, q.Group, q.Name}, "/"))
+	}
+	targetByKey := map[string]TenantBackupQuery{}
+	for _, q := range target {
+		targetByKey[key(q)] = q
+	}
+
+	sourceKeys := map[string]bool{}
+	for _, sq := range source {
+		sourceKeys[key(sq)] = true
+		tq, ok := targetByKey[key(sq)]
+		if !ok {
+			changes = append(changes, TenantSyncQueryChange{Action: TenantChangeActions.Create, Application: application, Query: sq})
+		} else if strings.TrimSpace(tq.Source) != strings.TrimSpace(sq.Source) || tq.Severity != sq.Severity {
+			changes = append(changes, TenantSyncQueryChange{Action: TenantChangeActions.Update, Application: application, Query: sq})
+		}
+	}
+
+	if s.Prune {
+		for _, tq := range target {
+			if !sourceKeys[key(tq)] {
+				changes = append(changes, TenantSyncQueryChange{Action: TenantChangeActions.Delete, Application: application, Query: tq})
+			}
+		}
+	}
+	return changes
+}
+
+func (s TenantSync) targetApplicationName(name string) string {
+	if mapped, ok := s.ApplicationMapping[name]; ok {
+		return mapped
+	}
+	return name
+}
+
+func levelQueries(queries []TenantBackupQuery, level, levelID string) []TenantBackupQuery {
+	filtered := []TenantBackupQuery{}
+	for _, q := range queries {
+		if q.Level == level && q.LevelID == levelID {
+			filtered = append(filtered, q)
+		}
+	}
+	return filtered
+}
+
+func (s TenantSync) applyQueries(changes []TenantSyncQueryChange, fail func(string, error)) {
+	c := s.Target
+
+	// one session per tenant-level language or target application
+	sessions := map[string]*AuditSession{}
+	defer func() {
+		for _, session := range sessions {
+			if err := c.DeleteAuditSession(session); err != nil {
+				c.config.Logger.Warnf("Failed to delete audit session %v: %s", session.ID, err)
+			}
+		}
+	}()
+
+	var applications []Application
+	getSession := func(change TenantSyncQueryChange) (*AuditSession, error) {
+		key := "tenant/" + change.Query.Language
+		if change.Application != "" {
+			key = "application/" + change.Application
+		}
+		if session, ok := sessions[key]; ok {
+			return session, nil
+		}
+
+		if change.Application == "" {
+			session, err := c.GetAuditSession("sast", change.Query.Language)
+			if err != nil {
+				return nil, err
+			}
+			sessions[key] = &session
+			return &session, nil
+		}
+
+		if applications == nil {
+			var err error
+			if applications, err = c.GetAllApplications(); err != nil {
+				return nil, err
+			}
+		}
+		index := slices.IndexFunc(applications, func(a Application) bool { return a.Name == change.Application })
+		if index < 0 {
+			return nil, fmt.Errorf("application %v does not exist", change.Application)
+		}
+		session, err := c.getApplicationAuditSession(applications[index])
+		if err != nil {
+			return nil, err
+		}
+		sessions[key] = &session
+		return &session, nil
+	}
+
+	for _, change := range changes {
+		session, err := getSession(change)
+		if err == nil && !session.HasLanguage(change.Query.Language) {
+			err = fmt.Errorf("the audit session for %v does not include language %v", session.ProjectName, change.Query.Language)
+		}
+		if err != nil {
+			fail(change.String(), err)
+			continue
+		}
+
+		level := AUDIT_QUERY.TENANT
+		if change.Application != "" {
+			level = AUDIT_QUERY.APPLICATION
+		}
+
+		if change.Action == TenantChangeActions.Delete {
+			err = c.deleteQueryOverride(session, change.Query, level)
+		} else {
+			err = c.restoreQuery(session, change.Query, level)
+		}
+		if err != nil {
+			fail(change.String(), err)
+		}
+	}
+}
+
+func (c *Cx1Client) deleteQueryOverride(auditSession *AuditSession, q TenantBackupQuery, level string) error {
+	treeLevel, levelID, targetLevelID := AUDIT_QUERY.TENANT, AUDIT_QUERY.TENANT, AUDIT_QUERY.TENANT
+	if auditSession.ProjectID != "" {
+		treeLevel, levelID = AUDIT_QUERY.PROJECT, auditSession.ProjectID
+	}
+	if level == AUDIT_QUERY.APPLICATION {
+		targetLevelID = auditSession.ApplicationID
+	}
+
+	collection, err := c.GetAuditSASTQueriesByLevelID(auditSession, treeLevel, levelID)
+	if err != nil {
+		return fmt.Errorf("failed to get queries for %v: %s", auditSession.String(), err)
+	}
+	query := collection.GetQueryByLevelAndName(level, targetLevelID, q.Language, q.Group, q.Name)
+	if query == nil {
+		return nil
+	}
+	return c.DeleteQueryOverrideByKey(auditSession, query.EditorKey)
+}
+
+func (s TenantSync) comparePresets() ([]TenantSyncPresetChange, error) {
+	changes := []TenantSyncPresetChange{}
+	for _, engine := range []string{"sast", "iac"} {
+		sourcePresets, err := s.Source.getCustomPresets(engine)
+		if err != nil {
+			return changes, fmt.Errorf("failed to get source %v presets: %s", engine, err)
+		}
+		targetPresets, err := s.Target.getCustomPresets(engine)
+		if err != nil {
+			return changes, fmt.Errorf("failed to get target %v presets: %s", engine, err)
+		}
+
+		for _, sp := range sourcePresets {
+			change := TenantSyncPresetChange{
+				Action:        TenantChangeActions.Create,
+				Engine:        engine,
+				Name:          sp.Name,
+				Description:   sp.Description,
+				QueryFamilies: sp.QueryFamilies,
+				Fields:        []TenantFieldChange{},
+			}
+			tp := findPreset(targetPresets, sp.Name)
+			if tp == nil {
+				changes = append(changes, change)
+				continue
+			}
+
+			change.Action = TenantChangeActions.Update
+			change.TargetID = tp.PresetID
+			change.Fields = diffField(change.Fields, "description", tp.Description, sp.Description)
+			change.Fields = diffQueryIDs(change.Fields, presetQueryIDs(*tp), presetQueryIDs(sp))
+			if len(change.Fields) > 0 {
+				changes = append(changes, change)
+			}
+		}
+
+		if s.Prune {
+			for _, tp := range targetPresets {
+				if findPreset(sourcePresets, tp.Name) == nil {
+					changes = append(changes, TenantSyncPresetChange{Action: TenantChangeActions.Delete, Engine: engine, Name: tp.Name, TargetID: tp.PresetID})
+				}
+			}
+		}
+	}
+	return changes, nil
+}
+
+// returns the custom presets for the engine with their contents
+func (c *Cx1Client) getCustomPresets(engine string) ([]Preset, error) {
+	var presets []Preset
+	var err error
+	if engine == "iac" {
+		presets, err = c.GetAllIACPresets()
+	} else {
+		presets, err = c.GetAllSASTPresets()
+	}
+	if err != nil {
+		return presets, err
+	}
+
+	custom := []Preset{}
+	for _, p := range presets {
+		if !p.Custom {
+			continue
+		}
+		if err := c.GetPresetContents(&p); err != nil {
+			return custom, fmt.Errorf("failed to get contents of preset %v: %s", p.String(), err)
+		}
+		custom = append(custom, p)
+	}
+	return custom, nil
+}
+
+// Returns the change set in a human-readable form, one change per line
+func (cs TenantSyncChangeSet) String() string {
+	lines := []string{}
+	for _, r := range cs.Roles {
+		lines = append(lines, r.String())
+	}
+	for _, q := range cs.Queries {
+		lines = append(lines, q.String())
+	}
+	for _, p := range cs.Presets {
+		lines = append(lines, p.String())
+		for _, f := range p.Fields {
+			lines = append(lines, fmt.Sprintf("    %v: %q -> %q", f.Field, f.Old, f.New))
+		}
+	}
+	lines = append(lines, fmt.Sprintf("Sync: %d role, %d query and %d preset changes", len(cs.Roles), len(cs.Queries), len(cs.Presets)))
+	return strings.Join(lines, "\n")
+}
+
+func (c TenantSyncQueryChange) String() string {
+	scope := "tenant"
+	if c.Application != "" {
+		scope = "application " + c.Application
+	}
+	return fmt.Sprintf("%v %v query %v -> %v -> %v", tenantChangeSymbol(c.Action), scope, c.Query.Language, c.Query.Group, c.Query.Name)
+}
+
+func (c TenantSyncPresetChange) String() string {
+	return fmt.Sprintf("%v %v preset %v", tenantChangeSymbol(c.Action), c.Engine, c.Name)
+}
+
+func (c TenantSyncRoleChange) String() string {
+	s := fmt.Sprintf("%v role %v", tenantChangeSymbol(c.Action), c.Name)
+	if len(c.AddSubRoles) > 0 {
+		s += fmt.Sprintf(" +[%v]", strings.Join(c.AddSubRoles, ", "))
+	}
+	if len(c.RemoveSubRoles) > 0 {
+		s += fmt.Sprintf(" -[%v]", strings.Join(c.RemoveSubRoles, ", "))
+	}
+	return s
+}
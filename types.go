@@ -1,7 +1,10 @@
 package Cx1ClientGo
 
 import (
+	"archive/zip"
+	"io"
 	"net/http"
+	"sync"
 	"time"
 
 	"github.com/golang-jwt/jwt/v4"
@@ -17,16 +20,19 @@ type Logger interface {
 }
 
 type Cx1Client struct {
-	config      Cx1ClientConfiguration
-	claims      Cx1Claims
-	user        *User
-	client      *OIDCClient
-	userinfo    Cx1TokenUserInfo
-	version     *VersionInfo
-	astAppID    string
-	tenantID    string
-	tenantOwner *TenantOwner
-	flags       map[string]bool // initial implementation ignoring "payload" part of the flag
+	config        Cx1ClientConfiguration
+	claims        Cx1Claims
+	user          *User
+	client        *OIDCClient
+	userinfo      Cx1TokenUserInfo
+	version       *VersionInfo
+	astAppID      string
+	tenantID      string
+	tenantOwner   *TenantOwner
+	flags         map[string]bool // initial implementation ignoring "payload" part of the flag
+	catalogue     *ConfigurationCatalogue
+	catalogueLock *sync.Mutex // guards catalogue, which is read while validating configuration updates
+	tokenLock     *sync.Mutex // guards config.Auth and claims while the access token is refreshed
 }
 
 type Cx1ClientConfiguration struct {
@@ -103,6 +109,225 @@ type TenantOwner struct {
 	UserID    string `json:"id"`
 }
 
+// Snapshot of the tenant configuration, see BackupTenant and RestoreTenant
+type TenantBackup struct {
+	Version             int                    `json:"version"`
+	Created             time.Time              `json:"created"`
+	Tenant              string                 `json:"tenant"`
+	Projects            []TenantBackupProject  `json:"projects"`
+	Applications        []Application          `json:"applications"`
+	Groups              []TenantBackupGroup    `json:"groups"`
+	Users               []TenantBackupUser     `json:"users"`
+	Clients             []TenantBackupClient   `json:"clients"`
+	Roles               []TenantBackupRole     `json:"roles"`
+	Presets             []TenantBackupPreset   `json:"presets"`
+	Queries             []TenantBackupQuery    `json:"queries"`
+	ScanSchedules       []ProjectScanSchedule  `json:"scanSchedules"`
+	ResultStates        []ResultState          `json:"resultStates"`
+	TenantConfiguration []ConfigurationSetting `json:"tenantConfiguration"`
+}
+
+type TenantBackupOptions struct {
+	SkipQueries   bool // custom queries are retrieved through an audit session per project, which can be slow on large tenants
+	SkipUsers     bool
+	SkipSchedules bool // scan schedules require v3.44+
+}
+
+type TenantBackupProject struct {
+	Project       Project                `json:"project"`
+	Configuration []ConfigurationSetting `json:"configuration"` // project-level settings only
+}
+
+type TenantBackupGroup struct {
+	GroupID     string              `json:"id"`
+	ParentID    string              `json:"parentId"`
+	Name        string              `json:"name"`
+	Path        string              `json:"path"`
+	ClientRoles map[string][]string `json:"clientRoles"`
+	RealmRoles  []string            `json:"realmRoles"`
+	Members     []string            `json:"members"` // user IDs
+}
+
+type TenantBackupUser struct {
+	User     User     `json:"user"`
+	AppRoles []string `json:"appRoles"`
+	IAMRoles []string `json:"iamRoles"`
+}
+
+// OIDC clients are backed up without their secrets, restored clients have new secrets
+type TenantBackupClient struct {
+	ID                   string   `json:"id"`
+	ClientID             string   `json:"clientId"`
+	Enabled              bool     `json:"enabled"`
+	SecretExpirationDays uint64   `json:"secretExpirationDays"`
+	NotificationEmails   []string `json:"notificationEmails"`
+}
+
+type TenantBackupRole struct {
+	RoleID      string   `json:"id"`
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Creator     string   `json:"creator"`
+	SubRoles    []string `json:"subRoles"` // role names
+}
+
+type TenantBackupPreset struct {
+	Engine string `json:"engine"`
+	Preset Preset `json:"preset"`
+}
+
+type TenantBackupQuery struct {
+	QueryID            uint64 `json:"queryId,string"`
+	Level              string `json:"level"`
+	LevelID            string `json:"levelId"`
+	Language           string `json:"language"`
+	Group              string `json:"group"`
+	Name               string `json:"name"`
+	Path               string `json:"path"`
+	Severity           string `json:"severity"`
+	CweID              int64  `json:"cweId"`
+	IsExecutable       bool   `json:"isExecutable"`
+	QueryDescriptionId int64  `json:"queryDescriptionId"`
+	Source             string `json:"source"`
+}
+
+// IDs from the backup mapped to the IDs of the restored (or already existing) objects
+type TenantRestoreResult struct {
+	Groups       map[string]string `json:"groups"`
+	Users        map[string]string `json:"users"`
+	Clients      map[string]string `json:"clients"`
+	Roles        map[string]string `json:"roles"`
+	Applications map[string]string `json:"applications"`
+	Projects     map[string]string `json:"projects"`
+	Presets      map[string]string `json:"presets"`
+	ResultStates map[uint64]uint64 `json:"resultStates"`
+	Errors       []string          `json:"errors"`  // objects which could not be restored
+	Skipped      []string          `json:"skipped"` // objects which were not attempted, eg: application and project-level queries
+}
+
+// Compares and synchronizes custom queries, presets and roles from one tenant to another, see NewTenantSync
+type TenantSync struct {
+	Source             *Cx1Client
+	Target             *Cx1Client
+	ApplicationMapping map[string]string // source application name: target application name, applications not listed map to the same name
+	SkipQueries        bool
+	SkipPresets        bool
+	SkipRoles          bool
+	Prune              bool // delete custom queries, presets and roles in the target which are not in the source
+}
+
+type TenantSyncChangeSet struct {
+	Queries []TenantSyncQueryChange  `json:"queries"`
+	Presets []TenantSyncPresetChange `json:"presets"`
+	Roles   []TenantSyncRoleChange   `json:"roles"`
+}
+
+type TenantSyncQueryChange struct {
+	Action      string            `json:"action"`                // see TenantChangeActions
+	Application string            `json:"application,omitempty"` // target application name for application-level queries
+	Query       TenantBackupQuery `json:"query"`
+}
+
+type TenantSyncPresetChange struct {
+	Action        string              `json:"action"`
+	Engine        string              `json:"engine"`
+	Name          string              `json:"name"`
+	TargetID      string              `json:"targetId,omitempty"`
+	Description   string              `json:"description"`
+	QueryFamilies []QueryFamily       `json:"queryFamilies"`
+	Fields        []TenantFieldChange `json:"fields,omitempty"`
+}
+
+type TenantSyncRoleChange struct {
+	Action         string   `json:"action"`
+	Name           string   `json:"name"`
+	TargetID       string   `json:"targetId,omitempty"`
+	Creator        string   `json:"creator"`
+	AddSubRoles    []string `json:"addSubRoles,omitempty"`
+	RemoveSubRoles []string `json:"removeSubRoles,omitempty"`
+}
+
+// Desired (or current) state of the tenant configuration, see LoadTenantState and PlanTenantState
+// A nil list means that resource type is not managed: it is neither compared nor pruned
+type TenantState struct {
+	Groups       []TenantGroupState       `json:"groups,omitempty" yaml:"groups,omitempty"`
+	Presets      []TenantPresetState      `json:"presets,omitempty" yaml:"presets,omitempty"`
+	Applications []TenantApplicationState `json:"applications,omitempty" yaml:"applications,omitempty"`
+	Projects     []TenantProjectState     `json:"projects,omitempty" yaml:"projects,omitempty"`
+}
+
+type TenantGroupState struct {
+	Path string `json:"path" yaml:"path"` // eg: /parent/child
+}
+
+type TenantPresetState struct {
+	Name        string   `json:"name" yaml:"name"`
+	Description string   `json:"description,omitempty" yaml:"description,omitempty"`
+	QueryIDs    []string `json:"queryIds,omitempty" yaml:"queryIds,omitempty"` // preset contents are not managed if empty
+}
+
+type TenantApplicationState struct {
+	Name        string            `json:"name" yaml:"name"`
+	Description string            `json:"description,omitempty" yaml:"description,omitempty"`
+	Criticality *uint             `json:"criticality,omitempty" yaml:"criticality,omitempty"`
+	Tags        map[string]string `json:"tags,omitempty" yaml:"tags,omitempty"`
+}
+
+type TenantProjectState struct {
+	Name          string               `json:"name" yaml:"name"`
+	Groups        []string             `json:"groups,omitempty" yaml:"groups,omitempty"`             // group paths
+	Applications  []string             `json:"applications,omitempty" yaml:"applications,omitempty"` // application names
+	Tags          map[string]string    `json:"tags,omitempty" yaml:"tags,omitempty"`
+	RepoUrl       string               `json:"repoUrl,omitempty" yaml:"repoUrl,omitempty"`
+	MainBranch    string               `json:"mainBranch,omitempty" yaml:"mainBranch,omitempty"`
+	Criticality   *uint                `json:"criticality,omitempty" yaml:"criticality,omitempty"`
+	Configuration map[string]string    `json:"configuration,omitempty" yaml:"configuration,omitempty"` // configuration key: value, keys not listed are left unchanged
+	Schedule      *TenantScheduleState `json:"schedule,omitempty" yaml:"schedule,omitempty"`
+}
+
+type TenantScheduleState struct {
+	StartTime string            `json:"startTime" yaml:"startTime"` // 15:04
+	Frequency string            `json:"frequency" yaml:"frequency"` // weekly or daily
+	Days      []string          `json:"days,omitempty" yaml:"days,omitempty"`
+	Active    bool              `json:"active" yaml:"active"`
+	Engines   []string          `json:"engines" yaml:"engines"`
+	Branch    string            `json:"branch" yaml:"branch"`
+	Tags      map[string]string `json:"tags,omitempty" yaml:"tags,omitempty"`
+}
+
+// A snapshot of the tenant's scan schedules keyed by project name, see ExportScanSchedules
+type ScanScheduleExport struct {
+	Exported  time.Time                      `json:"exported" yaml:"exported"`
+	Schedules map[string]TenantScheduleState `json:"schedules" yaml:"schedules"` // by project name
+}
+
+// The differences between the desired and live scan schedules, see GetScanScheduleDrift
+type ScanScheduleDrift struct {
+	Changes         []TenantChange `json:"changes"`         // create: missing from the tenant, delete: not in the desired schedules, update: changed
+	UnknownProjects []string       `json:"unknownProjects"` // projects with desired schedules which do not exist in the tenant
+}
+
+// The changes required to move the tenant from its current state to the desired state, see TenantPlan.String and ApplyTenantPlan
+type TenantPlan struct {
+	Desired TenantState    `json:"desired"`
+	Prune   bool           `json:"prune"`
+	Changes []TenantChange `json:"changes"`
+}
+
+type TenantChange struct {
+	Action       string              `json:"action"`       // see TenantChangeActions
+	ResourceType string              `json:"resourceType"` // see TenantResourceTypes
+	Name         string              `json:"name"`
+	ID           string              `json:"id,omitempty"` // ID of the existing resource, empty for creates
+	Fields       []TenantFieldChange `json:"fields,omitempty"`
+}
+
+type TenantFieldChange struct {
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
 type ClientVars struct {
 	MigrationPollingMaxSeconds                int
 	MigrationPollingDelaySeconds              int
@@ -284,6 +509,95 @@ type AnalyticsVulnerabilitiesStats struct {
 	Severities        []AnalyticsLabeledResultsCountEntry `json:"severities"`
 }
 
+// Client-side analytics calculated from scans, results and result change history, see NewLocalAnalytics
+// Unlike the server-side analytics, these can use custom states and arbitrary project groupings
+type LocalAnalytics struct {
+	scans    []localAnalyticsScan
+	history  map[string][]ResultsChangelog // by project ID + similarity ID
+	groups   map[string][]string           // by project ID
+	findings map[string]*LocalAnalyticsFinding
+}
+type localAnalyticsScan struct {
+	scan    Scan
+	results ScanResultSet
+}
+
+// The lifecycle of a finding across the scans of a project branch
+type LocalAnalyticsFinding struct {
+	ProjectID    string
+	Branch       string
+	SimilarityID string
+	Engine       string
+	Severity     string
+	State        string
+	FirstFoundAt time.Time
+	TriagedAt    *time.Time // first state change, from the change history
+	FixedAt      *time.Time // scan in which the finding was no longer detected
+	Reopened     uint64     // number of times the finding was detected again after being fixed
+}
+
+type LocalAnalyticsOptions struct {
+	Now          time.Time                // reference time for aging and open SLA breaches, default time.Now()
+	States       []string                 // only include findings currently in these states, all states if empty
+	Severities   []string                 // only include findings with these severities, all if empty
+	CustomStates []string                 // custom state names to recognize in the change text of entries without a structured state
+	AgingBuckets []int                    // upper bounds in days for open finding age buckets, default 30, 60, 90, 180
+	SLA          map[string]time.Duration // time to fix by severity, used to count SLA breaches
+}
+
+type LocalAnalyticsStats struct {
+	Group                string
+	Findings             uint64
+	Open                 uint64
+	Fixed                uint64
+	Reopened             uint64 // findings which were reopened at least once
+	MeanTimeToResolution time.Duration
+	MeanTimeToTriage     time.Duration
+	FixRate              float64 // fixed / findings
+	ReopenRate           float64 // reopened / findings which were fixed at some point
+	OpenBySeverity       map[string]uint64
+	Aging                []LocalAnalyticsAgingBucket
+	SLABreaches          uint64 // open findings past the SLA, and findings fixed after the SLA
+}
+type LocalAnalyticsAgingBucket struct {
+	MaxDays int // 0 for the final bucket with no upper bound
+	Count   uint64
+}
+
+// A set of metrics built from analytics data which can be written in the OpenMetrics text format, see NewAnalyticsMetricSet. The zero value is ready to use
+type AnalyticsMetricSet struct {
+	families []*analyticsMetricFamily
+	index    map[string]int
+}
+type analyticsMetricFamily struct {
+	name    string
+	help    string
+	samples []analyticsMetricSample
+}
+type analyticsMetricSample struct {
+	labels    [][2]string
+	value     float64
+	timestamp *time.Time
+}
+
+// A filter for which analytics metrics are collected, with the labels (eg: project, application) added to each metric
+type AnalyticsMetricsTarget struct {
+	Filter AnalyticsFilter
+	Labels map[string]string
+}
+
+// Serves analytics metrics in the OpenMetrics text format, refreshing them at most once per interval, see NewAnalyticsMetricsHandler
+type AnalyticsMetricsHandler struct {
+	IncludeProjectOverviews bool // also add project and application counts, see AnalyticsMetricSet.AddProjectOverviews
+
+	client   *Cx1Client
+	targets  []AnalyticsMetricsTarget
+	interval time.Duration
+	lock     sync.Mutex
+	cache    []byte
+	updated  time.Time
+}
+
 type Application struct {
 	ApplicationID      string            `json:"id"`
 	Name               string            `json:"name"`
@@ -326,6 +640,72 @@ type ApplicationRule struct {
 	Value string `json:"value"`
 }
 
+// The projects an application would gain or lose if its rules were replaced
+type ApplicationRulePreview struct {
+	ApplicationID   string    `json:"applicationId"`
+	ApplicationName string    `json:"applicationName"`
+	Gained          []Project `json:"gained"`
+	Lost            []Project `json:"lost"`
+	Unchanged       []Project `json:"unchanged"`
+}
+
+// A record from a CMDB export, describing an application and optionally one of its projects
+type CMDBRecord struct {
+	ID          string            `json:"id"`
+	Application string            `json:"application"`       // application name, or ID when matching by CMDBApplicationMatchKeys.ID
+	Project     string            `json:"project,omitempty"` // project name, or a name pattern when matching by CMDBMatchKeys.NamePattern
+	RepoUrl     string            `json:"repoUrl,omitempty"`
+	Owner       string            `json:"owner,omitempty"`
+	Criticality *uint             `json:"criticality,omitempty"`
+	Tags        map[string]string `json:"tags,omitempty"`
+}
+
+// The CSV columns holding each CMDBRecord field, TagColumns are copied into the record's tags using the column name as the key
+type CMDBColumns struct {
+	ID          string
+	Application string
+	Project     string
+	RepoUrl     string
+	Owner       string
+	Criticality string
+	TagColumns  []string
+}
+
+type CMDBReconcileOptions struct {
+	ApplicationMatchBy  string // one of CMDBApplicationMatchKeys, used to match records to applications, defaults to the application name
+	ApplicationTagKey   string // the application tag holding the record ID when matching by CMDBApplicationMatchKeys.Tag
+	MatchBy             string // one of CMDBMatchKeys, used to match records to projects
+	ProjectTagKey       string // the project tag holding the record ID when matching by CMDBMatchKeys.Tag
+	OwnerTagKey         string // the application and project tag holding the owner, defaults to "owner"
+	ApplyToProjects     bool   // also apply the record's criticality and tags to matched projects
+	AssignToApplication bool   // assign matched projects to the record's application if they are not already members
+}
+
+type CMDBReconciliation struct {
+	Matches            []CMDBMatch    `json:"matches"`
+	OrphanRecords      []CMDBRecord   `json:"orphanRecords"`      // records without a matching application or project
+	OrphanApplications []Application  `json:"orphanApplications"` // applications not in the CMDB
+	OrphanProjects     []Project      `json:"orphanProjects"`     // projects not matched by any record
+	Mismatches         []CMDBMismatch `json:"mismatches"`
+}
+
+type CMDBMatch struct {
+	RecordID      string   `json:"recordId"`
+	ApplicationID string   `json:"applicationId,omitempty"`
+	ProjectIDs    []string `json:"projectIds"`
+}
+
+type CMDBMismatch struct {
+	RecordID            string              `json:"recordId"`
+	ResourceType        string              `json:"resourceType"` // TenantResourceTypes.Application or Project
+	ID                  string              `json:"id"`
+	Name                string              `json:"name"`
+	Fields              []TenantFieldChange `json:"fields"`
+	Criticality         *uint               `json:"criticality,omitempty"`
+	Tags                *map[string]string  `json:"tags,omitempty"`
+	AssignApplicationID string              `json:"assignApplicationId,omitempty"`
+}
+
 type ApplicationOverview struct {
 	ApplicationID string            `json:"applicationId"`
 	Name          string            `json:"applicationName"`
@@ -496,6 +876,45 @@ type ConfigurationSetting struct {
 	AllowOverride   bool   `json:"allowOverride,omitempty"`
 }
 
+// The effective configuration of a project or scan, with the origin of each setting
+type ConfigurationResolution struct {
+	ProjectID string                         `json:"projectId"`
+	ScanID    string                         `json:"scanId,omitempty"`
+	Settings  []ResolvedConfigurationSetting `json:"settings"`
+}
+
+type ResolvedConfigurationSetting struct {
+	Key         string                `json:"key"`
+	Name        string                `json:"name"`
+	Category    string                `json:"category"`
+	Value       string                `json:"value"`
+	OriginLevel string                `json:"originLevel"`
+	LockedAt    string                `json:"lockedAt,omitempty"` // the level at which AllowOverride=false was set, if any
+	Chain       []ConfigurationOrigin `json:"chain"`
+}
+
+type ConfigurationOrigin struct {
+	Level         string `json:"level"`
+	Value         string `json:"value"`
+	AllowOverride bool   `json:"allowOverride"`
+	Blocked       bool   `json:"blocked"` // a value which differs from a locked value at a higher level and does not take effect
+}
+
+type ConfigurationDifference struct {
+	Key         string `json:"key"`
+	Name        string `json:"name"`
+	Value       string `json:"value"`
+	OriginLevel string `json:"originLevel"`
+	Other       string `json:"other"`
+	OtherOrigin string `json:"otherOrigin"`
+}
+
+type ProjectConfigurationDrift struct {
+	ProjectID   string                    `json:"projectId"`
+	ProjectName string                    `json:"projectName"`
+	Differences []ConfigurationDifference `json:"differences"`
+}
+
 type CxLink struct {
 	LinkID      string    `json:"id"`
 	Name        string    `json:"name"`
@@ -517,6 +936,60 @@ type CxLinkFilter struct {
 	BaseFilter
 }
 
+// CycloneDX 1.5 VEX document generated locally from SCA results, see NewSCAVEXDocument
+type CycloneDXVEX struct {
+	BOMFormat       string                   `json:"bomFormat"`
+	SpecVersion     string                   `json:"specVersion"`
+	SerialNumber    string                   `json:"serialNumber"`
+	Version         int                      `json:"version"`
+	Metadata        CycloneDXMetadata        `json:"metadata"`
+	Components      []CycloneDXComponent     `json:"components"`
+	Vulnerabilities []CycloneDXVulnerability `json:"vulnerabilities"`
+}
+type CycloneDXMetadata struct {
+	Timestamp time.Time `json:"timestamp"`
+	Tools     struct {
+		Components []CycloneDXComponent `json:"components"`
+	} `json:"tools"`
+}
+type CycloneDXComponent struct {
+	BOMRef  string `json:"bom-ref,omitempty"`
+	Type    string `json:"type"`
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+	PURL    string `json:"purl,omitempty"`
+}
+type CycloneDXVulnerability struct {
+	BOMRef         string            `json:"bom-ref"`
+	ID             string            `json:"id"`
+	Source         *CycloneDXSource  `json:"source,omitempty"`
+	Ratings        []CycloneDXRating `json:"ratings,omitempty"`
+	CWEs           []int             `json:"cwes,omitempty"`
+	Description    string            `json:"description,omitempty"`
+	Recommendation string            `json:"recommendation,omitempty"`
+	Published      *time.Time        `json:"published,omitempty"`
+	Analysis       CycloneDXAnalysis `json:"analysis"`
+	Affects        []CycloneDXAffect `json:"affects"`
+}
+type CycloneDXSource struct {
+	Name string `json:"name"`
+	URL  string `json:"url,omitempty"`
+}
+type CycloneDXRating struct {
+	Score    float64 `json:"score,omitempty"`
+	Severity string  `json:"severity"`
+	Method   string  `json:"method,omitempty"`
+}
+type CycloneDXAnalysis struct {
+	State         string   `json:"state"`
+	Justification string   `json:"justification,omitempty"`
+	Response      []string `json:"response,omitempty"`
+	Detail        string   `json:"detail,omitempty"`
+}
+type CycloneDXAffect struct {
+	Ref string `json:"ref"`
+}
+
 type DataImport struct {
 	MigrationId string             `json:"migrationId"`
 	Status      string             `json:"status"`
@@ -648,6 +1121,38 @@ type OIDCClientAMFilter struct {
 	Search string `json:"search"`
 }
 
+// OSV-format vulnerability generated locally from SCA results, see NewSCAOSVVulnerabilities
+type OSVVulnerability struct {
+	SchemaVersion    string                 `json:"schema_version"`
+	ID               string                 `json:"id"`
+	Modified         time.Time              `json:"modified"`
+	Published        *time.Time             `json:"published,omitempty"`
+	Aliases          []string               `json:"aliases,omitempty"`
+	Summary          string                 `json:"summary,omitempty"`
+	Details          string                 `json:"details,omitempty"`
+	Affected         []OSVAffected          `json:"affected"`
+	References       []OSVReference         `json:"references,omitempty"`
+	DatabaseSpecific map[string]interface{} `json:"database_specific,omitempty"`
+}
+type OSVAffected struct {
+	Package  OSVPackage `json:"package"`
+	Versions []string   `json:"versions,omitempty"`
+	Ranges   []OSVRange `json:"ranges,omitempty"`
+}
+type OSVPackage struct {
+	Ecosystem string `json:"ecosystem"`
+	Name      string `json:"name"`
+	PURL      string `json:"purl,omitempty"`
+}
+type OSVRange struct {
+	Type   string              `json:"type"`
+	Events []map[string]string `json:"events"`
+}
+type OSVReference struct {
+	Type string `json:"type"`
+	URL  string `json:"url"`
+}
+
 // Access Management phase2
 type Permission struct {
 	ID          string   `json:"id"`
@@ -660,21 +1165,20 @@ type Permission struct {
 }
 
 type Policy struct {
-	PolicyID     uint64    `json:"id"`
-	Name         string    `json:"name"`
-	Description  string    `json:"description"`
-	IsActivated  bool      `json:"isActivated"`
-	Tags         []string  `json:"tags"`
-	BreakBuild   bool      `json:"breakBuild"`
-	PinPolicy    bool      `json:"pinPolicy"`
-	LastViolated time.Time `json:"lastViolated"`
-	UpdatedAt    time.Time `json:"updatedAt"`
-	Projects     []struct {
-		AstProjectID string `json:"astProjectId"`
-	} `json:"projects"`
-	NetNewBreakBuild       bool   `json:"netNewBreakBuild"`
-	Type                   string `json:"type"`
-	AllScannersSeverities  []any  `json:"allScannersSeverities"`
+	PolicyID               uint64          `json:"id"`
+	Name                   string          `json:"name"`
+	Description            string          `json:"description"`
+	IsActivated            bool            `json:"isActivated"`
+	Tags                   []string        `json:"tags"`
+	BreakBuild             bool            `json:"breakBuild"`
+	PinPolicy              bool            `json:"pinPolicy"`
+	LastViolated           time.Time       `json:"lastViolated"`
+	UpdatedAt              time.Time       `json:"updatedAt"`
+	Projects               []PolicyProject `json:"projects"`
+	Rules                  []PolicyRule    `json:"rules,omitempty"` // only returned by GetPolicyByID, not in the list of policies
+	NetNewBreakBuild       bool            `json:"netNewBreakBuild"`
+	Type                   string          `json:"type"`
+	AllScannersSeverities  []any           `json:"allScannersSeverities"`
 	ViolatingAssocProjects struct {
 		AssocProjects uint64 `json:"assocProjects"`
 		Violating     uint64 `json:"violating"`
@@ -682,6 +1186,24 @@ type Policy struct {
 	DefaultPolicy bool `json:"defaultPolicy"`
 }
 
+type PolicyProject struct {
+	AstProjectID string `json:"astProjectId"`
+}
+
+// A rule is violated when the results matching all of its conditions reach the PolicyConditionKeys.ResultCount condition (default: at least 1)
+type PolicyRule struct {
+	ID         uint64                `json:"id,omitempty"`
+	Name       string                `json:"name"`
+	Type       string                `json:"type,omitempty"`
+	Conditions []PolicyRuleCondition `json:"conditions"`
+}
+
+type PolicyRuleCondition struct {
+	Key      string   `json:"key"`      // see PolicyConditionKeys
+	Operator string   `json:"operator"` // see PolicyConditionOperators
+	Values   []string `json:"values"`
+}
+
 type PolicyFilter struct {
 	BasePolicyFilter
 }
@@ -817,6 +1339,71 @@ type ProjectOverview struct {
 	} `json:"applications"`
 }
 
+// Rules for which scans to delete, see PlanScanRetention. Scans are only deleted if a delete rule applies and no keep rule applies.
+type ScanRetentionPolicy struct {
+	ProjectIDs               []string          // limit the policy to these projects, or all projects if empty
+	KeepLastCompleted        int               // keep this many completed or partial scans per project branch, 0 disables deletion of completed scans
+	DeleteCompletedAfterDays int               // completed scans beyond KeepLastCompleted are only deleted once older than this
+	DeleteFailedAfterDays    int               // delete failed and canceled scans older than this, 0 disables deletion of failed scans
+	KeepTriaged              bool              // keep scans in which results were triaged, see PlanScanRetention for the limits. Scans are kept if the triage can not be read
+	KeepPolicyViolations     bool              // keep scans referenced by policy violations
+	KeepTags                 map[string]string // keep scans with any of these tags, an empty value matches any value
+}
+
+type ScanRetentionReport struct {
+	DryRun    bool                    `json:"dryRun"`
+	Decisions []ScanRetentionDecision `json:"decisions"`
+	Deleted   []string                `json:"deleted"` // IDs of the scans which were deleted
+	Errors    []string                `json:"errors"`
+}
+
+type ScanRetentionDecision struct {
+	ScanID      string    `json:"scanId"`
+	ProjectID   string    `json:"projectId"`
+	ProjectName string    `json:"projectName"`
+	Branch      string    `json:"branch"`
+	Status      string    `json:"status"`
+	CreatedAt   time.Time `json:"createdAt"`
+	Delete      bool      `json:"delete"`
+	Reason      string    `json:"reason"`
+}
+
+// A branch of a project with its scan activity, see GetProjectBranchOverview
+type ProjectBranch struct {
+	ProjectID     string          `json:"projectId"`
+	Name          string          `json:"name"`
+	ScanCount     uint64          `json:"scanCount"`
+	LastScan      *Scan           `json:"lastScan,omitempty"`
+	LatestScans   map[string]Scan `json:"latestScans"` // the latest completed scan by engine
+	ResultSummary *ScanSummary    `json:"resultSummary,omitempty"`
+}
+
+// A project with no recent activity, see GetStaleProjects
+type StaleProject struct {
+	ProjectID      string    `json:"projectId"`
+	Name           string    `json:"name"`
+	LastScanID     string    `json:"lastScanId,omitempty"`
+	LastScanDate   time.Time `json:"lastScanDate,omitempty"`
+	LastScanBranch string    `json:"lastScanBranch,omitempty"`
+	BranchCount    int       `json:"branchCount"`
+	Empty          bool      `json:"empty"` // never scanned
+	Reasons        []string  `json:"reasons"`
+}
+
+// Everything needed to understand or recreate a project after it is deleted, see ArchiveProject
+type ProjectArchive struct {
+	Created        time.Time               `json:"created"`
+	Project        Project                 `json:"project"`
+	Configuration  []ConfigurationSetting  `json:"configuration"` // project-level settings only
+	GroupPaths     []string                `json:"groupPaths"`
+	Applications   []string                `json:"applications"` // application names
+	ScanSchedules  []ProjectScanSchedule   `json:"scanSchedules"`
+	LastScan       *Scan                   `json:"lastScan,omitempty"`
+	ResultSummary  *ScanSummary            `json:"resultSummary,omitempty"`
+	SASTPredicates []SASTResultsPredicates `json:"sastPredicates"`
+	IACPredicates  []IACResultsPredicates  `json:"iacPredicates"`
+}
+
 type ProjectOverviewFilter struct { // max limit = 100
 	BaseFilter
 	Name           string   `url:"name,omitempty"`
@@ -836,6 +1423,41 @@ type ProjectOverviewFilter struct { // max limit = 100
 	Sort           []string `url:"sort,omitempty"` //  name, scan-origin, last-scan-date, source-type, risk-level, is-public, applications
 }
 
+// Selects the projects, applications, scans and scan schedules for tag operations
+type TagSelector struct {
+	ResourceTypes []string          // TenantResourceTypes Project, Application, Scan and ScanSchedule, defaults to projects and applications
+	NameRegex     string            // matches the project or application name, scans and schedules match on their project's name
+	Groups        []string          // group IDs, the project (or the scan's or schedule's project) must be in one of these groups
+	Applications  []string          // application IDs, the application or project must be one of or in one of these applications
+	Tags          map[string]string // the resource must have all of these tags, an empty value matches any value
+	ScansFrom     time.Time         // only scans created since this time are included
+}
+
+// An operation on the tags of a resource, see TagActions
+type TagOperation struct {
+	Action    string            `json:"action"`
+	Key       string            `json:"key,omitempty"`
+	NewKey    string            `json:"newKey,omitempty"`    // for TagActions.Rename
+	Value     string            `json:"value,omitempty"`     // for TagActions.Add, or TagActions.Remove to only remove a specific value
+	Values    map[string]string `json:"values,omitempty"`    // for TagActions.Retype, from old value to new value
+	Case      string            `json:"case,omitempty"`      // for TagActions.Normalize, the case of keys: "lower", "upper" or "" to only trim whitespace
+	ValueCase string            `json:"valueCase,omitempty"` // for TagActions.Normalize, the case of values: "lower", "upper" or "" to only trim whitespace
+}
+
+type TagPlan struct {
+	Operations []TagOperation `json:"operations"`
+	Changes    []TagChange    `json:"changes"`
+}
+
+type TagChange struct {
+	ResourceType string               `json:"resourceType"`
+	ID           string               `json:"id"`
+	Name         string               `json:"name"`
+	Old          map[string]string    `json:"old"`
+	New          map[string]string    `json:"new"`
+	Schedule     *ProjectScanSchedule `json:"schedule,omitempty"` // scan schedules are updated as a whole
+}
+
 type ProjectScanSchedule struct {
 	ID            string            `json:"id"`
 	Name          string            `json:"name"`
@@ -865,6 +1487,36 @@ type ProjectScanScheduleFilter struct {
 	Sort             []string   `url:"sort,omitempty"` //  -created_at, +created_at, -status, +status, +name, -name, +trigger_time, -trigger_time
 }
 
+// The time window in which PlanScanSchedules spreads scan start times, in the same time zone as ProjectScanSchedule.StartTime
+type ScanScheduleWindow struct {
+	Start           string        // start of the window, eg: 22:00
+	End             string        // end of the window, eg: 06:00 - may be before Start for windows spanning midnight
+	Interval        time.Duration // granularity of start times, default 15 minutes
+	DefaultDuration time.Duration // expected duration of scans of projects without completed scans, default 30 minutes
+	MaxConcurrent   int           // report peaks where more scans are expected to run concurrently, 0 disables
+}
+
+type ScanSchedulePlan struct {
+	Window    ScanScheduleWindow     `json:"window"`
+	Changes   []ScanScheduleChange   `json:"changes"`
+	Conflicts []ScanScheduleConflict `json:"conflicts"` // conflicts expected once the plan is applied
+}
+
+type ScanScheduleChange struct {
+	ProjectID string               `json:"projectId"`
+	Old       *ProjectScanSchedule `json:"old,omitempty"` // nil if the project has no schedule yet
+	New       ProjectScanSchedule  `json:"new"`
+	Duration  time.Duration        `json:"duration"` // expected duration of a scan, based on the project's scan history
+}
+
+type ScanScheduleConflict struct {
+	Type      string                `json:"type"` // one of ScanScheduleConflictTypes
+	Day       string                `json:"day"`
+	StartTime string                `json:"startTime"`
+	Schedules []ProjectScanSchedule `json:"schedules"`
+	Reason    string                `json:"reason"`
+}
+
 type QueryError struct {
 	Line        uint64
 	StartColumn uint64
@@ -898,6 +1550,61 @@ type QueryUpdateMetadata_v310 struct {
 	Severity uint `json:"severity"`
 }
 
+// An entity to generate a report for in a batch, see GenerateReportBatch
+// Name is used in the output file name, eg: the project or application name
+type ReportBatchEntity struct {
+	EntityType string `json:"entityType"`
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+}
+
+// Settings for GenerateReportBatch. Request is used as the template for each report, with the EntityType and IDs replaced per entity
+// Sections are specific to the entity type, so batches mixing entity types can set the sections for each type in Sections
+// NameTemplate is a text/template for file names with fields EntityType, ID, Name, Date and Format, default "{{.EntityType}}-{{.Name}}.{{.Format}}"
+type ReportBatchOptions struct {
+	Request           ReportRequest
+	Sections          map[string][]string // entity type to the report sections, replacing Request.Sections for that type
+	OutputDir         string
+	NameTemplate      string
+	Concurrency       int    // maximum number of reports generating at the same time, default 5
+	Retries           int    // number of times a failed report is requested again
+	RetryDelaySeconds int    // delay before the first retry of a report, doubled for each further retry, default the client's report polling delay
+	Manifest          string // file name of the JSON manifest written to OutputDir, default manifest.json
+}
+
+type ReportBatchResult struct {
+	Entity   ReportBatchEntity `json:"entity"`
+	ReportID string            `json:"reportId,omitempty"`
+	FileName string            `json:"fileName,omitempty"`
+	Size     int64             `json:"size"`
+	Attempts int               `json:"attempts"`
+	Error    string            `json:"error,omitempty"`
+}
+
+type ReportBatchManifest struct {
+	Started  time.Time           `json:"started"`
+	Finished time.Time           `json:"finished"`
+	Format   string              `json:"format"`
+	Results  []ReportBatchResult `json:"results"`
+}
+
+// Parameters for a v2 (improved) report, see NewReportRequest and RequestNewReport
+// Values for each field are listed in ReportEntityTypes, ReportSections, ReportScanners, ReportSeverities, ReportStates, ReportStatuses and ReportFormats
+type ReportRequest struct {
+	Name       string // default improved-<entity>-report
+	EntityType string
+	IDs        []string
+	Tags       []string
+	Sections   []string
+	Scanners   []string
+	Severities []string
+	States     []string
+	Statuses   []string
+	Emails     []string
+	Format     string
+	ReportType string // default ui
+}
+
 type ReportStatus struct {
 	ReportID  string `json:"reportId"`
 	Status    string `json:"status"`
@@ -916,9 +1623,12 @@ type ResultsChangeHistory struct {
 	Predicates   []ResultsChangelog `json:"predicates"`
 }
 type ResultsChangelog struct {
-	Change string    `json:"change"`
-	Date   time.Time `json:"date"`
-	User   string    `json:"user"`
+	Change   string    `json:"change"`
+	Date     time.Time `json:"date"`
+	User     string    `json:"user"`
+	State    string    `json:"state,omitempty"`    // the state set by the change, if it changed the state
+	Severity string    `json:"severity,omitempty"` // the severity set by the change, if it changed the severity
+	Comment  string    `json:"comment,omitempty"`
 }
 
 type ResultsPredicatesBase struct {
@@ -933,6 +1643,28 @@ type ResultsPredicatesBase struct {
 	CreatedAt    time.Time `json:"createdAt,omitempty"`
 }
 
+// Options for ScanResult Fingerprint functions
+// StripPathPrefixes are removed from the start of file paths, eg: "/src/", so that results from different repository layouts match
+// The normalized source line from snippets attached by ScanResultSet.AddSourceSnippets is included unless ExcludeSnippets is set,
+// so fingerprints which will be compared must all be taken with or without snippets attached, and with the same setting
+type ResultFingerprintOptions struct {
+	StripPathPrefixes []string
+	ExcludeSnippets   bool
+}
+
+// A range of source lines surrounding a result location, see ScanResultSet.AddSourceSnippets
+type ResultSourceSnippet struct {
+	FileName  string             `json:"fileName"`
+	Line      uint64             `json:"line"` // the line of the result itself
+	StartLine uint64             `json:"startLine"`
+	EndLine   uint64             `json:"endLine"`
+	Lines     []ResultSourceLine `json:"lines"`
+}
+type ResultSourceLine struct {
+	Number uint64 `json:"number"`
+	Text   string `json:"text"`
+}
+
 type ResultState struct {
 	ID        uint64 `json:"id"`
 	Name      string `json:"name"`
@@ -1023,6 +1755,77 @@ type IACResultsPredicates struct {
 	ResultsPredicatesBase // actually the same structure but different endpoint
 }
 
+// An external ticketing system used by IssueSync, see JiraIssueTracker and MemoryIssueTracker
+// Issues are identified by the tracker's own ID (eg: PROJ-123) and labelled with the IssueSync group key used by FindByKey
+type IssueTracker interface {
+	CreateIssue(issue TrackerIssue) (string, error)
+	UpdateIssue(issue TrackerIssue) error
+	CloseIssue(issueID, comment string) error
+	ReopenIssue(issueID, comment string) error
+	FindByKey(key string) (*TrackerIssue, error)
+}
+
+type TrackerIssue struct {
+	ID          string // set by the tracker
+	Key         string // IssueSync group key, stored as a label
+	Title       string
+	Description string
+	Severity    string
+	Labels      []string
+	Closed      bool
+}
+
+// Synchronizes scan results to an IssueTracker, one issue per group of results, see NewIssueSync
+type IssueSync struct {
+	Tracker            IssueTracker
+	Mapping            *IssueSyncMapping
+	GroupBy            string   // one of IssueGroupings
+	Severities         []string // only results with these severities will create issues, all severities if empty
+	States             []string // results in these states are treated as fixed, default NOT_EXPLOITABLE
+	Labels             []string // additional labels added to new issues
+	FingerprintOptions ResultFingerprintOptions
+}
+
+// Persistent mapping between IssueSync group keys and tracker issues, can be saved to and loaded from a JSON file
+type IssueSyncMapping struct {
+	Issues map[string]IssueSyncRecord `json:"issues"`
+}
+type IssueSyncRecord struct {
+	IssueID   string    `json:"issueId"`
+	ProjectID string    `json:"projectId"`
+	Title     string    `json:"title"`
+	Closed    bool      `json:"closed"`
+	LastScan  string    `json:"lastScanId"`
+	LastSeen  time.Time `json:"lastSeen"`
+	Results   []string  `json:"results"` // similarity IDs of the results in the group at the last sync
+}
+
+type IssueSyncSummary struct {
+	Created   []string
+	Updated   []string
+	Reopened  []string
+	Closed    []string
+	Unchanged []string
+}
+
+// IssueTracker for Jira, using the REST v2 API with basic (username + API token) or bearer (personal access token) authentication
+type JiraIssueTracker struct {
+	BaseURL          string // eg: https://example.atlassian.net
+	ProjectKey       string
+	IssueType        string // default Bug
+	Username         string // if empty, Token is sent as a bearer token
+	Token            string
+	CloseTransition  string // default Done
+	ReopenTransition string // default To Do
+	Client           *http.Client
+}
+
+// IssueTracker held in memory, eg: for testing or dry-runs
+type MemoryIssueTracker struct {
+	Issues map[string]*TrackerIssue
+	nextID int
+}
+
 /*
 type KeyCloakClient struct {
 	ClientID string `json:"id"`
@@ -1111,6 +1914,23 @@ type ScanConfiguration struct {
 
 type ScanConfigurationSet struct {
 	Configurations []ScanConfiguration
+	Catalogue      *ConfigurationCatalogue // used by SetKey to validate values, the built-in catalogue is used if nil
+}
+
+// The known configuration settings, see DefaultConfigurationCatalogue and Cx1Client.RefreshConfigurationCatalogue
+type ConfigurationCatalogue struct {
+	Complete bool // the catalogue was loaded from the tenant, so unknown keys are rejected
+	Settings map[string]ConfigurationSettingDefinition
+}
+
+type ConfigurationSettingDefinition struct {
+	Key           string   `json:"key"`
+	Name          string   `json:"name"`
+	Category      string   `json:"category"`
+	ValueType     string   `json:"valueType"`
+	AllowedValues []string `json:"allowedValues,omitempty"` // empty if any value is allowed
+	KnownValues   []string `json:"knownValues,omitempty"`   // values which existed when the catalogue was loaded, other values are still allowed (eg: presets created later)
+	Default       string   `json:"default"`
 }
 
 type ScanHandler struct {
@@ -1153,6 +1973,45 @@ type ScanMetrics struct {
 	}
 }
 
+// Data used to render a local scan report, see GetScanReportDataByID and RenderScanReport
+// Engines, TopQueries and Triage are calculated from Results by NewScanReportData
+type ScanReportData struct {
+	Scan        Scan
+	Summary     ScanSummary
+	Metadata    ScanMetadata
+	Metrics     ScanMetrics
+	Results     ScanResultSet
+	GeneratedAt time.Time
+
+	Total      ScanReportEngine
+	Engines    []ScanReportEngine
+	TopQueries []ScanReportQuery
+	Triage     []ScanReportCount
+}
+type ScanReportEngine struct {
+	Name      string
+	Total     uint64
+	New       uint64
+	Recurrent uint64
+	Critical  uint64
+	High      uint64
+	Medium    uint64
+	Low       uint64
+	Info      uint64
+}
+type ScanReportQuery struct {
+	Engine   string
+	Language string
+	Name     string
+	Severity string
+	Count    uint64
+	New      uint64
+}
+type ScanReportCount struct {
+	Name  string
+	Count uint64
+}
+
 type ScanResultSet struct {
 	SAST         []ScanSASTResult
 	SCA          []ScanSCAResult
@@ -1206,6 +2065,7 @@ type ScanContainersResultData struct {
 	ImageTag       string
 	ImageFilePath  string
 	ImageOrigin    string
+	Snippet        *ResultSourceSnippet `json:"snippet,omitempty"` // filled by ScanResultSet.AddSourceSnippets
 }
 
 type ScanContainersResultDetails struct {
@@ -1241,6 +2101,7 @@ type ScanIACResultData struct {
 	IssueType     string
 	ExpectedValue string
 	Value         string
+	Snippet       *ResultSourceSnippet `json:"snippet,omitempty"` // filled by ScanResultSet.AddSourceSnippets
 }
 
 type ScanSASTResult struct {
@@ -1270,6 +2131,7 @@ type ScanSASTResultNodes struct {
 	TypeName    string
 	MethodLine  uint64
 	Definitions string
+	Snippet     *ResultSourceSnippet `json:"snippet,omitempty"` // filled by ScanResultSet.AddSourceSnippets
 }
 type ScanSASTResultDetails struct {
 	CweId       int
@@ -1322,8 +2184,9 @@ type ScanSCAResultData struct {
 	PublishedAt        time.Time
 	Recommendation     string
 	RecommendedVersion string
-	//ExploitableMethods // TODO
-	PackageData []ScanSCAResultPackageData
+	ExploitableMethods []ScanSCAResultExploitableMethod
+	PackageData        []ScanSCAResultPackageData
+	Snippet            *ResultSourceSnippet `json:"snippet,omitempty"` // filled by ScanResultSet.AddSourceSnippets
 }
 type ScanSCAResultDetails struct {
 	CweId     string
@@ -1338,6 +2201,12 @@ type ScanSCAResultCVSS struct {
 	Confidentiality  string
 	AttackComplexity string
 }
+type ScanSCAResultExploitableMethod struct {
+	FullName   string
+	MethodName string
+	SourceFile string
+	Line       uint64
+}
 type ScanSCAResultPackageData struct {
 	URL     string
 	Type    string
@@ -1359,6 +2228,15 @@ type ScanSCAContainerResultData struct {
 	PublishedAt    time.Time `json:"publishedAt"`
 }
 
+// Zip archive of the source code used in a scan, see GetScanSourceArchiveByID
+// File contents are read from the archive on demand and cached
+type ScanSourceArchive struct {
+	reader *zip.Reader
+	closer io.Closer
+	files  map[string]*zip.File
+	lines  map[string][]string
+}
+
 type ScanStatusDetails struct {
 	Name    string `json:"name"`
 	Status  string `json:"status"`
@@ -1580,6 +2458,91 @@ type ScanSummaryFilter struct {
 	ExcludeTypes   []string `url:"exclude-result-types"` // DEV_AND_TEST, NONE
 }
 
+// Dependency graph of a scan's SCA packages and their vulnerabilities, see GetScanSCADependencyGraphByID
+type SCADependencyGraph struct {
+	Packages     []SCAPackage
+	Results      []ScanSCAResult
+	packages     map[string]int                  // package ID to index in Packages
+	results      map[string][]ScanSCAResult      // package ID to results
+	parents      map[string][]string             // package ID to the IDs of the packages which depend on it
+	dependencies map[string]SCAPackageDependency // package ID to the package as it appears in dependency paths
+}
+
+type SCAPackage struct {
+	PackageID                string                   `json:"id"` // same format as ScanSCAResultData.PackageIdentifier
+	Name                     string                   `json:"name"`
+	Version                  string                   `json:"version"`
+	Licenses                 []string                 `json:"licenses"`
+	MatchType                string                   `json:"matchType"`
+	HighVulnerabilityCount   uint64                   `json:"highVulnerabilityCount"`
+	MediumVulnerabilityCount uint64                   `json:"mediumVulnerabilityCount"`
+	LowVulnerabilityCount    uint64                   `json:"lowVulnerabilityCount"`
+	Outdated                 bool                     `json:"outdated"`
+	NewestVersion            string                   `json:"newestVersion"`
+	Severity                 string                   `json:"severity"`
+	RiskScore                float64                  `json:"riskScore"`
+	Locations                []string                 `json:"locations"`
+	PackageRepository        string                   `json:"packageRepository"`
+	IsDirectDependency       bool                     `json:"isDirectDependency"`
+	IsDevelopmentDependency  bool                     `json:"isDevelopmentDependency"`
+	IsTestDependency         bool                     `json:"isTestDependency"`
+	DependencyPaths          [][]SCAPackageDependency `json:"dependencyPaths"` // each path starts at a direct dependency and ends with this package
+}
+
+type SCAPackageDependency struct {
+	PackageID     string   `json:"id"`
+	Name          string   `json:"name"`
+	Version       string   `json:"version"`
+	IsResolved    bool     `json:"isResolved"`
+	IsDevelopment bool     `json:"isDevelopment"`
+	Locations     []string `json:"locations"`
+}
+
+// An upgrade needed to fix vulnerabilities, see SCADependencyGraph.GetPackageUpgrades
+type SCAPackageUpgrade struct {
+	PackageID      string
+	Name           string
+	CurrentVersion string
+	TargetVersion  string // the highest recommended version across the fixable vulnerabilities, empty if none has a recommended version
+	IsDirect       bool
+	Via            []string // IDs of the direct dependencies which pull in this package, if it is transitive
+	Fixes          []string // vulnerability IDs (CVEs) fixed by this upgrade
+	Unfixed        []string // vulnerability IDs without a recommended version
+}
+
+// Remediation deadlines by severity, see NewSLAPolicy and LoadSLAPolicy
+// Deadlines are multiplied by the factor for the project's criticality, eg: {5: 0.5} halves the deadlines for the most critical projects
+type SLAPolicy struct {
+	Days               map[string]int   `json:"days"` // by severity, eg: {"Critical": 7, "High": 30}
+	CriticalityFactors map[uint]float64 `json:"criticalityFactors,omitempty"`
+	ExcludedStates     []string         `json:"excludedStates,omitempty"` // results in these states have no deadline
+	WarningDays        int              `json:"warningDays"`              // results due within this many days are reported as due soon
+}
+
+// An open result evaluated against an SLAPolicy
+type SLAFinding struct {
+	ProjectID     string    `json:"projectId"`
+	ProjectName   string    `json:"projectName"`
+	Applications  []string  `json:"applications"` // names from GetSLAReport, IDs from SLAPolicy.EvaluateResults
+	Groups        []string  `json:"groups"`       // paths from GetSLAReport, IDs from SLAPolicy.EvaluateResults
+	ScanID        string    `json:"scanId"`
+	Engine        string    `json:"engine"`
+	SimilarityID  string    `json:"similarityId"`
+	Title         string    `json:"title"`
+	Severity      string    `json:"severity"`
+	State         string    `json:"state"`
+	FirstFoundAt  time.Time `json:"firstFoundAt"`
+	DueDate       time.Time `json:"dueDate"`
+	DaysRemaining int       `json:"daysRemaining"` // negative when overdue
+	SLAStatus     string    `json:"slaStatus"`     // one of SLAStatuses
+}
+
+type SLAReport struct {
+	Generated time.Time    `json:"generated"`
+	Policy    SLAPolicy    `json:"policy"`
+	Findings  []SLAFinding `json:"findings"`
+}
+
 type SCMIntegration struct {
 	ID          uint64 `json:"id"`
 	Type        string `json:"type"`
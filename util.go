@@ -3,6 +3,7 @@ package Cx1ClientGo
 import (
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -23,6 +24,39 @@ func (c *Cx1Client) depwarn(old, new string) {
 	}
 }
 
+// runs work for each index from 0 to count-1 on up to workers goroutines (default 4), and returns the error for each index
+// the access token is refreshed up front so that the workers do not all wait on the token lock to refresh it
+func (c *Cx1Client) runConcurrently(count, workers int, work func(i int) error) []error {
+	errs := make([]error, count)
+	if workers <= 0 {
+		workers = 4
+	}
+	if err := c.refreshAccessToken(); err != nil {
+		for i := range errs {
+			errs[i] = fmt.Errorf("failed to get access token: %s", err)
+		}
+		return errs
+	}
+
+	var wg sync.WaitGroup
+	indexes := make(chan int)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				errs[i] = work(i)
+			}
+		}()
+	}
+	for i := 0; i < count; i++ {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+	return errs
+}
+
 func RemoveIndex(slice []interface{}, index int) []interface{} {
 	ret := slice[:index]
 	ret = append(ret, slice[index+1:]...)